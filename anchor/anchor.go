@@ -0,0 +1,181 @@
+package anchor
+
+import (
+	"certification/config"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var ErrDisabled = errors.New("anchoring is disabled, ANCHOR_RPC_URL is not set")
+
+// What the anchoring needs from a node. Both ethclient.Client and the client of
+// go-ethereum's simulated backend satisfy it, so it runs end-to-end without a network.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Anchors Merkle roots in the registry with the key of its owner
+type Anchorer struct {
+	Registry      *Registry
+	backend       Backend
+	key           *ecdsa.PrivateKey
+	chainID       *big.Int
+	confirmations uint64
+}
+
+// Transaction which anchored a root
+type Receipt struct {
+	TxHash      common.Hash
+	BlockNumber uint64
+	Success     bool // false when the transaction was reverted
+}
+
+// Create an anchorer for the registry, the key must be the one of the owner of the registry.
+// Receipts are only returned once their block has the given number of confirmations, at least 1.
+func New(ctx context.Context, backend Backend, registry common.Address, key *ecdsa.PrivateKey, confirmations uint64) (*Anchorer, error) {
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := backend.CodeAt(ctx, registry, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("no registry contract at %s", registry.Hex())
+	}
+
+	a := &Anchorer{
+		Registry:      NewRegistry(registry, backend),
+		backend:       backend,
+		key:           key,
+		chainID:       chainID,
+		confirmations: confirmations,
+	}
+	if a.confirmations == 0 {
+		a.confirmations = 1
+	}
+
+	owner, err := a.Registry.Owner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if owner != a.Address() {
+		return nil, fmt.Errorf("registry %s is owned by %s, not by %s", registry.Hex(), owner.Hex(), a.Address().Hex())
+	}
+	return a, nil
+}
+
+// Connect to the node and registry of the configuration
+func Dial(ctx context.Context) (*Anchorer, error) {
+	if config.ANCHOR_RPC_URL == "" {
+		return nil, ErrDisabled
+	}
+
+	key, err := Key()
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(config.ANCHOR_REGISTRY_ADDRESS) {
+		return nil, fmt.Errorf("invalid ANCHOR_REGISTRY_ADDRESS %q", config.ANCHOR_REGISTRY_ADDRESS)
+	}
+
+	client, err := ethclient.DialContext(ctx, config.ANCHOR_RPC_URL)
+	if err != nil {
+		return nil, err
+	}
+	return New(ctx, client, common.HexToAddress(config.ANCHOR_REGISTRY_ADDRESS), key, config.ANCHOR_CONFIRMATIONS)
+}
+
+// Key of the owner of the registry from ANCHOR_PRIVATE_KEY, it also signs the proof bundles
+func Key() (*ecdsa.PrivateKey, error) {
+	key, err := crypto.HexToECDSA(config.ANCHOR_PRIVATE_KEY)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANCHOR_PRIVATE_KEY: %w", err)
+	}
+	return key, nil
+}
+
+// address of the account sending the transactions
+func (a *Anchorer) Address() common.Address {
+	return crypto.PubkeyToAddress(a.key.PublicKey)
+}
+
+func (a *Anchorer) ChainID() *big.Int {
+	return new(big.Int).Set(a.chainID)
+}
+
+// Send the transaction anchoring the root, returns its hash without waiting for it to be mined
+func (a *Anchorer) Submit(ctx context.Context, root common.Hash) (common.Hash, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(a.key, a.chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	opts.Context = ctx
+
+	tx, err := a.Registry.Anchor(opts, root)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+// Whether Submit failed because the registry rejected the transaction, which sending it
+// again does not change, rather than because the node could not be reached
+func IsReverted(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == 3 {
+		return true
+	}
+	return err != nil && strings.Contains(err.Error(), "execution reverted")
+}
+
+// Receipt of the transaction, nil while it is pending or not confirmed enough
+func (a *Anchorer) Confirm(ctx context.Context, txHash common.Hash) (*Receipt, error) {
+	receipt, err := a.backend.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := a.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	block := receipt.BlockNumber.Uint64()
+	if head.Number.Uint64()+1 < block+a.confirmations {
+		return nil, nil
+	}
+
+	return &Receipt{
+		TxHash:      txHash,
+		BlockNumber: block,
+		Success:     receipt.Status == types.ReceiptStatusSuccessful,
+	}, nil
+}
+
+// Transaction which anchored the root, nil when the root is not anchored
+func (a *Anchorer) Find(ctx context.Context, root common.Hash) (*Receipt, error) {
+	log, err := a.Registry.FindAnchor(ctx, root)
+	if err != nil || log == nil {
+		return nil, err
+	}
+	return &Receipt{TxHash: log.TxHash, BlockNumber: log.BlockNumber, Success: true}, nil
+}
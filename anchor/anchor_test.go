@@ -0,0 +1,135 @@
+//go:build simulated
+
+// Runs against an in-process simulated chain, with: go test -tags simulated ./...
+
+package anchor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// Simulated chain with a registry deployed by the returned key
+func deployTestRegistry(t *testing.T) (*simulated.Backend, *ecdsa.PrivateKey, common.Address) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey):   {Balance: balance},
+		crypto.PubkeyToAddress(other.PublicKey): {Balance: balance},
+	}, simulated.WithBlockGasLimit(30_000_000))
+	t.Cleanup(func() { backend.Close() })
+
+	chainID, err := backend.Client().ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, _, err := DeployRegistry(opts, backend.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+
+	return backend, key, registry.Address
+}
+
+func TestNewChecksRegistry(t *testing.T) {
+	backend, key, registry := deployTestRegistry(t)
+	ctx := context.Background()
+
+	if _, err := New(ctx, backend.Client(), registry, key, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(ctx, backend.Client(), registry, other, 1); err == nil {
+		t.Fatal("anchorer accepted a key which does not own the registry")
+	}
+	if _, err := New(ctx, backend.Client(), crypto.PubkeyToAddress(key.PublicKey), key, 1); err == nil {
+		t.Fatal("anchorer accepted an address without a contract")
+	}
+}
+
+func TestSubmitConfirmFind(t *testing.T) {
+	backend, key, registry := deployTestRegistry(t)
+	ctx := context.Background()
+
+	anchorer, err := New(ctx, backend.Client(), registry, key, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := NewTree([]common.Hash{HashLeaf([]byte("a")), HashLeaf([]byte("b")), HashLeaf([]byte("c"))})
+	root := tree.Root()
+
+	if receipt, err := anchorer.Find(ctx, root); err != nil || receipt != nil {
+		t.Fatalf("root found before it was anchored: %v %v", receipt, err)
+	}
+
+	txHash, err := anchorer.Submit(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt, err := anchorer.Confirm(ctx, txHash); err != nil || receipt != nil {
+		t.Fatalf("pending transaction confirmed: %v %v", receipt, err)
+	}
+
+	backend.Commit()
+	if receipt, err := anchorer.Confirm(ctx, txHash); err != nil || receipt != nil {
+		t.Fatalf("transaction confirmed without enough confirmations: %v %v", receipt, err)
+	}
+
+	backend.Commit()
+	receipt, err := anchorer.Confirm(ctx, txHash)
+	if err != nil || receipt == nil {
+		t.Fatalf("transaction not confirmed: %v", err)
+	}
+	if !receipt.Success || receipt.TxHash != txHash {
+		t.Fatalf("unexpected receipt %+v", receipt)
+	}
+
+	found, err := anchorer.Find(ctx, root)
+	if err != nil || found == nil {
+		t.Fatalf("anchored root not found: %v", err)
+	}
+	if found.TxHash != txHash || found.BlockNumber != receipt.BlockNumber {
+		t.Fatalf("unexpected anchor %+v, expected %+v", found, receipt)
+	}
+
+	for i := 0; i < 3; i++ {
+		if !VerifyProof(HashLeaf([]byte{"abc"[i]}), tree.Proof(i), root) {
+			t.Fatalf("proof of leaf %d does not verify", i)
+		}
+	}
+
+	// A root can only be anchored once
+	if _, err := anchorer.Submit(ctx, root); err == nil {
+		t.Fatal("root was anchored twice")
+	} else if !IsReverted(err) {
+		t.Fatalf("rejected transaction is not reported as reverted: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package anchor
+
+import (
+	"bytes"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Merkle trees of keccak256 hashes. Leaves are hashed twice so that a leaf can never be mistaken
+// for a node, and the two children of a node are hashed in sorted order so that a proof is only the
+// list of sibling hashes, the same scheme as OpenZeppelin's MerkleProof. A node without sibling is
+// moved up a level unchanged.
+
+type Tree struct {
+	levels [][]common.Hash // levels[0] are the leaves, the last level is the root
+}
+
+// hash of a leaf of the document
+func HashLeaf(document []byte) common.Hash {
+	return crypto.Keccak256Hash(crypto.Keccak256(document))
+}
+
+func hashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
+
+// Build the tree of the leaves in their given order, there must be at least one leaf
+func NewTree(leaves []common.Hash) *Tree {
+	level := append([]common.Hash(nil), leaves...)
+	levels := [][]common.Hash{level}
+
+	for len(level) > 1 {
+		next := make([]common.Hash, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+			} else {
+				next = append(next, hashPair(level[i], level[i+1]))
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return &Tree{levels: levels}
+}
+
+func (t *Tree) Root() common.Hash {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// sibling hashes from the leaf at index up to the root
+func (t *Tree) Proof(index int) []common.Hash {
+	proof := []common.Hash{}
+	for _, level := range t.levels[:len(t.levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, level[sibling])
+		}
+		index /= 2
+	}
+	return proof
+}
+
+// Check that the leaf is part of the tree with the root
+func VerifyProof(leaf common.Hash, proof []common.Hash, root common.Hash) bool {
+	hash := leaf
+	for _, sibling := range proof {
+		hash = hashPair(hash, sibling)
+	}
+	return hash == root
+}
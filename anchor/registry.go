@@ -0,0 +1,124 @@
+package anchor
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Registry contract of the anchored Merkle roots. Only the account which deployed it can
+// anchor, a root can be anchored once and the block it was anchored in is kept.
+//
+//	function anchor(bytes32 root)                    emits Anchored(root)
+//	function anchoredAt(bytes32 root) returns uint256 block number, 0 when not anchored
+//	function owner() returns address
+const RegistryABI = `[
+	{"type":"function","name":"anchor","stateMutability":"nonpayable","inputs":[{"name":"root","type":"bytes32"}],"outputs":[]},
+	{"type":"function","name":"anchoredAt","stateMutability":"view","inputs":[{"name":"root","type":"bytes32"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"function","name":"owner","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+	{"type":"event","name":"Anchored","anonymous":false,"inputs":[{"name":"root","type":"bytes32","indexed":true}]}
+]`
+
+// The contract is small enough to be written in EVM assembly, which keeps the build free of a
+// Solidity compiler. The owner is kept in slot 0 and the block of every root in the slot of the root.
+//
+//	constructor:  sstore(0, caller) codecopy(0, 0x0f, 0x8b) return(0, 0x8b)
+//	0x00  selector := calldataload(0) >> 0xe0, jump to anchor, anchoredAt or owner
+//	0x24  fail:       revert(0, 0)
+//	0x29  anchor:     fail if callvalue, caller != sload(0), root == 0 or sload(root) != 0
+//	                  sstore(root, number) log2(0, 0, Anchored, root) stop
+//	0x6d  anchoredAt: fail if root == 0, return sload(root)
+//	0x7f  owner:      return sload(0)
+const RegistryBin = "0x33600055608b80600f6000396000f3" +
+	"60003560e01c8063eecdf9271460295780639591a61014606d5780638da5cb5b14607f57" +
+	"5b600080fd" +
+	"5b34602457600054331415602457600435801560245780546024574381557f" +
+	"c8f7565859107929ba4b285bfb17dfba069f69ab64447f49d6fecb70f6bd552b600080a200" +
+	"5b60043580156024575460005260206000f3" +
+	"5b60005460005260206000f3"
+
+var registryABI = mustParseABI(RegistryABI)
+
+type Registry struct {
+	Address  common.Address
+	backend  bind.ContractBackend
+	contract *bind.BoundContract
+}
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Deploy a new registry owned by the account of the options
+func DeployRegistry(opts *bind.TransactOpts, backend bind.ContractBackend) (*Registry, *types.Transaction, error) {
+	address, tx, contract, err := bind.DeployContract(opts, registryABI, common.FromHex(RegistryBin), backend)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &Registry{Address: address, backend: backend, contract: contract}, tx, nil
+}
+
+// Registry deployed at the address
+func NewRegistry(address common.Address, backend bind.ContractBackend) *Registry {
+	return &Registry{
+		Address:  address,
+		backend:  backend,
+		contract: bind.NewBoundContract(address, registryABI, backend, backend, backend),
+	}
+}
+
+// Send the transaction anchoring the root
+func (r *Registry) Anchor(opts *bind.TransactOpts, root common.Hash) (*types.Transaction, error) {
+	return r.contract.Transact(opts, "anchor", root)
+}
+
+// block the root was anchored in, 0 when it is not anchored
+func (r *Registry) AnchoredAt(ctx context.Context, root common.Hash) (uint64, error) {
+	var out []interface{}
+	if err := r.contract.Call(&bind.CallOpts{Context: ctx}, &out, "anchoredAt", root); err != nil {
+		return 0, err
+	}
+	return out[0].(*big.Int).Uint64(), nil
+}
+
+func (r *Registry) Owner(ctx context.Context) (common.Address, error) {
+	var out []interface{}
+	if err := r.contract.Call(&bind.CallOpts{Context: ctx}, &out, "owner"); err != nil {
+		return common.Address{}, err
+	}
+	return out[0].(common.Address), nil
+}
+
+// Log of the transaction which anchored the root, nil when it is not anchored
+func (r *Registry) FindAnchor(ctx context.Context, root common.Hash) (*types.Log, error) {
+	block, err := r.AnchoredAt(ctx, root)
+	if err != nil || block == 0 {
+		return nil, err
+	}
+
+	number := new(big.Int).SetUint64(block)
+	logs, err := r.backend.FilterLogs(ctx, ethereum.FilterQuery{
+		FromBlock: number,
+		ToBlock:   number,
+		Addresses: []common.Address{r.Address},
+		Topics:    [][]common.Hash{{registryABI.Events["Anchored"].ID}, {root}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) == 0 {
+		return nil, errors.New("anchored root has no log")
+	}
+	return &logs[0], nil
+}
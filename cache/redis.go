@@ -118,3 +118,14 @@ func (c *Cache) DeleteCacheGroup(key string) error {
 	}
 	return c.RDB.Del(context.Background(), keys...).Err()
 }
+
+// Take a lock shared by all instances, false when another instance holds it
+func (c *Cache) Lock(key string, expiration time.Duration) (bool, error) {
+	_key := fmt.Sprintf("%s:lock", key)
+	return c.RDB.SetNX(context.Background(), _key, 1, expiration).Result()
+}
+
+func (c *Cache) Unlock(key string) error {
+	_key := fmt.Sprintf("%s:lock", key)
+	return c.RDB.Del(context.Background(), _key).Err()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"certification/config"
+	"certification/jwtkey"
+	"certification/logger"
+	"certification/verifier"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Run a maintenance command instead of the server, returns false when args is not a command.
+//
+//	rotate-key [RS256|EdDSA]        create a new JWT signing key and retire the active one
+//	verify <bundle.json> [rpc-url]  check a proof bundle against the chain, ANCHOR_RPC_URL by default
+func RunCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "rotate-key":
+		config.LoadEnv("")
+		logger.Initialize("", config.LOG_PATH)
+		defer logger.Log.Sync()
+
+		alg := config.JWT_ALGORITHM
+		if len(args) > 1 {
+			alg = args[1]
+		}
+
+		key, err := jwtkey.Rotate(alg)
+		if err != nil {
+			logger.Log.Fatal(err)
+		}
+		fmt.Printf("Rotated signing key %s (%s)\n", key.ID, key.Algorithm)
+		return true
+
+	case "verify":
+		config.LoadEnv("")
+		if err := verifyBundle(args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid bundle:", err)
+			os.Exit(1)
+		}
+		return true
+	}
+
+	return false
+}
+
+func verifyBundle(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: verify <bundle.json> [rpc-url]")
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var bundle verifier.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return err
+	}
+
+	url := config.ANCHOR_RPC_URL
+	if len(args) > 1 {
+		url = args[1]
+	}
+	if url == "" {
+		return fmt.Errorf("no rpc url, pass one or set ANCHOR_RPC_URL")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	result, err := bundle.Verify(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(bundle.Document)
+	fmt.Printf("Anchored in block %d of chain %s at %s by registry %s of %s\n",
+		result.BlockNumber, bundle.ChainID, result.AnchoredAt.Format(time.RFC3339), bundle.Registry.Hex(), result.Signer.Hex())
+	return nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/robfig/cron/v3"
 )
@@ -12,6 +13,7 @@ var DATABASE_URL string
 var FIREBASE_CREDENTIALS string
 var HOST string
 var SECRET string
+var ENCRYPTION_KEY string
 var REDIS_HOST string
 var REDIS_PORT string
 var REDIS_PASSWORD string
@@ -24,6 +26,30 @@ var SCHEDULER = cron.New()
 var API_URL string
 var EMAIL_LOGO_URL string
 var LOG_PATH string
+var STORAGE_PATH string
+var STORAGE_DRIVER string
+var AWS_ENDPOINT string
+var AWS_REGION string
+var AWS_BUCKET string
+var AWS_ACCESS_KEY_ID string
+var AWS_SECRET_ACCESS_KEY string
+var ANCHOR_RPC_URL string
+var ANCHOR_PRIVATE_KEY string
+var ANCHOR_REGISTRY_ADDRESS string
+var ANCHOR_CONFIRMATIONS uint64
+var JWT_KEYS_PATH string
+var JWT_ALGORITHM string
+var OIDC_REDIRECT_URL string
+var OIDC_PROVIDERS = map[string]OIDCProvider{}
+
+// OpenID Connect provider, loaded from OIDC_<NAME>_* for every name listed in OIDC_PROVIDERS
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
 
 // var MONGO_URL string
 // var MONGO_DB string
@@ -41,6 +67,12 @@ func LoadEnv(path string) {
 
 	// Auth Configuration
 	SECRET = os.Getenv("SECRET")
+	ENCRYPTION_KEY = os.Getenv("ENCRYPTION_KEY") // encrypts MFA secrets and signing keys, 32 bytes in hex
+	JWT_KEYS_PATH = os.Getenv("JWT_KEYS_PATH")
+	JWT_ALGORITHM = os.Getenv("JWT_ALGORITHM")
+	if JWT_ALGORITHM == "" {
+		JWT_ALGORITHM = "RS256"
+	}
 
 	// Redis Configuration
 	REDIS_HOST = os.Getenv("REDIS_HOST")
@@ -63,4 +95,56 @@ func LoadEnv(path string) {
 	API_URL = os.Getenv("API_URL")
 	LOG_PATH = os.Getenv("LOG_PATH")
 
+	// Uploaded files and rendered certificates, on the local disk under STORAGE_PATH
+	// or in an S3-compatible bucket when STORAGE_DRIVER is s3
+	STORAGE_DRIVER = os.Getenv("STORAGE_DRIVER")
+	STORAGE_PATH = os.Getenv("STORAGE_PATH")
+	if STORAGE_PATH == "" {
+		STORAGE_PATH = "storage"
+	}
+
+	// S3 Configuration, AWS_ENDPOINT overrides the AWS endpoint, e.g. http://minio:9000
+	AWS_ENDPOINT = os.Getenv("AWS_ENDPOINT")
+	AWS_REGION = os.Getenv("AWS_REGION")
+	AWS_BUCKET = os.Getenv("AWS_BUCKET")
+	AWS_ACCESS_KEY_ID = os.Getenv("AWS_ACCESS_KEY_ID")
+	AWS_SECRET_ACCESS_KEY = os.Getenv("AWS_SECRET_ACCESS_KEY")
+
+	// On-chain Anchoring Configuration, disabled without an RPC URL
+	ANCHOR_RPC_URL = os.Getenv("ANCHOR_RPC_URL")
+	ANCHOR_PRIVATE_KEY = strings.TrimPrefix(os.Getenv("ANCHOR_PRIVATE_KEY"), "0x")
+	ANCHOR_REGISTRY_ADDRESS = os.Getenv("ANCHOR_REGISTRY_ADDRESS")
+	ANCHOR_CONFIRMATIONS, _ = strconv.ParseUint(os.Getenv("ANCHOR_CONFIRMATIONS"), 10, 64)
+
+	// OpenID Connect Configuration
+	OIDC_REDIRECT_URL = os.Getenv("OIDC_REDIRECT_URL")
+	OIDC_PROVIDERS = LoadOIDCProviders(os.Getenv("OIDC_PROVIDERS"))
+}
+
+// Load the providers of a comma separated list of names, e.g. "google,microsoft"
+func LoadOIDCProviders(names string) map[string]OIDCProvider {
+	providers := map[string]OIDCProvider{}
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		scopes := strings.Fields(os.Getenv(prefix + "SCOPES"))
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+
+		providers[name] = OIDCProvider{
+			Name:         name,
+			Issuer:       strings.TrimSuffix(os.Getenv(prefix+"ISSUER"), "/"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       scopes,
+		}
+	}
+
+	return providers
 }
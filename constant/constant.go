@@ -1,5 +1,7 @@
 package constant
 
+import "time"
+
 const (
 	// Redis Status
 	CREATED = "created"
@@ -17,9 +19,10 @@ const (
 	PATCH_VALIDATE = "patch_validate"
 
 	// Modules
-	ACCOUNT = 1
-	WALLET  = 2
-	PROFILE = 3
+	ACCOUNT     = 1
+	WALLET      = 2
+	PROFILE     = 3
+	CERTIFICATE = 4
 
 	// Access Permission
 	READ   = "read"
@@ -45,6 +48,169 @@ const (
 	DEFAULT_SIZE_OF_API_GROUP  = 4
 )
 
+// API Key
+const (
+	API_KEY_SCHEME             = "ApiKey"
+	API_KEY_LAST_USED_INTERVAL = time.Minute // last used is written at most once per interval
+)
+
+// Certificate
+const (
+	CERTIFICATE_PAGE_SIZE     = 50
+	CERTIFICATE_MAX_PAGE_SIZE = 100
+	ISSUANCE_MAX_ROWS         = 50000 // rows of a bulk issuance file
+	ISSUANCE_BATCH_SIZE       = 100   // certificates issued and notified per batch
+	ISSUANCE_EMAIL_COLUMN     = "email"
+	VERIFICATION_CODE_SIZE    = 20 // base32 characters of the public verification code
+	VERIFICATION_CACHE_EXPIRY = time.Minute * 10
+)
+
+// Spreadsheet
+const (
+	SPREADSHEET_MAX_PART_SIZE = 50 << 20 // uncompressed bytes of a part of an XLSX file
+	SPREADSHEET_MAX_COLUMNS   = 16384    // columns of a worksheet, A to XFD
+	SPREADSHEET_MAX_CELLS     = 5000000  // cells of a worksheet, counting the empty cells before a value
+)
+
+// Revocation
+const (
+	REVOCATION_REVOKE        = "revoke"
+	REVOCATION_UNREVOKE      = "unrevoke"
+	STATUS_LIST_SIZE         = 131072 // bits of a status list, 16KB as the minimum of StatusList2021
+	STATUS_LIST_CACHE_EXPIRY = time.Minute * 10
+)
+
+// On-chain Anchoring
+const (
+	ANCHOR_SCHEDULE       = "@every 1m"
+	ANCHOR_BATCH_SIZE     = 1000             // certificates in the Merkle tree of a batch
+	ANCHOR_MAX_ATTEMPTS   = 5                // batches a certificate is part of before it is FAILED
+	ANCHOR_SUBMIT_TIMEOUT = time.Minute * 30 // a transaction not mined by then fails its batch
+	ANCHOR_LOCK_EXPIRY    = time.Minute * 5  // also the time limit of a round
+)
+
+// Certificate Rendering
+const (
+	RENDER_DPI                = 150  // PDF pages are sized as if the layout was printed at this resolution
+	RENDER_MAX_SIZE           = 6000 // width and height of a layout in pixels
+	RENDER_DEFAULT_WIDTH      = 2000
+	RENDER_DEFAULT_HEIGHT     = 1414
+	RENDER_BACKGROUND_MAX     = 10 << 20 // bytes of an uploaded background image
+	RENDER_FORMAT_PNG         = "png"
+	RENDER_FORMAT_PDF         = "pdf"
+	RENDER_DEFAULT_FONT_COLOR = "#000000"
+)
+
+// File Storage
+const (
+	STORAGE_LOCAL          = "local"
+	STORAGE_S3             = "s3"
+	STORAGE_TIMEOUT        = time.Minute      // time limit of a request to the bucket
+	STORAGE_PRESIGN_EXPIRY = time.Minute * 15 // default lifetime of a presigned URL
+	ARCHIVE_MAX_FILE_SIZE  = 20 << 20         // uncompressed bytes of a file in an uploaded archive
+	ARCHIVE_MAX_SIZE       = 100 << 20        // uncompressed bytes of all files in an uploaded archive
+)
+
+// OpenID Connect
+const (
+	OIDC_STATE_EXPIRY     = time.Minute * 10
+	OIDC_DISCOVERY_EXPIRY = time.Hour
+)
+
+// Sign-In with Ethereum
+const (
+	WALLET_NONCE_EXPIRY = time.Minute * 10
+	WALLET_STATEMENT    = "Sign in with Ethereum to CertFirst."
+)
+
+// JWT Signing Key
+const (
+	JWT_ALG_RS256           = "RS256"
+	JWT_ALG_EDDSA           = "EdDSA"
+	JWT_KEY_GRACE_PERIOD    = time.Hour * 24 // a retired key verifies tokens for this long
+	JWT_KEY_RELOAD_INTERVAL = time.Second * 30
+)
+
+// Issuer Signing Key, the keys of the verifiable credentials of a company
+const (
+	ISSUER_KEY_ED25519   = "Ed25519"
+	ISSUER_KEY_SECP256K1 = "secp256k1"
+	ISSUER_KEY_DEFAULT   = ISSUER_KEY_ED25519 // created on the first credential of a company without key
+)
+
+// Company Invitation
+const (
+	INVITATION_EXPIRY = time.Hour * 24 * 7
+)
+
+// Account Event
+const (
+	EVENT_REAUTHENTICATE    = "reauthenticate"    // the sessions of the account have to log in again
+	EVENT_ISSUANCE_PROGRESS = "issuance_progress" // progress of a bulk issuance job started by the account
+)
+
+// Session Expiry
+const (
+	ACCESS_TOKEN_EXPIRY  = time.Minute * 15
+	REFRESH_TOKEN_EXPIRY = time.Hour * 24 * 7
+)
+
+// Redis Key Prefix
+const (
+	REDIS_SESSION          = "session"
+	REDIS_ACCOUNT_SESSIONS = "sessions"
+	REDIS_REFRESH_TOKEN    = "refresh_token"
+	REDIS_REFRESH_FAMILY   = "refresh_family"
+	REDIS_MFA_CHALLENGE    = "mfa_challenge"
+	REDIS_MFA_FAILURE      = "mfa_failure"
+	REDIS_OTP_LOCK         = "otp_lock"
+	REDIS_OTP_VERIFIED     = "otp_verified"
+	REDIS_RATE_LIMIT       = "rate_limit"
+	REDIS_LOGIN_FAILURE    = "login_failure"
+	REDIS_LOGIN_DELAY      = "login_delay"
+	REDIS_LOGIN_LOCK       = "login_lock"
+	REDIS_ACCOUNT_EVENTS   = "account_events"
+	REDIS_OIDC_STATE       = "oidc_state"
+	REDIS_WALLET_NONCE     = "wallet_nonce"
+	REDIS_VERIFICATION     = "verification"
+	REDIS_ANCHOR           = "anchor"
+	REDIS_STATUS_LIST      = "status_list"
+)
+
+// Brute-force Protection
+const (
+	LOGIN_DELAY_AFTER    = 3 // failures before each attempt is delayed
+	LOGIN_MAX_DELAY      = time.Second * 30
+	LOGIN_LOCKOUT_AFTER  = 10
+	LOGIN_LOCKOUT        = time.Minute * 15
+	LOGIN_FAILURE_WINDOW = time.Minute * 15
+)
+
+// Email OTP
+const (
+	OTP_MAX_ATTEMPTS    = 5
+	OTP_RESEND_COOLDOWN = time.Minute
+	OTP_LOCKOUT         = time.Minute * 15
+	OTP_VERIFIED_EXPIRY = time.Minute * 10
+)
+
+// OTP Purpose
+const (
+	OTP_PURPOSE_STEP_UP           = "step_up"
+	OTP_PURPOSE_CLAIM_CERTIFICATE = "claim_certificate"
+	OTP_PURPOSE_CHANGE_EMAIL      = "change_email"
+)
+
+// Two-Factor Authentication
+const (
+	MFA_ISSUER           = "FirstCert"
+	MFA_PERIOD           = 30 // seconds per TOTP code
+	MFA_RECOVERY_CODES   = 10
+	MFA_MAX_ATTEMPTS     = 5
+	MFA_CHALLENGE_EXPIRY = time.Minute * 5
+	MFA_FAILURE_WINDOW   = time.Minute * 15 // wrong codes of a logged in account are counted within
+)
+
 // Token Type
 const (
 	VALIDATION_TOKEN     = "validation"
@@ -66,6 +232,7 @@ const (
 	SUBMITTED Status = "submitted"
 	DELETED   Status = "deleted"
 	FAILED    Status = "failed"
+	REVOKED   Status = "revoked"
 )
 
 // Environment
@@ -86,6 +253,23 @@ const (
 	DATETIME FieldType = "datetime"
 )
 
+// Layout Element Type with defined string
+type ElementType string
+
+const (
+	ELEMENT_TEXT ElementType = "text"
+	ELEMENT_QR   ElementType = "qr"
+)
+
+// Text Alignment with defined string
+type Align string
+
+const (
+	ALIGN_LEFT   Align = "left"
+	ALIGN_CENTER Align = "center"
+	ALIGN_RIGHT  Align = "right"
+)
+
 // Account Role
 type AccountRoleType string
 
@@ -93,3 +277,8 @@ const (
 	ROLE_COMPANY AccountRoleType = "company"
 	ROLE_USER    AccountRoleType = "user"
 )
+
+// Permission Role, the default roles of company and user accounts are named after their role type
+const (
+	ROLE_NAME_ADMIN = "admin"
+)
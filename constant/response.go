@@ -9,13 +9,22 @@ const (
 	ErrorInvalidID      = "invalid ID "
 	ErrorLogOut         = "Failed to log out"
 	ErrorWriteAccess    = "WRITE access cannot be granted while READ access is set to false"
+	ErrorDeleteAccess   = "DELETE access cannot be granted while READ access is set to false"
+	ErrorInvalidToken   = "Invalid or expired token"
+	ErrorInvalidMFACode = "Invalid two-factor authentication code"
+	ErrorTooManyRequest = "Too many requests, please try again later"
 
 	// Success message
 	SuccessCreateRecord = "Successfully created"
 	SuccessDeleteRecord = "Successfully deleted"
 	SuccessUpdateRecord = "Successfully updated"
 	SuccessLogIn        = "Successfully logged in"
+	SuccessRefresh      = "Successfully refreshed token"
+	SuccessMFAPending   = "Two-factor authentication required"
 	SuccessLogOut       = "Successfully logged out"
 	SuccessSignUp       = "Successfully signed up"
 	SuccessValidate     = "Successfully validated"
+	SuccessActivate     = "Successfully activated"
+	SuccessResetPass    = "Successfully reset password"
+	SuccessSendEmail    = "If the email is registered, a message has been sent"
 )
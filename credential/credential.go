@@ -0,0 +1,152 @@
+// Package credential issues the certificates as W3C Verifiable Credentials secured as VC-JWT,
+// signed by the key of the issuing company which is published in its did:web DID document.
+package credential
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+const (
+	CredentialType = "CertificateCredential"
+	MediaTypeJWT   = "application/jwt" // content type of a VC-JWT served on its own
+)
+
+var ErrInvalidCredential = errors.New("invalid credential")
+
+// W3C Verifiable Credential data model 1.1, the certificate terms are issuer-dependent
+type Credential struct {
+	Context           []interface{} `json:"@context"`
+	ID                string        `json:"id"`
+	Type              []string      `json:"type"`
+	Issuer            Issuer        `json:"issuer"`
+	IssuanceDate      time.Time     `json:"issuanceDate"`
+	CredentialSubject Subject       `json:"credentialSubject"`
+	CredentialStatus  *Status       `json:"credentialStatus,omitempty"`
+}
+
+type Issuer struct {
+	ID   string `json:"id"` // DID of the company
+	Name string `json:"name"`
+}
+
+type Subject struct {
+	Email       string      `json:"email"`
+	Name        string      `json:"name,omitempty"` // set once claimed
+	Certificate Certificate `json:"certificate"`
+}
+
+type Certificate struct {
+	Code         string  `json:"code"`
+	Name         string  `json:"name"` // of the template
+	Version      int     `json:"version"`
+	Verification string  `json:"verification"` // URL of the public verification
+	Fields       []Field `json:"fields"`
+}
+
+type Field struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// VC-JWT claims, the registered claims repeat the members of the credential
+type Claims struct {
+	jwt.StandardClaims
+	VC Credential `json:"vc"`
+}
+
+// Resolves the public key of a verification method of the issuer, returns its key type
+type KeyFunc func(issuer string, keyID string) (string, interface{}, error)
+
+// Credential of the certificate with the given ID
+func New(id string, issuer Issuer, issuedAt time.Time, subject Subject) *Credential {
+	return &Credential{
+		Context: []interface{}{
+			"https://www.w3.org/2018/credentials/v1",
+			map[string]string{"@vocab": "https://www.w3.org/ns/credentials/issuer-dependent#"},
+		},
+		ID:                id,
+		Type:              []string{"VerifiableCredential", CredentialType},
+		Issuer:            issuer,
+		IssuanceDate:      issuedAt.UTC().Truncate(time.Second),
+		CredentialSubject: subject,
+	}
+}
+
+// Sign the credential as a VC-JWT with the key of the issuer, keyID is the fragment of its verification method
+func Sign(vc *Credential, keyType string, keyID string, privateKey interface{}) (string, error) {
+	return sign(Claims{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    vc.Issuer.ID,
+			Id:        vc.ID,
+			NotBefore: vc.IssuanceDate.Unix(),
+		},
+		VC: *vc,
+	}, vc.Issuer.ID, keyType, keyID, privateKey)
+}
+
+func sign(claims jwt.Claims, issuer string, keyType string, keyID string, privateKey interface{}) (string, error) {
+	method, err := SigningMethod(keyType)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = issuer + "#" + keyID
+	return token.SignedString(privateKey)
+}
+
+// Check the signature of the VC-JWT with the key of its kid header, which must belong to its issuer
+func Parse(token string, keyFunc KeyFunc) (*Credential, string, error) {
+	var claims Claims
+	var keyID string
+
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		issuer, fragment, ok := strings.Cut(kid, "#")
+		if !ok || issuer != claims.Issuer || issuer != claims.VC.Issuer.ID {
+			return nil, errors.New("kid is not a key of the issuer")
+		}
+
+		keyType, publicKey, err := keyFunc(issuer, fragment)
+		if err != nil {
+			return nil, err
+		}
+		method, err := SigningMethod(keyType)
+		if err != nil {
+			return nil, err
+		}
+		if t.Method.Alg() != method.Alg() {
+			return nil, errors.New("unexpected signing method " + t.Method.Alg())
+		}
+
+		keyID = fragment
+		return publicKey, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if claims.Id != claims.VC.ID {
+		return nil, "", ErrInvalidCredential
+	}
+	return &claims.VC, keyID, nil
+}
+
+// ID of the credential of a certificate
+func CertificateURN(certificateID uuid.UUID) string {
+	return "urn:uuid:" + certificateID.String()
+}
+
+// Certificate of a credential ID made by CertificateURN
+func CertificateIDFromURN(id string) (uuid.UUID, error) {
+	if !strings.HasPrefix(id, "urn:uuid:") {
+		return uuid.Nil, ErrInvalidCredential
+	}
+	return uuid.Parse(strings.TrimPrefix(id, "urn:uuid:"))
+}
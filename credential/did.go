@@ -0,0 +1,93 @@
+package credential
+
+import (
+	"certification/config"
+	"certification/jwtkey"
+	model_company "certification/model/company"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// DID document of an issuer, its verification methods are the keys of the company
+type DIDDocument struct {
+	Context            []string             `json:"@context"`
+	ID                 string               `json:"id"`
+	VerificationMethod []VerificationMethod `json:"verificationMethod"`
+	AssertionMethod    []string             `json:"assertionMethod"`
+}
+
+type VerificationMethod struct {
+	ID           string     `json:"id"`
+	Type         string     `json:"type"`
+	Controller   string     `json:"controller"`
+	PublicKeyJwk jwtkey.JWK `json:"publicKeyJwk"`
+}
+
+// did:web prefix of the API, a colon separated host and path where a port is percent-encoded
+func webDID() string {
+	u, err := url.Parse(config.API_URL)
+	if err != nil || u.Host == "" {
+		return "did:web:localhost"
+	}
+
+	did := "did:web:" + strings.ReplaceAll(u.Host, ":", "%3A")
+	for _, segment := range strings.Split(strings.Trim(u.Path, "/"), "/") {
+		if segment != "" {
+			did += ":" + segment
+		}
+	}
+	return did
+}
+
+// did:web of the company, resolved to <API_URL>/issuers/<id>/did.json
+func IssuerDID(companyID uuid.UUID) string {
+	return webDID() + ":issuers:" + companyID.String()
+}
+
+// Company of a DID issued by IssuerDID
+func CompanyIDFromDID(did string) (uuid.UUID, error) {
+	prefix := webDID() + ":issuers:"
+	if !strings.HasPrefix(did, prefix) {
+		return uuid.Nil, fmt.Errorf("unknown issuer %s", did)
+	}
+	return uuid.Parse(strings.TrimPrefix(did, prefix))
+}
+
+// DID document listing every key of the company, retired keys included
+func NewDIDDocument(companyID uuid.UUID, keys []model_company.CompanyKey) (*DIDDocument, error) {
+	did := IssuerDID(companyID)
+	document := DIDDocument{
+		Context:            []string{"https://www.w3.org/ns/did/v1", "https://w3id.org/security/suites/jws-2020/v1"},
+		ID:                 did,
+		VerificationMethod: []VerificationMethod{},
+		AssertionMethod:    []string{},
+	}
+
+	for _, key := range keys {
+		method, err := SigningMethod(key.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		publicKey, err := ParsePublicKey(key.Algorithm, key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		jwk, err := jwtkey.NewJWK(key.KeyID, method.Alg(), publicKey)
+		if err != nil {
+			return nil, err
+		}
+
+		id := did + "#" + key.KeyID
+		document.VerificationMethod = append(document.VerificationMethod, VerificationMethod{
+			ID:           id,
+			Type:         "JsonWebKey2020",
+			Controller:   did,
+			PublicKeyJwk: jwk,
+		})
+		document.AssertionMethod = append(document.AssertionMethod, id)
+	}
+	return &document, nil
+}
@@ -0,0 +1,133 @@
+package credential
+
+import (
+	"certification/constant"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang-jwt/jwt"
+)
+
+// JWS algorithm of ES256K signatures, secp256k1 with SHA-256 as in RFC 8812
+type signingMethodES256K struct{}
+
+var SigningMethodES256K = &signingMethodES256K{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodES256K.Alg(), func() jwt.SigningMethod {
+		return SigningMethodES256K
+	})
+}
+
+func (m *signingMethodES256K) Alg() string {
+	return "ES256K"
+}
+
+// Sign with a *ecdsa.PrivateKey on secp256k1, the signature is R || S
+func (m *signingMethodES256K) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	hash := sha256.Sum256([]byte(signingString))
+	signature, err := crypto.Sign(hash[:], privateKey)
+	if err != nil {
+		return "", err
+	}
+	return jwt.EncodeSegment(signature[:64]), nil
+}
+
+// Verify with a *ecdsa.PublicKey on secp256k1, malleable signatures with a high S are rejected
+func (m *signingMethodES256K) Verify(signingString, signature string, key interface{}) error {
+	publicKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	raw, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256([]byte(signingString))
+	if len(raw) != 64 || !crypto.VerifySignature(crypto.FromECDSAPub(publicKey), hash[:], raw) {
+		return jwt.ErrECDSAVerification
+	}
+	return nil
+}
+
+// JWS algorithm of the signatures of a key type
+func SigningMethod(keyType string) (jwt.SigningMethod, error) {
+	switch keyType {
+	case constant.ISSUER_KEY_ED25519:
+		return jwt.SigningMethodEdDSA, nil
+	case constant.ISSUER_KEY_SECP256K1:
+		return SigningMethodES256K, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", keyType)
+}
+
+// Generate a key of the type, returns its private and public keys in hex. Ed25519 private keys
+// are their 32 bytes seed and secp256k1 public keys are compressed.
+func GenerateKey(keyType string) (string, string, error) {
+	switch keyType {
+	case constant.ISSUER_KEY_ED25519:
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+		return hex.EncodeToString(privateKey.Seed()), hex.EncodeToString(publicKey), nil
+
+	case constant.ISSUER_KEY_SECP256K1:
+		privateKey, err := crypto.GenerateKey()
+		if err != nil {
+			return "", "", err
+		}
+		return hex.EncodeToString(crypto.FromECDSA(privateKey)), hex.EncodeToString(crypto.CompressPubkey(&privateKey.PublicKey)), nil
+	}
+	return "", "", fmt.Errorf("unsupported key type %s", keyType)
+}
+
+// Decode a private key of GenerateKey, the key signs with the SigningMethod of its type
+func ParsePrivateKey(keyType string, hexKey string) (interface{}, error) {
+	raw, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyType {
+	case constant.ISSUER_KEY_ED25519:
+		if len(raw) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid Ed25519 private key")
+		}
+		return ed25519.NewKeyFromSeed(raw), nil
+	case constant.ISSUER_KEY_SECP256K1:
+		return crypto.ToECDSA(raw)
+	}
+	return nil, fmt.Errorf("unsupported key type %s", keyType)
+}
+
+// Decode a public key of GenerateKey
+func ParsePublicKey(keyType string, hexKey string) (interface{}, error) {
+	raw, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyType {
+	case constant.ISSUER_KEY_ED25519:
+		if len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(raw), nil
+	case constant.ISSUER_KEY_SECP256K1:
+		return crypto.DecompressPubkey(raw)
+	}
+	return nil, fmt.Errorf("unsupported key type %s", keyType)
+}
@@ -0,0 +1,116 @@
+package credential
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	StatusListContext        = "https://w3id.org/vc/status-list/2021/v1"
+	StatusPurposeRevocation  = "revocation"
+	StatusListCredentialType = "StatusList2021Credential"
+)
+
+// StatusList2021Entry of a credential, its bit in the status list is set once it is revoked
+type Status struct {
+	ID                   string `json:"id"`
+	Type                 string `json:"type"`
+	StatusPurpose        string `json:"statusPurpose"`
+	StatusListIndex      string `json:"statusListIndex"`
+	StatusListCredential string `json:"statusListCredential"`
+}
+
+// StatusList2021Credential publishing the revocation bits of the credentials of an issuer
+type StatusListCredential struct {
+	Context           []string          `json:"@context"`
+	ID                string            `json:"id"`
+	Type              []string          `json:"type"`
+	Issuer            Issuer            `json:"issuer"`
+	IssuanceDate      time.Time         `json:"issuanceDate"`
+	CredentialSubject StatusListSubject `json:"credentialSubject"`
+}
+
+type StatusListSubject struct {
+	ID            string `json:"id"`
+	Type          string `json:"type"`
+	StatusPurpose string `json:"statusPurpose"`
+	EncodedList   string `json:"encodedList"` // GZIP compressed bitstring, base64url without padding
+}
+
+type StatusListClaims struct {
+	jwt.StandardClaims
+	VC StatusListCredential `json:"vc"`
+}
+
+// Point the credential at its bit in the revocation status list published at url
+func (vc *Credential) SetStatus(url string, index int) {
+	vc.Context = append(vc.Context, StatusListContext)
+	vc.CredentialStatus = &Status{
+		ID:                   url + "#" + strconv.Itoa(index),
+		Type:                 "StatusList2021Entry",
+		StatusPurpose:        StatusPurposeRevocation,
+		StatusListIndex:      strconv.Itoa(index),
+		StatusListCredential: url,
+	}
+}
+
+// Revocation status list published at url with the bits of the revoked indexes set
+func NewStatusList(url string, issuer Issuer, size int, revoked []int) (*StatusListCredential, error) {
+	encoded, err := EncodeStatusList(size, revoked)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatusListCredential{
+		Context:      []string{"https://www.w3.org/2018/credentials/v1", StatusListContext},
+		ID:           url,
+		Type:         []string{"VerifiableCredential", StatusListCredentialType},
+		Issuer:       issuer,
+		IssuanceDate: time.Now().UTC().Truncate(time.Second),
+		CredentialSubject: StatusListSubject{
+			ID:            url + "#list",
+			Type:          "StatusList2021",
+			StatusPurpose: StatusPurposeRevocation,
+			EncodedList:   encoded,
+		},
+	}, nil
+}
+
+// Sign the status list as a VC-JWT, like Sign
+func SignStatusList(list *StatusListCredential, keyType string, keyID string, privateKey interface{}) (string, error) {
+	return sign(StatusListClaims{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    list.Issuer.ID,
+			Id:        list.ID,
+			NotBefore: list.IssuanceDate.Unix(),
+		},
+		VC: *list,
+	}, list.Issuer.ID, keyType, keyID, privateKey)
+}
+
+// Bitstring of size bits with the given indexes set, the first index is the most significant bit of the first byte
+func EncodeStatusList(size int, indexes []int) (string, error) {
+	bits := make([]byte, (size+7)/8)
+	for _, index := range indexes {
+		if index < 0 || index >= size {
+			return "", fmt.Errorf("status index %d out of the list of %d", index, size)
+		}
+		bits[index/8] |= 0x80 >> (index % 8)
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(bits); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
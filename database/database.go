@@ -4,9 +4,19 @@ import (
 	"certification/cache"
 	"certification/logger"
 	model_account "certification/model/account"
+	model_anchor "certification/model/anchor"
+	model_apikey "certification/model/apikey"
+	model_certificate "certification/model/certificate"
 	model_company "certification/model/company"
+	model_identity "certification/model/identity"
+	model_issuance "certification/model/issuance"
+	model_mfa "certification/model/mfa"
+	model_permission "certification/model/permission"
+	model_template "certification/model/template"
 	model_token "certification/model/token"
 	model_user "certification/model/user"
+	model_wallet "certification/model/wallet"
+	"certification/utils"
 	"context"
 
 	"cloud.google.com/go/firestore"
@@ -14,9 +24,6 @@ import (
 
 	"google.golang.org/api/option"
 
-	s3config "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
@@ -26,7 +33,6 @@ type Initializer struct {
 	DB  *gorm.DB
 	RDB *cache.Cache
 	FB  *firestore.Client
-	S3  *s3.Client
 }
 
 // ----------------- Postgres -----------------
@@ -40,6 +46,19 @@ func (initializer *Initializer) ConnectDB(url string) error {
 	if err != nil {
 		return err
 	}
+
+	// The hooks of the models queue the sessions to invalidate until the statement is committed
+	const invalidateSessions = "certification:invalidate_sessions"
+	if err := gdb.Callback().Create().After("gorm:commit_or_rollback_transaction").Register(invalidateSessions, utils.InvalidateSessionsCallback); err != nil {
+		return err
+	}
+	if err := gdb.Callback().Update().After("gorm:commit_or_rollback_transaction").Register(invalidateSessions, utils.InvalidateSessionsCallback); err != nil {
+		return err
+	}
+	if err := gdb.Callback().Delete().After("gorm:commit_or_rollback_transaction").Register(invalidateSessions, utils.InvalidateSessionsCallback); err != nil {
+		return err
+	}
+
 	initializer.DB = gdb
 	return nil
 }
@@ -56,13 +75,41 @@ func (initializer *Initializer) MigrateDB() {
 	err := initializer.DB.AutoMigrate(
 		model_account.Account{},
 		model_company.Company{},
+		model_company.CompanyMember{},
+		model_company.CompanyKey{},
 		model_user.User{},
 		model_token.Token{},
+		model_mfa.MFA{},
+		model_mfa.RecoveryCode{},
+		model_permission.Role{},
+		model_permission.Module{},
+		model_permission.Permission{},
+		model_apikey.APIKey{},
+		model_apikey.APIKeyScope{},
+		model_identity.LinkedIdentity{},
+		model_wallet.Wallet{},
+		model_template.CertificateTemplate{},
+		model_template.TemplateVersion{},
+		model_template.TemplateField{},
+		model_template.TemplateLayout{},
+		model_template.LayoutElement{},
+		model_certificate.Certificate{},
+		model_certificate.CertificateValue{},
+		model_issuance.IssuanceJob{},
+		model_issuance.IssuanceRow{},
+		model_anchor.AnchorBatch{},
+		model_anchor.CertificateAnchor{},
+		model_certificate.CertificateRevocation{},
+		model_certificate.StatusList{},
 	)
 	if err != nil {
 		logger.Log.Error(err)
-	} else {
-		logger.Log.Info("Database migrated")
+		return
+	}
+	logger.Log.Info("Database migrated")
+
+	if err := model_permission.SeedPermissions(initializer.DB); err != nil {
+		logger.Log.Error(err)
 	}
 }
 
@@ -99,21 +146,3 @@ func (initializer *Initializer) CloseFirebase() {
 		logger.Log.Error(err)
 	}
 }
-
-// ----------------- S3 -----------------
-
-func (initializer *Initializer) ConnectS3(accessKeyID, secretAccessKey, region string) error {
-	customProvider := credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")
-
-	cfg, err := s3config.LoadDefaultConfig(context.TODO(),
-		s3config.WithCredentialsProvider(customProvider),
-		s3config.WithRegion(region),
-	)
-
-	if err != nil {
-		return err
-	}
-
-	initializer.S3 = s3.NewFromConfig(cfg)
-	return nil
-}
@@ -2,10 +2,21 @@ package handler_auth
 
 import (
 	"certification/constant"
+	"certification/database"
 	"certification/logger"
+	"certification/mailer"
 	model_account "certification/model/account"
+	model_company "certification/model/company"
+	model_permission "certification/model/permission"
+	model_user "certification/model/user"
+	"certification/template"
+	"context"
+	"mime/multipart"
+	"strconv"
 
+	"github.com/Boostport/mjml-go"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -22,21 +33,23 @@ type Access struct {
 type IncomingLogin struct {
 	Email    string `json:"email" validate:"required"`
 	Password string `json:"password" validate:"required"`
+	Device   string `json:"device" validate:"-"`
 }
 
+// Every failure except an inactive account returns ErrLoginFailed so the email cannot be probed.
+// The status is only revealed once the password is proven.
 func CheckLogin(account *model_account.Account, body IncomingLogin, role constant.AccountRoleType, db *gorm.DB, ctx *fiber.Ctx) error {
+	if !CheckPasswordHash(body.Password, account.Password) {
+		logger.Log.Error("Invalid password by: ", body.Email)
+		return ErrLoginFailed
+	}
 	if account.Role != role {
 		logger.Log.Error("Unauthorized account for email: ", account.Email)
-		return errors.New("Unauthorized account")
+		return ErrLoginFailed
 	}
-	if account.Status != "active" {
+	if account.Status != constant.ACTIVE {
 		logger.Log.Error("Inactive account for email: ", account.Email)
-		return errors.New("Inactive account")
-	}
-
-	if !CheckPasswordHash(body.Password, account.Password) {
-		logger.Log.Error("Invalid password by: ", body.Email)
-		return errors.New("Invalid password")
+		return ErrInactiveAccount
 	}
 	return nil
 }
@@ -51,3 +64,74 @@ func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// Render the MJML template and send it to the given email
+func SendTemplateEmail(mjmlTemplate string, subject string, email string) error {
+	html, err := mjml.ToHTML(context.Background(), mjmlTemplate, mjml.WithMinify(true))
+	if err != nil {
+		return errors.Wrap(err, "Unable to convert MJML to HTML")
+	}
+
+	var emptyFile *multipart.FileHeader
+	return mailer.SendEmail(html, subject, []string{email}, emptyFile)
+}
+
+// Render the activation email and send it to the account's email
+func SendActivationEmail(initializer *database.Initializer, email string, username string, token string) error {
+	return SendTemplateEmail(template.TemplateEmailInvitation(initializer, username, token), "Welcome to CertFirst!", email)
+}
+
+// Name used to greet the account in emails, falls back to the email
+func GetDisplayName(db *gorm.DB, account *model_account.Account) string {
+	switch account.Role {
+	case constant.ROLE_USER:
+		if user, err := model_user.GetUserByAccountID(db, account.ID); err == nil && user.FullName() != "" {
+			return user.FullName()
+		}
+	case constant.ROLE_COMPANY:
+		if company, err := model_company.GetCompanyByAccountID(db, account.ID); err == nil && company.Name != "" {
+			return company.Name
+		}
+	}
+	return account.Email
+}
+
+// Module permissions of the account stored with its session
+func GetModules(db *gorm.DB, account *model_account.Account) []map[string]interface{} {
+	modulesArr := make([]map[string]interface{}, 0)
+
+	role, err := model_permission.GetRoleForAccount(db, account.RoleID, account.Role)
+	if err != nil {
+		logger.Log.Error("No permission role for account: ", account.ID)
+		return modulesArr
+	}
+
+	access := role.Permissions
+	for i := 0; i < len(access); i++ {
+		moduleMap := map[string]interface{}{
+			"module_id":     strconv.FormatUint(uint64(access[i].ModuleID), 10),
+			"module_access": access[i].ModuleAccess,
+			"read_access":   access[i].ReadAccess,
+			"write_access":  access[i].WriteAccess,
+			"delete_access": access[i].DeleteAccess,
+		}
+		modulesArr = append(modulesArr, moduleMap)
+	}
+
+	return modulesArr
+}
+
+// ID of the user or company profile linked to the account
+func GetProfileID(db *gorm.DB, account *model_account.Account) uuid.UUID {
+	switch account.Role {
+	case constant.ROLE_USER:
+		if user, err := model_user.GetUserByAccountID(db, account.ID); err == nil {
+			return user.ID
+		}
+	case constant.ROLE_COMPANY:
+		if company, err := model_company.GetCompanyByAccountID(db, account.ID); err == nil {
+			return company.ID
+		}
+	}
+	return uuid.Nil
+}
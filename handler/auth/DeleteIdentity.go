@@ -0,0 +1,61 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_identity "certification/model/identity"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Unlink Identity
+// @Description Unlink a provider from the logged in account, the account must keep a way to log in
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Identity ID"
+// @Success 200 {object} response.MessageResponse "Successful unlink"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Identity not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/identities/{id} [delete]
+func DeleteIdentity(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var identityID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &identityID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	identity, err := model_identity.GetIdentityByID(initializer.DB, accountID, identityID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Identity not found"))
+	}
+
+	account, err := model_account.GetAccountByID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if account.Password == "" && model_identity.CountIdentities(initializer.DB, accountID) <= 1 {
+		errMsg := "Set a password before unlinking the last provider"
+		logger.Log.Error(errMsg, accountID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	if err := model_identity.DeleteIdentity(initializer.DB, identity.ID); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(constant.ErrorDeleteRecord))
+	}
+
+	logger.Log.Info(constant.SuccessDeleteRecord, identity.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessDeleteRecord))
+}
@@ -0,0 +1,68 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Revoke Session
+// @Description Log out one session of the logged in account
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.MessageResponse "Successful revoke session"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Session not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/sessions/{id} [delete]
+func DeleteSession(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var sessionID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &sessionID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	// Only sessions of the logged in account can be found under its key
+	if _, err := GetSession(accountID, sessionID.String()); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Session not found"))
+	}
+
+	if err := RevokeSession(accountID, sessionID.String()); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Session revoked ", sessionID, " for ", accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessDeleteRecord))
+}
+
+// @Summary Revoke Other Sessions
+// @Description Log out every session of the logged in account except the current one
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.MessageResponse "Successful revoke sessions"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/sessions [delete]
+func DeleteOtherSessions(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+	sessionID, _ := ctx.Locals("session_id").(string)
+
+	if err := RevokeOtherSessions(accountID, sessionID); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Other sessions revoked for ", accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessDeleteRecord))
+}
@@ -0,0 +1,48 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_wallet "certification/model/wallet"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Unlink Wallet
+// @Description Unlink a wallet from the logged in account
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Success 200 {object} response.MessageResponse "Successful unlink"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Wallet not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/wallets/{id} [delete]
+func DeleteWallet(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var walletID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &walletID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	wallet, err := model_wallet.GetWalletByID(initializer.DB, accountID, walletID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Wallet not found"))
+	}
+
+	if err := model_wallet.DeleteWallet(initializer.DB, wallet.ID); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(constant.ErrorDeleteRecord))
+	}
+
+	logger.Log.Info(constant.SuccessDeleteRecord, wallet.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessDeleteRecord))
+}
@@ -0,0 +1,31 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	model_token "certification/model/token"
+	"certification/template"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Activation Page
+// @Description Page of the activation link sent by email, it submits the token to POST /auth/activate and does not use it
+// @Tags Auth
+// @Produce html
+// @Param token query string true "Validation token"
+// @Success 200 {string} string "Confirmation page"
+// @Router /auth/activate [get]
+func GetActivateAccount(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	tokenStr := ctx.Query("token")
+
+	valid := false
+	if token, err := model_token.GetTokenByToken(initializer.DB, tokenStr, constant.VALIDATION_TOKEN); err == nil {
+		valid = token.Status == constant.PENDING && time.Now().Before(token.ExpireAt)
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return ctx.Status(fiber.StatusOK).SendString(template.TemplateActivatePage(tokenStr, valid))
+}
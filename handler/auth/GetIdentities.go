@@ -0,0 +1,31 @@
+package handler_auth
+
+import (
+	"certification/database"
+	"certification/logger"
+	model_identity "certification/model/identity"
+	"certification/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Get Linked Identities
+// @Description List the OpenID Connect providers linked to the logged in account
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]model_identity.LinkedIdentity} "Successful get identities"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/identities [get]
+func GetIdentities(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	identities, err := model_identity.GetIdentitiesByAccountID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(identities, "Successfully get identities"))
+}
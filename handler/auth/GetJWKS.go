@@ -0,0 +1,18 @@
+package handler_auth
+
+import (
+	"certification/jwtkey"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary JSON Web Key Set
+// @Description Public keys that verify the access tokens, including retired keys still in their grace period
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} jwtkey.JWKS "Key set"
+// @Router /.well-known/jwks.json [get]
+func GetJWKS(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.Status(fiber.StatusOK).JSON(jwtkey.PublicJWKS())
+}
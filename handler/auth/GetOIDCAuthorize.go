@@ -0,0 +1,54 @@
+package handler_auth
+
+import (
+	"certification/database"
+	"certification/logger"
+	"certification/oidc"
+	"certification/response"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/oauth2"
+)
+
+type ResponseOIDCAuthorize struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// @Summary OIDC Authorize
+// @Description Start a login with an OpenID Connect provider, the client redirects to the returned URL
+// @Tags Auth
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param device query string false "Device name of the session"
+// @Success 200 {object} response.DataResponse{data=ResponseOIDCAuthorize} "Successful authorize"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/oidc/{provider} [get]
+func OIDCAuthorize(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	providerName := ctx.Params("provider")
+
+	provider, err := oidc.GetProvider(ctx.Context(), providerName)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Unknown or unavailable provider"))
+	}
+
+	value := OIDCStateValue{
+		Provider: providerName,
+		Verifier: oauth2.GenerateVerifier(),
+		Nonce:    oauth2.GenerateVerifier(),
+		Device:   ctx.Query("device"),
+	}
+
+	state, err := CreateOIDCState(value)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody("Error in generating state"))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseOIDCAuthorize{
+		AuthorizationURL: provider.AuthCodeURL(state, value.Nonce, value.Verifier),
+		State:            state,
+	}, "Successfully created authorization URL"))
+}
@@ -0,0 +1,56 @@
+package handler_auth
+
+import (
+	"certification/database"
+	"certification/logger"
+	"certification/response"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ResponseSession struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+// @Summary Get Sessions
+// @Description List the active sessions of the logged in account
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]ResponseSession} "Successful get sessions"
+// @Failure 401 {object} response.MessageResponse "Unauthorized"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/sessions [get]
+func GetSessions(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+	sessionID, _ := ctx.Locals("session_id").(string)
+
+	sessions, err := ListSessions(accountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	responseSessions := make([]ResponseSession, len(sessions))
+	for i, session := range sessions {
+		responseSessions[i] = ResponseSession{
+			ID:         session.SessionID,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.SessionID == sessionID,
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(responseSessions, "Successfully get sessions"))
+}
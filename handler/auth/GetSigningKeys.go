@@ -0,0 +1,20 @@
+package handler_auth
+
+import (
+	"certification/jwtkey"
+	"certification/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get Signing Keys
+// @Description List the JWT signing keys with their algorithm and retirement time
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]jwtkey.Key} "Successful get keys"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Router /auth/keys [get]
+func GetSigningKeys(ctx *fiber.Ctx) error {
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(jwtkey.Keys(), "Successfully get keys"))
+}
@@ -0,0 +1,31 @@
+package handler_auth
+
+import (
+	"certification/database"
+	"certification/logger"
+	model_wallet "certification/model/wallet"
+	"certification/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Get Wallets
+// @Description List the wallets linked to the logged in account
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]model_wallet.Wallet} "Successful get wallets"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/wallets [get]
+func GetWallets(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	wallets, err := model_wallet.GetWalletsByAccountID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(wallets, "Successfully get wallets"))
+}
@@ -0,0 +1,98 @@
+package handler_auth
+
+import (
+	"certification/cache"
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	"certification/template"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrLoginFailed     = errors.New("Invalid email or password")
+	ErrInactiveAccount = errors.New("Account is not active")
+	ErrLoginLocked     = errors.New("Too many failed login attempts, please try again later")
+)
+
+// Compared against when the email is unknown so the response time does not reveal it
+var dummyPasswordHash, _ = HashPassword("dummy-password-for-timing")
+
+func loginGuardKey(prefix string, email string) string {
+	return fmt.Sprintf("%s:%s", prefix, strings.ToLower(strings.TrimSpace(email)))
+}
+
+// Returns how long the email has to wait before the next login attempt
+func CheckLoginAllowed(email string) (time.Duration, error) {
+	ctx := context.Background()
+
+	if ttl, err := cache.Redis.RDB.TTL(ctx, loginGuardKey(constant.REDIS_LOGIN_LOCK, email)).Result(); err == nil && ttl > 0 {
+		return ttl, ErrLoginLocked
+	}
+
+	if ttl, err := cache.Redis.RDB.TTL(ctx, loginGuardKey(constant.REDIS_LOGIN_DELAY, email)).Result(); err == nil && ttl > 0 {
+		return ttl, errors.New(constant.ErrorTooManyRequest)
+	}
+
+	return 0, nil
+}
+
+// Count a failed login for the email. Each failure after LOGIN_DELAY_AFTER doubles the
+// wait before the next attempt, and LOGIN_LOCKOUT_AFTER failures lock the email out.
+func RecordLoginFailure(initializer *database.Initializer, email string, account *model_account.Account, ipAddress string) {
+	ctx := context.Background()
+	failureKey := loginGuardKey(constant.REDIS_LOGIN_FAILURE, email)
+
+	failures, err := cache.Redis.RDB.Incr(ctx, failureKey).Result()
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+	cache.Redis.RDB.Expire(ctx, failureKey, constant.LOGIN_FAILURE_WINDOW)
+
+	if failures >= constant.LOGIN_LOCKOUT_AFTER {
+		cache.Redis.RDB.Set(ctx, loginGuardKey(constant.REDIS_LOGIN_LOCK, email), failures, constant.LOGIN_LOCKOUT)
+		cache.Redis.RDB.Del(ctx, failureKey, loginGuardKey(constant.REDIS_LOGIN_DELAY, email))
+		logger.Log.Error("Login locked for ", email)
+
+		if account != nil {
+			go SendLockoutEmail(initializer, account, ipAddress)
+		}
+		return
+	}
+
+	if failures >= constant.LOGIN_DELAY_AFTER {
+		delay := time.Second << (failures - constant.LOGIN_DELAY_AFTER)
+		if delay > constant.LOGIN_MAX_DELAY {
+			delay = constant.LOGIN_MAX_DELAY
+		}
+		cache.Redis.RDB.Set(ctx, loginGuardKey(constant.REDIS_LOGIN_DELAY, email), failures, delay)
+	}
+}
+
+// Clear the failed logins of the email after a successful login
+func ResetLoginFailures(email string) {
+	err := cache.Redis.RDB.Del(context.Background(),
+		loginGuardKey(constant.REDIS_LOGIN_FAILURE, email),
+		loginGuardKey(constant.REDIS_LOGIN_DELAY, email),
+	).Err()
+	if err != nil {
+		logger.Log.Error(err)
+	}
+}
+
+// Tell the owner of the account that logging in has been paused
+func SendLockoutEmail(initializer *database.Initializer, account *model_account.Account, ipAddress string) {
+	username := GetDisplayName(initializer.DB, account)
+	mjmlTemplate := template.TemplateAccountLocked(initializer, username, ipAddress, int(constant.LOGIN_LOCKOUT.Minutes()))
+
+	if err := SendTemplateEmail(mjmlTemplate, "Your CertFirst account has been locked", account.Email); err != nil {
+		logger.Log.Error(err)
+	}
+}
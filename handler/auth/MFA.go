@@ -0,0 +1,325 @@
+package handler_auth
+
+import (
+	"certification/cache"
+	"certification/constant"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_company "certification/model/company"
+	model_mfa "certification/model/mfa"
+	"certification/utils"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+type ResponseMFAEnrollment struct {
+	Secret string `json:"secret"`
+	OTPURL string `json:"otp_url"`
+	QRCode string `json:"qr_code"` // PNG data URI
+}
+
+type ResponseRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Stored in Redis under the hash of the challenge token until the second factor is verified
+type MFAChallengeValue struct {
+	AccountID          uuid.UUID `json:"account_id"`
+	Device             string    `json:"device"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+}
+
+var ErrMFAChallengeLocked = errors.New("Too many failed attempts, please log in again")
+
+func mfaChallengeKey(hash string) string {
+	return fmt.Sprintf("%s:%s", constant.REDIS_MFA_CHALLENGE, hash)
+}
+
+// Two-factor authentication is required when the account's company enforces it
+func IsMFARequired(db *gorm.DB, account *model_account.Account) bool {
+	company, err := model_company.GetCompanyByAccountID(db, account.ID)
+	return err == nil && company.RequireMFA
+}
+
+// Start a short-lived challenge when the account must pass a second factor,
+// returns an empty token when the login can be completed right away
+func CreateMFAChallenge(db *gorm.DB, account *model_account.Account, device string) (string, bool, time.Time, error) {
+	isEnabled := model_mfa.IsMFAEnabled(db, account.ID)
+	if !isEnabled && !IsMFARequired(db, account) {
+		return "", false, time.Time{}, nil
+	}
+
+	token, hash, err := utils.GenerateHashedToken()
+	if err != nil {
+		return "", false, time.Time{}, err
+	}
+
+	value := MFAChallengeValue{
+		AccountID:          account.ID,
+		Device:             device,
+		EnrollmentRequired: !isEnabled,
+	}
+
+	jsonData, err := jsoniter.Marshal(value)
+	if err != nil {
+		return "", false, time.Time{}, err
+	}
+
+	ctx := context.Background()
+	key := mfaChallengeKey(hash)
+
+	pipe := cache.Redis.RDB.TxPipeline()
+	pipe.HSet(ctx, key, "data", jsonData, "attempts", 0)
+	pipe.Expire(ctx, key, constant.MFA_CHALLENGE_EXPIRY)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", false, time.Time{}, err
+	}
+
+	return token, value.EnrollmentRequired, time.Now().Add(constant.MFA_CHALLENGE_EXPIRY), nil
+}
+
+// Look up a pending challenge
+func GetMFAChallenge(token string) (*MFAChallengeValue, error) {
+	results, err := cache.Redis.RDB.HGet(context.Background(), mfaChallengeKey(utils.HashToken(token)), "data").Result()
+	if err != nil {
+		return nil, errors.New(constant.ErrorInvalidToken)
+	}
+
+	var value MFAChallengeValue
+	if err := json.Unmarshal([]byte(results), &value); err != nil {
+		return nil, errors.New(constant.ErrorInvalidToken)
+	}
+
+	return &value, nil
+}
+
+// Count a failed attempt, the challenge is dropped once the limit is reached
+func FailMFAChallenge(token string) error {
+	ctx := context.Background()
+	key := mfaChallengeKey(utils.HashToken(token))
+
+	attempts, err := cache.Redis.RDB.HIncrBy(ctx, key, "attempts", 1).Result()
+	if err != nil {
+		return err
+	}
+
+	if attempts >= constant.MFA_MAX_ATTEMPTS {
+		cache.Redis.RDB.Del(ctx, key)
+		return ErrMFAChallengeLocked
+	}
+
+	return errors.New(constant.ErrorInvalidMFACode)
+}
+
+// Count a wrong code of a logged in account, e.g. to disable MFA. Like a login challenge,
+// MFA_MAX_ATTEMPTS wrong codes end the sessions of the account so it has to log in again.
+func FailMFACode(accountID uuid.UUID) error {
+	ctx := context.Background()
+	key := fmt.Sprintf("%s:%s", constant.REDIS_MFA_FAILURE, accountID)
+
+	attempts, err := cache.Redis.RDB.Incr(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	cache.Redis.RDB.Expire(ctx, key, constant.MFA_FAILURE_WINDOW)
+
+	if attempts >= constant.MFA_MAX_ATTEMPTS {
+		cache.Redis.RDB.Del(ctx, key)
+		if err := RevokeSessions(accountID); err != nil {
+			logger.Log.Error(err)
+		}
+		return ErrMFAChallengeLocked
+	}
+
+	return errors.New(constant.ErrorInvalidMFACode)
+}
+
+// Clear the wrong codes of the account after a correct one
+func ResetMFAFailures(accountID uuid.UUID) {
+	key := fmt.Sprintf("%s:%s", constant.REDIS_MFA_FAILURE, accountID)
+	if err := cache.Redis.RDB.Del(context.Background(), key).Err(); err != nil {
+		logger.Log.Error(err)
+	}
+}
+
+func DeleteMFAChallenge(token string) error {
+	return cache.Redis.RDB.Del(context.Background(), mfaChallengeKey(utils.HashToken(token))).Err()
+}
+
+// Generate a TOTP secret for the account, the returned secret is not encrypted yet
+func GenerateMFAKey(email string) (*otp.Key, *ResponseMFAEnrollment, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      constant.MFA_ISSUER,
+		AccountName: email,
+		Period:      constant.MFA_PERIOD,
+		Digits:      otp.DigitsSix,
+		Algorithm:   otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	png, err := qrcode.Encode(key.URL(), qrcode.Medium, 256)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, &ResponseMFAEnrollment{
+		Secret: key.Secret(),
+		OTPURL: key.URL(),
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Generate and store the secret of an account which has not enabled mfa yet
+func EnrollMFAForAccount(db *gorm.DB, account *model_account.Account) (*ResponseMFAEnrollment, error) {
+	if model_mfa.IsMFAEnabled(db, account.ID) {
+		return nil, errors.New("Two-factor authentication is already enabled")
+	}
+
+	key, enrollment, err := GenerateMFAKey(account.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.EncryptString(key.Secret())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := model_mfa.SavePendingMFA(db, account.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return enrollment, nil
+}
+
+// Check the code against the current time step and one step either side.
+// Steps already accepted are skipped so a code cannot be replayed.
+func ValidateTOTP(secret string, code string, lastUsedStep int64) (int64, bool) {
+	now := time.Now()
+	opts := totp.ValidateOpts{
+		Period:    constant.MFA_PERIOD,
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	}
+
+	for _, skew := range []int64{0, -1, 1} {
+		t := now.Add(time.Duration(skew*constant.MFA_PERIOD) * time.Second)
+		step := t.Unix() / constant.MFA_PERIOD
+		if step <= lastUsedStep {
+			continue
+		}
+
+		expected, err := totp.GenerateCodeCustom(secret, t, opts)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// Confirm the pending secret with the first code and enable mfa, returns the recovery codes
+func ConfirmMFAForAccount(db *gorm.DB, accountID uuid.UUID, code string) ([]string, error) {
+	mfa, err := model_mfa.GetMFAByAccountID(db, accountID)
+	if err != nil || mfa.Enabled {
+		return nil, errors.New("No pending two-factor authentication enrollment")
+	}
+
+	secret, err := utils.DecryptString(mfa.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := ValidateTOTP(secret, code, mfa.LastUsedStep)
+	if !ok {
+		return nil, errors.New(constant.ErrorInvalidMFACode)
+	}
+
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := db.Begin()
+
+	if err := model_mfa.EnableMFA(tx, accountID, step); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := model_mfa.ReplaceRecoveryCodes(tx, accountID, hashes); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify a TOTP code or an unused recovery code of an account with mfa enabled
+func VerifyMFACode(db *gorm.DB, accountID uuid.UUID, code string) bool {
+	mfa, err := model_mfa.GetMFAByAccountID(db, accountID)
+	if err != nil || !mfa.Enabled {
+		return false
+	}
+
+	secret, err := utils.DecryptString(mfa.Secret)
+	if err != nil {
+		return false
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := ValidateTOTP(secret, code, mfa.LastUsedStep); ok {
+		return model_mfa.UpdateLastUsedStep(db, accountID, step)
+	}
+
+	return model_mfa.UseRecoveryCode(db, accountID, utils.HashToken(normalizeRecoveryCode(code)))
+}
+
+// Generate recovery codes formatted as xxxxx-xxxxx together with their hashes
+func GenerateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, constant.MFA_RECOVERY_CODES)
+	hashes := make([]string, constant.MFA_RECOVERY_CODES)
+
+	for i := range codes {
+		raw := make([]byte, 10)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %v", err)
+		}
+
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw))[:10]
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = utils.HashToken(code)
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
@@ -0,0 +1,149 @@
+package handler_auth
+
+import (
+	"certification/cache"
+	"certification/constant"
+	model_account "certification/model/account"
+	model_identity "certification/model/identity"
+	model_user "certification/model/user"
+	"certification/oidc"
+	"certification/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Stored in Redis under the hash of the state until the provider redirects back
+type OIDCStateValue struct {
+	Provider string `json:"provider"`
+	Verifier string `json:"verifier"` // PKCE code verifier
+	Nonce    string `json:"nonce"`
+	Device   string `json:"device"`
+}
+
+var (
+	ErrOIDCState            = errors.New("Invalid or expired login state")
+	ErrOIDCEmailNotVerified = errors.New("The provider did not return a verified email")
+	ErrOIDCAccountNotUser   = errors.New("The email is registered to an account that cannot use social login")
+)
+
+func oidcStateKey(hash string) string {
+	return fmt.Sprintf("%s:%s", constant.REDIS_OIDC_STATE, hash)
+}
+
+// Save the PKCE verifier and nonce of a login until the callback
+func CreateOIDCState(value OIDCStateValue) (string, error) {
+	state, hash, err := utils.GenerateHashedToken()
+	if err != nil {
+		return "", err
+	}
+
+	jsonData, err := jsoniter.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	err = cache.Redis.RDB.Set(context.Background(), oidcStateKey(hash), jsonData, constant.OIDC_STATE_EXPIRY).Err()
+	return state, err
+}
+
+// Get and remove the state, a state can only be used once
+func ConsumeOIDCState(state string, provider string) (*OIDCStateValue, error) {
+	results, err := cache.Redis.RDB.GetDel(context.Background(), oidcStateKey(utils.HashToken(state))).Result()
+	if err != nil {
+		return nil, ErrOIDCState
+	}
+
+	var value OIDCStateValue
+	if err := json.Unmarshal([]byte(results), &value); err != nil || value.Provider != provider {
+		return nil, ErrOIDCState
+	}
+	return &value, nil
+}
+
+// Find the account linked to the identity, otherwise link the user account with the same
+// verified email or create a new one
+func FindOrCreateOIDCAccount(db *gorm.DB, provider string, claims *oidc.Claims) (*model_account.Account, error) {
+	identity, err := model_identity.GetIdentityBySubject(db, provider, claims.Subject)
+	if err == nil {
+		if err := model_identity.UpdateLastLogin(db, identity.ID, claims.Email); err != nil {
+			return nil, err
+		}
+		return model_account.GetAccountByID(db, identity.AccountID)
+	}
+
+	if !claims.EmailVerified || claims.Email == "" {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	tx := db.Begin()
+	defer utils.DiscardPendingSessions(tx)
+
+	account, err := model_account.GetAccountByEmail(tx, claims.Email)
+	if err == nil {
+		if account.Role != constant.ROLE_USER {
+			tx.Rollback()
+			return nil, ErrOIDCAccountNotUser
+		}
+
+		// The password of an account that was never activated may have been set by someone
+		// else than the owner of the email, it is removed before the account is linked
+		if account.Status == constant.PENDING {
+			if err := model_account.UpdateAccountPassword(tx, account.ID, ""); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			if err := model_account.UpdateAccountStatus(tx, account.ID, constant.ACTIVE); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			account.Status = constant.ACTIVE
+		}
+	} else {
+		accountID := uuid.New()
+		account = &model_account.Account{
+			ID:     accountID,
+			Email:  claims.Email,
+			Role:   constant.ROLE_USER,
+			Status: constant.ACTIVE, // the provider verified the email
+		}
+
+		user := model_user.User{
+			AccountID: &accountID,
+			FirstName: claims.GivenName,
+			LastName:  claims.FamilyName,
+		}
+
+		if err := tx.Create(account).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	identity = &model_identity.LinkedIdentity{
+		AccountID: account.ID,
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+	}
+	if err := tx.Create(identity).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := utils.CommitTransaction(tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return account, nil
+}
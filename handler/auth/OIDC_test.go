@@ -0,0 +1,237 @@
+package handler_auth
+
+import (
+	"certification/cache"
+	"certification/constant"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_identity "certification/model/identity"
+	model_user "certification/model/user"
+	"certification/oidc"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gorm_logger "gorm.io/gorm/logger"
+)
+
+// Random UUID in the same text form as the IDs written by the application
+const uuidDefault = `(lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-' || hex(randomblob(2)) || '-' ||
+	hex(randomblob(2)) || '-' || hex(randomblob(6))))`
+
+// In-memory database with the tables used to link identities, Redis is unreachable
+// so invalidating the sessions of an updated account only logs an error
+func newOIDCTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	logger.Log = zap.NewNop().Sugar()
+	cache.Redis.RDB = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gorm_logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, stmt := range []string{
+		`CREATE TABLE accounts (id TEXT PRIMARY KEY, created_at DATETIME, updated_at DATETIME,
+			email TEXT, password TEXT, role TEXT, status TEXT, role_id INTEGER)`,
+		`CREATE TABLE users (id TEXT PRIMARY KEY DEFAULT ` + uuidDefault + `,
+			account_id TEXT, first_name TEXT, last_name TEXT)`,
+		`CREATE TABLE linked_identities (id TEXT PRIMARY KEY DEFAULT ` + uuidDefault + `,
+			created_at DATETIME, updated_at DATETIME, account_id TEXT, provider TEXT, subject TEXT,
+			email TEXT, last_login_at DATETIME, UNIQUE (provider, subject))`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func createTestAccount(t *testing.T, db *gorm.DB, email string, role constant.AccountRoleType, status constant.Status) *model_account.Account {
+	t.Helper()
+
+	account := &model_account.Account{
+		ID:       uuid.New(),
+		Email:    email,
+		Password: "password-hash",
+		Role:     role,
+		Status:   status,
+	}
+	if err := db.Create(account).Error; err != nil {
+		t.Fatal(err)
+	}
+	return account
+}
+
+func verifiedClaims(subject string, email string) *oidc.Claims {
+	return &oidc.Claims{
+		Subject:       subject,
+		Email:         email,
+		EmailVerified: true,
+		GivenName:     "Jane",
+		FamilyName:    "Doe",
+	}
+}
+
+func countRows(t *testing.T, db *gorm.DB, model interface{}) int64 {
+	t.Helper()
+
+	var count int64
+	if err := db.Model(model).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestFindOrCreateOIDCAccountCreatesUser(t *testing.T) {
+	db := newOIDCTestDB(t)
+
+	account, err := FindOrCreateOIDCAccount(db, "google", verifiedClaims("subject-1", "new@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Email != "new@example.com" || account.Role != constant.ROLE_USER || account.Status != constant.ACTIVE {
+		t.Fatalf("unexpected account %+v", account)
+	}
+
+	user, err := model_user.GetUserByAccountID(db, account.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.FirstName != "Jane" || user.LastName != "Doe" {
+		t.Fatalf("unexpected user %+v", user)
+	}
+
+	identity, err := model_identity.GetIdentityBySubject(db, "google", "subject-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.AccountID != account.ID {
+		t.Fatal("identity is linked to another account")
+	}
+}
+
+func TestFindOrCreateOIDCAccountUsesLinkedIdentity(t *testing.T) {
+	db := newOIDCTestDB(t)
+
+	first, err := FindOrCreateOIDCAccount(db, "google", verifiedClaims("subject-1", "user@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The email at the provider changed, the subject still identifies the account
+	claims := verifiedClaims("subject-1", "renamed@example.com")
+	claims.EmailVerified = false
+	second, err := FindOrCreateOIDCAccount(db, "google", claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID {
+		t.Fatal("another account was used for the same subject")
+	}
+	if count := countRows(t, db, &model_account.Account{}); count != 1 {
+		t.Fatalf("%d accounts were created", count)
+	}
+
+	identity, err := model_identity.GetIdentityBySubject(db, "google", "subject-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Email != "renamed@example.com" || identity.LastLoginAt == nil {
+		t.Fatalf("login was not recorded on the identity %+v", identity)
+	}
+
+	// The same subject at another provider is another identity
+	other, err := FindOrCreateOIDCAccount(db, "microsoft", verifiedClaims("subject-1", "other@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.ID == first.ID {
+		t.Fatal("identity of another provider was linked to the account")
+	}
+}
+
+func TestFindOrCreateOIDCAccountLinksActiveUser(t *testing.T) {
+	db := newOIDCTestDB(t)
+	existing := createTestAccount(t, db, "user@example.com", constant.ROLE_USER, constant.ACTIVE)
+
+	account, err := FindOrCreateOIDCAccount(db, "google", verifiedClaims("subject-1", "user@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.ID != existing.ID {
+		t.Fatal("a new account was created for a registered email")
+	}
+
+	stored, err := model_account.GetAccountByID(db, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Password != "password-hash" {
+		t.Fatal("password of an active account was removed")
+	}
+	if count := countRows(t, db, &model_account.Account{}); count != 1 {
+		t.Fatalf("%d accounts were created", count)
+	}
+}
+
+func TestFindOrCreateOIDCAccountLinksPendingUser(t *testing.T) {
+	db := newOIDCTestDB(t)
+	existing := createTestAccount(t, db, "user@example.com", constant.ROLE_USER, constant.PENDING)
+
+	account, err := FindOrCreateOIDCAccount(db, "google", verifiedClaims("subject-1", "user@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.ID != existing.ID || account.Status != constant.ACTIVE {
+		t.Fatalf("unexpected account %+v", account)
+	}
+
+	stored, err := model_account.GetAccountByID(db, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Password != "" || stored.Status != constant.ACTIVE {
+		t.Fatal("password set before the email was verified was kept")
+	}
+}
+
+func TestFindOrCreateOIDCAccountRejectsCompany(t *testing.T) {
+	db := newOIDCTestDB(t)
+	createTestAccount(t, db, "company@example.com", constant.ROLE_COMPANY, constant.ACTIVE)
+
+	if _, err := FindOrCreateOIDCAccount(db, "google", verifiedClaims("subject-1", "company@example.com")); err != ErrOIDCAccountNotUser {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if count := countRows(t, db, &model_identity.LinkedIdentity{}); count != 0 {
+		t.Fatal("identity was linked to a company account")
+	}
+}
+
+func TestFindOrCreateOIDCAccountRequiresVerifiedEmail(t *testing.T) {
+	db := newOIDCTestDB(t)
+	createTestAccount(t, db, "user@example.com", constant.ROLE_USER, constant.ACTIVE)
+
+	claims := verifiedClaims("subject-1", "user@example.com")
+	claims.EmailVerified = false
+	if _, err := FindOrCreateOIDCAccount(db, "google", claims); err != ErrOIDCEmailNotVerified {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if _, err := FindOrCreateOIDCAccount(db, "google", verifiedClaims("subject-1", "")); err != ErrOIDCEmailNotVerified {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if count := countRows(t, db, &model_identity.LinkedIdentity{}); count != 0 {
+		t.Fatal("identity was linked without a verified email")
+	}
+}
@@ -0,0 +1,134 @@
+package handler_auth
+
+import (
+	"certification/cache"
+	"certification/constant"
+	"certification/database"
+	model_account "certification/model/account"
+	model_token "certification/model/token"
+	"certification/template"
+	"certification/utils"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOTPLocked   = errors.New("Too many failed attempts, please try again later")
+	ErrOTPCooldown = errors.New("Please wait before requesting another code")
+	ErrOTPInvalid  = errors.New("Invalid or expired code")
+)
+
+func otpLockKey(accountID uuid.UUID, purpose string) string {
+	return fmt.Sprintf("%s:%s:%s", constant.REDIS_OTP_LOCK, accountID, purpose)
+}
+
+func otpVerifiedKey(hash string) string {
+	return fmt.Sprintf("%s:%s", constant.REDIS_OTP_VERIFIED, hash)
+}
+
+func isOTPLocked(accountID uuid.UUID, purpose string) bool {
+	count, err := cache.Redis.RDB.Exists(context.Background(), otpLockKey(accountID, purpose)).Result()
+	return err == nil && count > 0
+}
+
+// Generate an OTP for the purpose and email it to the account
+func SendOTP(initializer *database.Initializer, account *model_account.Account, purpose string) error {
+	if isOTPLocked(account.ID, purpose) {
+		return ErrOTPLocked
+	}
+
+	latest, err := model_token.GetLatestToken(initializer.DB, account.ID, constant.OTP_TOKEN, purpose)
+	if err == nil && time.Since(latest.CreatedAt) < constant.OTP_RESEND_COOLDOWN {
+		return ErrOTPCooldown
+	}
+
+	tx := initializer.DB.Begin()
+
+	otp, err := utils.GenerateOTPToken(account.ID, purpose, tx)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	username := GetDisplayName(initializer.DB, account)
+
+	mjmlTemplate := template.TemplateVerifyOTP(initializer, username, otp)
+	subject := "Your FirstCert verification code"
+	if purpose == constant.OTP_PURPOSE_CLAIM_CERTIFICATE {
+		mjmlTemplate = template.TemplateOTP(initializer, username, account.Email, otp)
+		subject = "Your FirstCert certificate claim code"
+	}
+
+	return SendTemplateEmail(mjmlTemplate, subject, account.Email)
+}
+
+// Check the code against the newest OTP of the purpose. The OTP is consumed on success,
+// each failure is counted and the purpose is locked once the attempts run out.
+func VerifyOTP(db *gorm.DB, accountID uuid.UUID, purpose string, code string) error {
+	if isOTPLocked(accountID, purpose) {
+		return ErrOTPLocked
+	}
+
+	token, err := model_token.GetLatestToken(db, accountID, constant.OTP_TOKEN, purpose)
+	if err != nil || token.Status != constant.PENDING || time.Now().After(token.ExpireAt) {
+		return ErrOTPInvalid
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token.Token), []byte(code)) == 1 {
+		return model_token.UpdateTokenStatusByID(token.ID, constant.USED, db)
+	}
+
+	attempts, err := model_token.IncrementTokenAttempts(token.ID, db)
+	if err != nil {
+		return err
+	}
+
+	if attempts >= constant.OTP_MAX_ATTEMPTS {
+		if err := model_token.UpdateTokenStatusByID(token.ID, constant.FAILED, db); err != nil {
+			return err
+		}
+		if err := cache.Redis.RDB.Set(context.Background(), otpLockKey(accountID, purpose), attempts, constant.OTP_LOCKOUT).Err(); err != nil {
+			return err
+		}
+		return ErrOTPLocked
+	}
+
+	return ErrOTPInvalid
+}
+
+// Issue a short-lived proof that the account passed an OTP check for the purpose
+func CreateStepUpToken(accountID uuid.UUID, purpose string) (string, time.Time, error) {
+	token, hash, err := utils.GenerateHashedToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	value := fmt.Sprintf("%s:%s", accountID, purpose)
+	err = cache.Redis.RDB.Set(context.Background(), otpVerifiedKey(hash), value, constant.OTP_VERIFIED_EXPIRY).Err()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, time.Now().Add(constant.OTP_VERIFIED_EXPIRY), nil
+}
+
+// Consume a step-up token, it is only valid once for the account and purpose it was issued for
+func ConsumeStepUpToken(token string, accountID uuid.UUID, purpose string) bool {
+	value, err := cache.Redis.RDB.GetDel(context.Background(), otpVerifiedKey(utils.HashToken(token))).Result()
+	if err != nil {
+		return false
+	}
+
+	expected := fmt.Sprintf("%s:%s", accountID, purpose)
+	return subtle.ConstantTimeCompare([]byte(value), []byte(expected)) == 1
+}
@@ -0,0 +1,63 @@
+package handler_auth
+
+import (
+	"certification/cache"
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_company "certification/model/company"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingCompanyMFA struct {
+	RequireMFA *bool `json:"require_mfa" validate:"required"`
+}
+
+// @Summary Require MFA For Company
+// @Description Require two-factor authentication for every login of the company
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingCompanyMFA body IncomingCompanyMFA true "Require MFA"
+// @Success 200 {object} response.MessageResponse "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/company/mfa [patch]
+func UpdateCompanyMFA(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingCompanyMFA
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	account, err := model_account.GetAccountByID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	company, err := model_company.GetCompanyByAccountID(initializer.DB, account.ID)
+	if err != nil || account.Role != constant.ROLE_COMPANY {
+		logger.Log.Error("Account is not a company: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	if err := model_company.UpdateRequireMFA(initializer.DB, company.ID, *body.RequireMFA); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	cache.Redis.DeleteCacheByIdForId("profile", "", accountID.String())
+
+	logger.Log.Info(constant.SuccessUpdateRecord, company.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessUpdateRecord))
+}
@@ -0,0 +1,60 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_wallet "certification/model/wallet"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingUpdateWallet struct {
+	AllowLogin bool `json:"allow_login"`
+}
+
+// @Summary Update Wallet
+// @Description Allow or forbid logging in with the wallet alone
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Param IncomingUpdateWallet body IncomingUpdateWallet true "Allow login"
+// @Success 200 {object} response.MessageResponse "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Wallet not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/wallets/{id} [patch]
+func UpdateWallet(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var walletID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &walletID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingUpdateWallet
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	wallet, err := model_wallet.GetWalletByID(initializer.DB, accountID, walletID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Wallet not found"))
+	}
+
+	if err := model_wallet.UpdateAllowLogin(initializer.DB, wallet.ID, body.AllowLogin); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info(constant.SuccessUpdateRecord, wallet.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessUpdateRecord))
+}
@@ -0,0 +1,172 @@
+package handler_auth
+
+import (
+	"certification/cache"
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_token "certification/model/token"
+	"certification/response"
+	"certification/utils"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type IncomingActivateAccount struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type IncomingResendActivation struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// @Summary Activate Account
+// @Description Activate a pending account with the validation token sent by email
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param token query string false "Validation token"
+// @Param IncomingActivateAccount body IncomingActivateAccount false "Validation token"
+// @Success 200 {object} response.MessageResponse "Successful activation"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/activate [post]
+func ActivateAccount(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingActivateAccount
+	body.Token = ctx.Query("token")
+	if body.Token == "" {
+		if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+		}
+	}
+
+	token, err := model_token.GetTokenByToken(initializer.DB, body.Token, constant.VALIDATION_TOKEN)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+
+	if token.Status != constant.PENDING || time.Now().After(token.ExpireAt) {
+		logger.Log.Error("Validation token is used or expired for ", token.AccountID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+
+	account, err := model_account.GetAccountByID(initializer.DB, token.AccountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+
+	if account.Status != constant.PENDING {
+		logger.Log.Error("Account is not pending activation: ", account.ID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+
+	tx := initializer.DB.Begin()
+	defer utils.DiscardPendingSessions(tx)
+
+	err = model_account.UpdateAccountStatus(tx, account.ID, constant.ACTIVE)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = model_token.UpdateTokenStatus(token.Token, tx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		logger.Log.Error("Token was used concurrently for ", token.AccountID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := utils.CommitTransaction(tx); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	cache.Redis.DeleteCacheByIdForId("profile", "", account.ID.String())
+
+	logger.Log.Info(constant.SuccessActivate, account.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessActivate))
+}
+
+// @Summary Resend Activation Email
+// @Description Issue a new validation token and invalidate the previous ones
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param IncomingResendActivation body IncomingResendActivation true "Account email"
+// @Success 200 {object} response.MessageResponse "Email sent if the account is pending"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/activate/resend [post]
+func ResendActivation(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingResendActivation
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// Do not reveal whether the email is registered or already active
+	account, err := model_account.GetAccountByEmail(initializer.DB, body.Email)
+	if err != nil || account.Status != constant.PENDING {
+		logger.Log.Info("Skip resending activation email to ", body.Email)
+		return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessSendEmail))
+	}
+
+	tokenStr, err := utils.GenerateToken()
+	if err != nil {
+		logger.Log.Error("Error in generating token for ", account.ID)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody("Error in generating token"))
+	}
+
+	token := model_token.Token{
+		AccountID: account.ID,
+		Token:     tokenStr,
+		ExpireAt:  time.Now().Add(time.Hour * 24 * 7), // 7 days expiry
+		Type:      constant.VALIDATION_TOKEN,
+		Status:    constant.PENDING,
+	}
+
+	tx := initializer.DB.Begin()
+
+	err = model_token.SupersedeTokens(account.ID, constant.VALIDATION_TOKEN, tx)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = tx.Create(&token).Error
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = SendActivationEmail(initializer, account.Email, GetDisplayName(initializer.DB, account), tokenStr)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Activation email resent to ", account.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessSendEmail))
+}
@@ -0,0 +1,182 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_token "certification/model/token"
+	"certification/response"
+	"certification/template"
+	"certification/utils"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type IncomingForgotPassword struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type IncomingResetPassword struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// @Summary Forgot Password
+// @Description Send a reset password link to the email. The response is the same whether the email is registered or not
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param IncomingForgotPassword body IncomingForgotPassword true "Account email"
+// @Success 200 {object} response.MessageResponse "Email sent if the account exists"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/forgot [post]
+func ForgotPassword(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingForgotPassword
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	account, err := model_account.GetAccountByEmail(initializer.DB, body.Email)
+	if err != nil || account.Status == constant.INACTIVE || account.Status == constant.DELETED {
+		logger.Log.Info("Skip sending reset password email to ", body.Email)
+		return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessSendEmail))
+	}
+
+	// The token and the email are made in the background so that the response takes as long
+	// whether the email is registered or not
+	go sendResetPasswordEmail(initializer, account)
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessSendEmail))
+}
+
+// Issue a reset password token superseding the previous ones and email it, errors are only logged
+func sendResetPasswordEmail(initializer *database.Initializer, account *model_account.Account) {
+	tokenStr, err := utils.GenerateToken()
+	if err != nil {
+		logger.Log.Error("Error in generating token for ", account.ID)
+		return
+	}
+
+	token := model_token.Token{
+		AccountID: account.ID,
+		Token:     tokenStr,
+		ExpireAt:  time.Now().Add(time.Minute * 30), // 30 minutes expiry
+		Type:      constant.RESET_PASSWORD_TOKEN,
+		Status:    constant.PENDING,
+	}
+
+	tx := initializer.DB.Begin()
+
+	err = model_token.SupersedeTokens(account.ID, constant.RESET_PASSWORD_TOKEN, tx)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return
+	}
+
+	err = tx.Create(&token).Error
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return
+	}
+
+	username := GetDisplayName(initializer.DB, account)
+	err = SendTemplateEmail(template.TemplateForgotPassword(initializer, username, tokenStr), "Reset your CertFirst password", account.Email)
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+
+	logger.Log.Info("Reset password email sent to ", account.ID)
+}
+
+// @Summary Reset Password
+// @Description Set a new password with the reset password token and log out every session
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param IncomingResetPassword body IncomingResetPassword true "Reset token and new password"
+// @Success 200 {object} response.MessageResponse "Successful reset password"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/reset [post]
+func ResetPassword(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingResetPassword
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	token, err := model_token.GetTokenByToken(initializer.DB, body.Token, constant.RESET_PASSWORD_TOKEN)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+
+	if token.Status != constant.PENDING || time.Now().After(token.ExpireAt) {
+		logger.Log.Error("Reset password token is used or expired for ", token.AccountID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+
+	hashPassword, err := HashPassword(body.Password)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Unable to hash password"))
+	}
+
+	tx := initializer.DB.Begin()
+	defer utils.DiscardPendingSessions(tx)
+
+	err = model_account.UpdateAccountPassword(tx, token.AccountID, hashPassword)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// The token was emailed, so it also proves ownership of accounts created by a certificate claim
+	err = model_account.ActivatePendingAccount(tx, token.AccountID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = model_token.UpdateTokenStatus(token.Token, tx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		logger.Log.Error("Token was used concurrently for ", token.AccountID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := utils.CommitTransaction(tx); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = RevokeSessions(token.AccountID)
+	if err != nil {
+		logger.Log.Errorf("unable to revoke sessions for %s in Redis", token.AccountID)
+	}
+
+	logger.Log.Info(constant.SuccessResetPass, token.AccountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessResetPass))
+}
@@ -1,18 +1,15 @@
 package handler_auth
 
 import (
-	"certification/cache"
 	"certification/constant"
 	"certification/database"
 	"certification/logger"
 	model_account "certification/model/account"
 	"certification/response"
 	"certification/utils"
-	"context"
-	"time"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
-	jsoniter "github.com/json-iterator/go"
 	"gorm.io/gorm"
 )
 
@@ -35,65 +32,54 @@ func LoginUser(ctx *fiber.Ctx, initializer *database.Initializer, db *gorm.DB) e
 		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
 	}
 
+	retryAfter, err := CheckLoginAllowed(body.Email)
+	if err != nil {
+		logger.Log.Error(err.Error(), body.Email)
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(retryAfter.Seconds())+1))
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
 	account, err := model_account.GetAccountByEmail(initializer.DB, body.Email)
 	if err != nil {
-		logger.Log.Error(err.Error())
-		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(err.Error()))
+		CheckPasswordHash(body.Password, dummyPasswordHash)
+		RecordLoginFailure(initializer, body.Email, nil, ctx.IP())
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.LoginFailResponseBody())
 	}
 
 	err = CheckLogin(account, body, constant.ROLE_USER, db, ctx)
-	if err != nil {
-		logger.Log.Error(err.Error())
+	if err == ErrInactiveAccount {
 		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(err.Error()))
 	}
-
-	expiry := time.Now().Add(time.Hour * 24 * 7) // 7 days expiry
-	token, err := utils.GenerateJWT(&account.ID, &account.User.ID, &account.Email, &expiry, &account.Role)
 	if err != nil {
-		logger.Log.Error("Error in generating token for ", account.ID)
+		RecordLoginFailure(initializer, body.Email, account, ctx.IP())
 		return ctx.Status(fiber.StatusUnauthorized).JSON(response.LoginFailResponseBody())
 	}
 
-	modulesArr := make([]map[string]interface{}, 0)
+	ResetLoginFailures(body.Email)
 
-	// var access []model.Permission
-	// db.Where("role_id = ? AND department_id = ?", account.RoleID, account.DeptID).
-	// 	Find(&access)
-
-	// for i := 0; i < len(access); i++ {
-	// 	moduleMap := map[string]interface{}{
-	// 		"module_id":     strconv.FormatUint(uint64(access[i].ModuleID), 10),
-	// 		"module_access": access[i].ModuleAccess,
-	// 		"read_access":   access[i].ReadAccess,
-	// 		"write_access":  access[i].WriteAccess,
-	// 		"delete_access": access[i].DeleteAccess,
-	// 	}
-	// 	modulesArr = append(modulesArr, moduleMap)
-	// }
-
-	data := utils.RedisValue{
-		Token:  token,
-		Module: modulesArr,
-		Status: constant.CREATED,
-	}
-
-	jsonData, err := jsoniter.Marshal(data)
+	challengeToken, enrollmentRequired, expireAt, err := CreateMFAChallenge(db, account, body.Device)
 	if err != nil {
-		logger.Log.Error("Error in encoding JSON: ", err)
+		logger.Log.Errorf("unable to create mfa challenge for ID %s. %s", account.ID, err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(response.LoginFailResponseBody())
 	}
 
-	// Store in Redis
-	err = cache.Redis.RDB.Set(context.Background(), account.ID.String(), jsonData, 0).Err()
+	if challengeToken != "" {
+		logger.Log.Info(constant.SuccessMFAPending, account.ID)
+		return ctx.Status(fiber.StatusOK).JSON(response.MFAChallengeResponseBody(challengeToken, enrollmentRequired, expireAt))
+	}
+
+	session, err := CreateSession(account, GetProfileID(db, account), GetModules(db, account), GetSessionMetadata(ctx, body.Device))
 	if err != nil {
-		logger.Log.Errorf("unable to set ID %s and JSON %s to Redis", account.ID, data)
+		logger.Log.Errorf("unable to create session for ID %s. %s", account.ID, err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(response.LoginFailResponseBody())
 	}
 
 	logger.Log.Info(constant.SuccessLogIn, account.ID)
 	return ctx.Status(fiber.StatusOK).JSON(response.LoginSuccessResponseBody(
 		account.ID,
-		token,
+		session.Token,
+		session.RefreshToken,
+		session.ExpireAt,
 		account.Email,
 	))
 }
@@ -1,12 +1,10 @@
 package handler_auth
 
 import (
-	"certification/cache"
 	"certification/constant"
 	"certification/database"
 	"certification/logger"
 	"certification/response"
-	"context"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -30,8 +28,10 @@ func Logout(ctx *fiber.Ctx, initializer *database.Initializer) error {
 		return ctx.Status(fiber.StatusUnauthorized).JSON(response.LogoutFailResponseBody(errMsg))
 	}
 
-	// Delete the value in Redis
-	err := cache.Redis.RDB.Del(context.Background(), id.String()).Err()
+	sessionID, _ := ctx.Locals("session_id").(string)
+
+	// Delete the session and its refresh tokens in Redis
+	err := RevokeSession(id, sessionID)
 	if err != nil {
 		logger.Log.Errorf("unable to delete value for %s in Redis", id)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(response.LogoutFailResponseBody(err.Error()))
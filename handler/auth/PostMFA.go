@@ -0,0 +1,315 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_mfa "certification/model/mfa"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingMFACode struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type IncomingMFAChallenge struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+type IncomingVerifyMFA struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+type ResponseMFALogin struct {
+	response.LoginSuccessResponse
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// @Summary Enroll MFA
+// @Description Generate a TOTP secret and QR code for the logged in account, confirm it with the first code
+// @Tags Auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=ResponseMFAEnrollment} "Successful enroll"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/mfa/enroll [post]
+func EnrollMFA(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	account, err := model_account.GetAccountByID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	enrollment, err := EnrollMFAForAccount(initializer.DB, account)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(enrollment, "Scan the QR code and confirm with the first code"))
+}
+
+// @Summary Confirm MFA
+// @Description Enable two-factor authentication with the first code from the authenticator app
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingMFACode body IncomingMFACode true "TOTP code"
+// @Success 200 {object} response.DataResponse{data=ResponseRecoveryCodes} "Successful confirm"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/mfa/confirm [post]
+func ConfirmMFA(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingMFACode
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	codes, err := ConfirmMFAForAccount(initializer.DB, accountID, body.Code)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Two-factor authentication enabled for ", accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseRecoveryCodes{RecoveryCodes: codes}, "Two-factor authentication enabled"))
+}
+
+// @Summary Disable MFA
+// @Description Disable two-factor authentication with a TOTP or recovery code
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingMFACode body IncomingMFACode true "TOTP or recovery code"
+// @Param X-OTP-Token header string true "Step-up token from /auth/otp/verify"
+// @Success 200 {object} response.MessageResponse "Successful disable"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 401 {object} response.MessageResponse "Too many failed attempts, sessions ended"
+// @Failure 403 {object} response.MessageResponse "Required by company or step-up token required"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/mfa/disable [post]
+func DisableMFA(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingMFACode
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	account, err := model_account.GetAccountByID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if IsMFARequired(initializer.DB, account) {
+		errMsg := "Two-factor authentication is required by your company"
+		logger.Log.Error(errMsg, accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	if !VerifyMFACode(initializer.DB, accountID, body.Code) {
+		err := FailMFACode(accountID)
+		logger.Log.Error(err, accountID)
+		if err == ErrMFAChallengeLocked {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(err.Error()))
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidMFACode))
+	}
+	ResetMFAFailures(accountID)
+
+	tx := initializer.DB.Begin()
+
+	if err := model_mfa.DeleteMFA(tx, accountID); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Two-factor authentication disabled for ", accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody("Two-factor authentication disabled"))
+}
+
+// @Summary Regenerate Recovery Codes
+// @Description Replace the recovery codes, the previous codes stop working
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingMFACode body IncomingMFACode true "TOTP code"
+// @Param X-OTP-Token header string true "Step-up token from /auth/otp/verify"
+// @Success 200 {object} response.DataResponse{data=ResponseRecoveryCodes} "Successful regenerate"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 401 {object} response.MessageResponse "Too many failed attempts, sessions ended"
+// @Failure 403 {object} response.MessageResponse "Step-up token required"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/mfa/recovery-codes [post]
+func RegenerateRecoveryCodes(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingMFACode
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if !VerifyMFACode(initializer.DB, accountID, body.Code) {
+		err := FailMFACode(accountID)
+		logger.Log.Error(err, accountID)
+		if err == ErrMFAChallengeLocked {
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(err.Error()))
+		}
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidMFACode))
+	}
+	ResetMFAFailures(accountID)
+
+	codes, hashes, err := GenerateRecoveryCodes()
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	tx := initializer.DB.Begin()
+
+	if err := model_mfa.ReplaceRecoveryCodes(tx, accountID, hashes); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseRecoveryCodes{RecoveryCodes: codes}, "Recovery codes regenerated"))
+}
+
+// @Summary Enroll MFA During Login
+// @Description Generate a TOTP secret for a login challenge of an account whose company requires two-factor authentication
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param IncomingMFAChallenge body IncomingMFAChallenge true "Challenge token"
+// @Success 200 {object} response.DataResponse{data=ResponseMFAEnrollment} "Successful enroll"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 401 {object} response.MessageResponse "Unauthorized"
+// @Router /auth/mfa/challenge/enroll [post]
+func EnrollMFAChallenge(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingMFAChallenge
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	challenge, err := GetMFAChallenge(body.ChallengeToken)
+	if err != nil || !challenge.EnrollmentRequired {
+		logger.Log.Error(constant.ErrorInvalidToken)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+
+	account, err := model_account.GetAccountByID(initializer.DB, challenge.AccountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+
+	enrollment, err := EnrollMFAForAccount(initializer.DB, account)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(enrollment, "Scan the QR code and verify with the first code"))
+}
+
+// @Summary Verify MFA
+// @Description Complete a login challenge with a TOTP or recovery code
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param IncomingVerifyMFA body IncomingVerifyMFA true "Challenge token and code"
+// @Success 200 {object} response.DataResponse{data=ResponseMFALogin} "Successful login"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 401 {object} response.MessageResponse "Unauthorized"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/mfa/verify [post]
+func VerifyMFA(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingVerifyMFA
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	challenge, err := GetMFAChallenge(body.ChallengeToken)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	account, err := model_account.GetAccountByID(initializer.DB, challenge.AccountID)
+	if err != nil || account.Status != constant.ACTIVE {
+		logger.Log.Error("Inactive account for challenge ", challenge.AccountID)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.LoginFailResponseBody())
+	}
+
+	// An enrollment challenge is completed by confirming the newly generated secret
+	var recoveryCodes []string
+	isVerified := false
+	if challenge.EnrollmentRequired {
+		recoveryCodes, err = ConfirmMFAForAccount(initializer.DB, account.ID, body.Code)
+		isVerified = err == nil
+	} else {
+		isVerified = VerifyMFACode(initializer.DB, account.ID, body.Code)
+	}
+
+	if !isVerified {
+		err := FailMFAChallenge(body.ChallengeToken)
+		logger.Log.Error(err, account.ID)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := DeleteMFAChallenge(body.ChallengeToken); err != nil {
+		logger.Log.Error(err)
+	}
+
+	session, err := CreateSession(account, GetProfileID(initializer.DB, account), GetModules(initializer.DB, account), GetSessionMetadata(ctx, challenge.Device))
+	if err != nil {
+		logger.Log.Errorf("unable to create session for ID %s. %s", account.ID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.LoginFailResponseBody())
+	}
+
+	logger.Log.Info(constant.SuccessLogIn, account.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseMFALogin{
+		LoginSuccessResponse: response.LoginSuccessResponse{
+			ID:           account.ID,
+			Token:        session.Token,
+			RefreshToken: session.RefreshToken,
+			ExpireAt:     session.ExpireAt,
+			Email:        account.Email,
+		},
+		RecoveryCodes: recoveryCodes,
+	}, constant.SuccessLogIn))
+}
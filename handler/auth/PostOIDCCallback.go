@@ -0,0 +1,99 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	"certification/oidc"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IncomingOIDCCallback struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+// @Summary OIDC Callback
+// @Description Complete a login with the code and state the provider redirected back with.
+// @Description An existing user account with the same verified email is linked to the provider.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param IncomingOIDCCallback body IncomingOIDCCallback true "Authorization code and state"
+// @Success 200 {object} response.MessageDataResponse{data=response.LoginSuccessResponse} "Successful login"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 401 {object} response.MessageResponse "Unauthorized"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/oidc/{provider}/callback [post]
+func OIDCCallback(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	providerName := ctx.Params("provider")
+
+	var body IncomingOIDCCallback
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	state, err := ConsumeOIDCState(body.State, providerName)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	provider, err := oidc.GetProvider(ctx.Context(), providerName)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Unknown or unavailable provider"))
+	}
+
+	claims, err := provider.Exchange(ctx.Context(), body.Code, state.Verifier, state.Nonce)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody("Unable to verify the provider login"))
+	}
+
+	account, err := FindOrCreateOIDCAccount(initializer.DB, providerName, claims)
+	if err == ErrOIDCEmailNotVerified || err == ErrOIDCAccountNotUser {
+		logger.Log.Error(err.Error(), claims.Email)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if account.Status != constant.ACTIVE {
+		logger.Log.Error("Inactive account for email: ", account.Email)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(ErrInactiveAccount.Error()))
+	}
+
+	challengeToken, enrollmentRequired, expireAt, err := CreateMFAChallenge(initializer.DB, account, state.Device)
+	if err != nil {
+		logger.Log.Errorf("unable to create mfa challenge for ID %s. %s", account.ID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.LoginFailResponseBody())
+	}
+
+	if challengeToken != "" {
+		logger.Log.Info(constant.SuccessMFAPending, account.ID)
+		return ctx.Status(fiber.StatusOK).JSON(response.MFAChallengeResponseBody(challengeToken, enrollmentRequired, expireAt))
+	}
+
+	session, err := CreateSession(account, GetProfileID(initializer.DB, account), GetModules(initializer.DB, account), GetSessionMetadata(ctx, state.Device))
+	if err != nil {
+		logger.Log.Errorf("unable to create session for ID %s. %s", account.ID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.LoginFailResponseBody())
+	}
+
+	logger.Log.Info(constant.SuccessLogIn, account.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.LoginSuccessResponseBody(
+		account.ID,
+		session.Token,
+		session.RefreshToken,
+		session.ExpireAt,
+		account.Email,
+	))
+}
@@ -0,0 +1,113 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	"certification/response"
+	"certification/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingRequestOTP struct {
+	Purpose string `json:"purpose" validate:"required,oneof=step_up change_email"`
+}
+
+type IncomingVerifyOTP struct {
+	Purpose string `json:"purpose" validate:"required,oneof=step_up change_email"`
+	Code    string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type ResponseVerifyOTP struct {
+	OTPToken string    `json:"otp_token"`
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+// @Summary Request OTP
+// @Description Email a one-time password to the logged in account for a sensitive action
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingRequestOTP body IncomingRequestOTP true "OTP purpose"
+// @Success 200 {object} response.MessageResponse "OTP sent"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 429 {object} response.MessageResponse "Too many requests"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/otp/request [post]
+func RequestOTP(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingRequestOTP
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	account, err := model_account.GetAccountByID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = SendOTP(initializer, account, body.Purpose)
+	if err == ErrOTPLocked || err == ErrOTPCooldown {
+		logger.Log.Error(err, accountID)
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("OTP sent for ", body.Purpose, " to ", accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody("OTP has been sent to your email"))
+}
+
+// @Summary Verify OTP
+// @Description Verify the emailed one-time password and receive a token for the X-OTP-Token header of the sensitive action
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingVerifyOTP body IncomingVerifyOTP true "OTP purpose and code"
+// @Success 200 {object} response.DataResponse{data=ResponseVerifyOTP} "OTP verified"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 429 {object} response.MessageResponse "Too many failed attempts"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/otp/verify [post]
+func VerifyOTPCode(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingVerifyOTP
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err := VerifyOTP(initializer.DB, accountID, body.Purpose, body.Code)
+	if err == ErrOTPLocked {
+		logger.Log.Error(err, accountID)
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	if err != nil {
+		logger.Log.Error(err, accountID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(ErrOTPInvalid.Error()))
+	}
+
+	token, expireAt, err := CreateStepUpToken(accountID, body.Purpose)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info(constant.SuccessValidate, accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseVerifyOTP{
+		OTPToken: token,
+		ExpireAt: expireAt,
+	}, constant.SuccessValidate))
+}
@@ -0,0 +1,50 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IncomingRefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// Refresh
+// @Summary Refresh token
+// @Description Exchange a refresh token for a new access token and refresh token. Reusing a refresh token revokes the session
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body IncomingRefreshToken true "Refresh token"
+// @Success 200 {object} response.MessageDataResponse{data=response.LoginSuccessResponse} "Successful refresh"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 401 {object} response.MessageResponse "Unauthorized"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/refresh [post]
+func RefreshToken(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingRefreshToken
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	session, value, err := RotateSession(initializer, body.RefreshToken, GetSessionMetadata(ctx, ""))
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info(constant.SuccessRefresh, value.AccountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.RefreshSuccessResponseBody(
+		value.AccountID,
+		session.Token,
+		session.RefreshToken,
+		session.ExpireAt,
+		value.Email,
+	))
+}
@@ -0,0 +1,51 @@
+package handler_auth
+
+import (
+	"certification/config"
+	"certification/constant"
+	"certification/jwtkey"
+	"certification/logger"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IncomingRotateKey struct {
+	Algorithm string `json:"algorithm" validate:"omitempty,oneof=RS256 EdDSA"` // defaults to JWT_ALGORITHM
+}
+
+// @Summary Rotate Signing Key
+// @Description Create a new JWT signing key, the previous key keeps verifying tokens during the grace period
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingRotateKey body IncomingRotateKey false "Algorithm of the new key"
+// @Success 200 {object} response.DataResponse{data=jwtkey.Key} "Successful rotate"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/keys/rotate [post]
+func RotateSigningKey(ctx *fiber.Ctx) error {
+	var body IncomingRotateKey
+	if len(ctx.Body()) > 0 {
+		if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+		}
+	}
+
+	if body.Algorithm == "" {
+		body.Algorithm = config.JWT_ALGORITHM
+	}
+
+	key, err := jwtkey.Rotate(body.Algorithm)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Signing key rotated ", key.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(key, constant.SuccessCreateRecord))
+}
@@ -0,0 +1,143 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_company "certification/model/company"
+	model_token "certification/model/token"
+	"certification/response"
+	"certification/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingSignUpCompany struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+}
+
+// @Summary Sign Up Company
+// @Description Register a company, the account becomes its owner once activated
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param IncomingSignUpCompany body IncomingSignUpCompany true "Sign up data"
+// @Success 200 {object} response.DataResponse{data=ResponseSignUp} "Successful sign up"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/signup/company [post]
+func SignUpCompany(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingSignUpCompany
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// Check if email already exists
+	_, err := model_account.GetAccountByEmail(initializer.DB, body.Email)
+	if err == nil {
+		logger.Log.Error("Email already exists: ", body.Email)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Email already exists"))
+	}
+
+	accountID := uuid.New()
+	companyID := uuid.New()
+
+	hashPassword, err := HashPassword(body.Password)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Unable to hash password"))
+	}
+
+	tokenStr, err := utils.GenerateToken()
+	if err != nil {
+		logger.Log.Error("Error in generating token for ", accountID)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody("Error in generating token"))
+	}
+
+	account := model_account.Account{
+		ID:       accountID,
+		Email:    body.Email,
+		Password: hashPassword,
+		Role:     constant.ROLE_COMPANY,
+		Status:   constant.PENDING,
+	}
+
+	company := model_company.Company{
+		ID:        companyID,
+		AccountID: &accountID,
+		Name:      body.Name,
+	}
+
+	member := model_company.CompanyMember{
+		CompanyID: companyID,
+		AccountID: &accountID,
+		Email:     body.Email,
+		Role:      constant.PROJECT_OWNER,
+		Status:    constant.ACTIVE,
+	}
+
+	token := model_token.Token{
+		AccountID: accountID,
+		Token:     tokenStr,
+		ExpireAt:  time.Now().Add(time.Hour * 24 * 7), // 7 days expiry
+		Type:      constant.VALIDATION_TOKEN,
+		Status:    constant.PENDING,
+	}
+
+	tx := initializer.DB.Begin()
+
+	err = tx.Create(&account).Error
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = tx.Create(&company).Error
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = tx.Create(&member).Error
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = tx.Create(&token).Error
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// Commit the changes so far
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// Send email with token
+	err = SendActivationEmail(initializer, body.Email, body.Name, tokenStr)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	responeSignUp := ResponseSignUp{
+		AccountID: accountID,
+	}
+
+	logger.Log.Info(constant.SuccessSignUp, accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(responeSignUp, "Sign up successful"))
+}
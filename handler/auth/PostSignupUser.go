@@ -4,18 +4,13 @@ import (
 	"certification/constant"
 	"certification/database"
 	"certification/logger"
-	"certification/mailer"
 	model_account "certification/model/account"
 	model_token "certification/model/token"
 	model_user "certification/model/user"
 	"certification/response"
-	"certification/template"
 	"certification/utils"
-	"context"
-	"mime/multipart"
 	"time"
 
-	"github.com/Boostport/mjml-go"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -121,15 +116,7 @@ func SignUpUser(ctx *fiber.Ctx, initializer *database.Initializer) error {
 	}
 
 	// Send email with token
-	html, err := mjml.ToHTML(context.Background(), template.TemplateEmailInvitation(initializer, body.FirstName+body.LastName, tokenStr), mjml.WithMinify(true))
-	if err != nil {
-		logger.Log.Error(err)
-		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Unable to convert MJML to HTML"))
-	}
-
-	subject := "Welcome to CertFirst!"
-	var emptyFile *multipart.FileHeader
-	err = mailer.SendEmail(html, subject, []string{body.Email}, emptyFile)
+	err = SendActivationEmail(initializer, body.Email, body.FirstName+body.LastName, tokenStr)
 	if err != nil {
 		logger.Log.Error(err)
 		return ctx.Status(fiber.StatusServiceUnavailable).JSON(response.ErrorResponseBody(err.Error()))
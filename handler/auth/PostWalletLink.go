@@ -0,0 +1,68 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_wallet "certification/model/wallet"
+	"certification/response"
+	"certification/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingWalletLink struct {
+	Nonce      string `json:"nonce" validate:"required"`
+	Signature  string `json:"signature" validate:"required"`
+	AllowLogin bool   `json:"allow_login"`
+}
+
+// @Summary Link Wallet
+// @Description Link the wallet that signed the nonce message to the logged in account
+// @Tags Auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingWalletLink body IncomingWalletLink true "Nonce and signature"
+// @Success 200 {object} response.DataResponse{data=model_wallet.Wallet} "Successful link"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/wallets [post]
+func LinkWallet(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingWalletLink
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	value, err := VerifyWalletSignature(body.Nonce, body.Signature)
+	if err != nil {
+		logger.Log.Error(err, accountID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if _, err := model_wallet.GetWalletByAddress(initializer.DB, value.Address); err == nil {
+		logger.Log.Error("Wallet already linked: ", value.Address)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Wallet already linked"))
+	}
+
+	wallet := model_wallet.Wallet{
+		AccountID:  accountID,
+		Address:    value.Address,
+		ChainID:    value.ChainID,
+		AllowLogin: body.AllowLogin,
+		VerifiedAt: time.Now(),
+	}
+
+	if err := initializer.DB.Create(&wallet).Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Wallet linked ", wallet.Address, " to ", accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(wallet, constant.SuccessCreateRecord))
+}
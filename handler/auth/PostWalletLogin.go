@@ -0,0 +1,88 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_wallet "certification/model/wallet"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IncomingWalletLogin struct {
+	Nonce     string `json:"nonce" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+	Device    string `json:"device" validate:"-"`
+}
+
+// @Summary Wallet Login
+// @Description Log in with a linked wallet that allows login by signing the nonce message
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param IncomingWalletLogin body IncomingWalletLogin true "Nonce and signature"
+// @Success 200 {object} response.MessageDataResponse{data=response.LoginSuccessResponse} "Successful login"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 401 {object} response.MessageResponse "Unauthorized"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/login/wallet [post]
+func LoginWallet(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingWalletLogin
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	value, err := VerifyWalletSignature(body.Nonce, body.Signature)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// An unknown wallet and a wallet without login get the same answer
+	wallet, err := model_wallet.GetWalletByAddress(initializer.DB, value.Address)
+	if err != nil || !wallet.AllowLogin {
+		logger.Log.Error("Wallet cannot log in: ", value.Address)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(ErrWalletSignature.Error()))
+	}
+
+	account, err := model_account.GetAccountByID(initializer.DB, wallet.AccountID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(ErrWalletSignature.Error()))
+	}
+
+	if account.Status != constant.ACTIVE {
+		logger.Log.Error("Inactive account for email: ", account.Email)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(ErrInactiveAccount.Error()))
+	}
+
+	challengeToken, enrollmentRequired, expireAt, err := CreateMFAChallenge(initializer.DB, account, body.Device)
+	if err != nil {
+		logger.Log.Errorf("unable to create mfa challenge for ID %s. %s", account.ID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.LoginFailResponseBody())
+	}
+
+	if challengeToken != "" {
+		logger.Log.Info(constant.SuccessMFAPending, account.ID)
+		return ctx.Status(fiber.StatusOK).JSON(response.MFAChallengeResponseBody(challengeToken, enrollmentRequired, expireAt))
+	}
+
+	session, err := CreateSession(account, GetProfileID(initializer.DB, account), GetModules(initializer.DB, account), GetSessionMetadata(ctx, body.Device))
+	if err != nil {
+		logger.Log.Errorf("unable to create session for ID %s. %s", account.ID, err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.LoginFailResponseBody())
+	}
+
+	logger.Log.Info(constant.SuccessLogIn, account.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.LoginSuccessResponseBody(
+		account.ID,
+		session.Token,
+		session.RefreshToken,
+		session.ExpireAt,
+		account.Email,
+	))
+}
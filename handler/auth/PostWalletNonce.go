@@ -0,0 +1,59 @@
+package handler_auth
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	"certification/response"
+	"certification/utils"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IncomingWalletNonce struct {
+	Address string `json:"address" validate:"required"`
+	ChainID int64  `json:"chain_id" validate:"required,min=1"`
+}
+
+type ResponseWalletNonce struct {
+	Nonce    string    `json:"nonce"`
+	Message  string    `json:"message"` // sign exactly this message with personal_sign
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+// @Summary Request Wallet Nonce
+// @Description Get a Sign-In with Ethereum message for the address, used to link a wallet or log in with it
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param IncomingWalletNonce body IncomingWalletNonce true "Wallet address and chain ID"
+// @Success 200 {object} response.DataResponse{data=ResponseWalletNonce} "Successful nonce"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /auth/wallet/nonce [post]
+func RequestWalletNonce(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingWalletNonce
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if !common.IsHexAddress(body.Address) {
+		logger.Log.Error("Invalid wallet address: ", body.Address)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidValue + body.Address))
+	}
+
+	nonce, message, expireAt, err := CreateWalletMessage(common.HexToAddress(body.Address), body.ChainID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody("Error in generating nonce"))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseWalletNonce{
+		Nonce:    nonce,
+		Message:  message,
+		ExpireAt: expireAt,
+	}, "Successfully created nonce"))
+}
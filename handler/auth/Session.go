@@ -0,0 +1,300 @@
+package handler_auth
+
+import (
+	"certification/cache"
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	"certification/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+type Session struct {
+	ID           string
+	Token        string
+	RefreshToken string
+	ExpireAt     time.Time
+}
+
+// Device information recorded with a session
+type SessionMetadata struct {
+	Device    string
+	IPAddress string
+	UserAgent string
+}
+
+// Stored in Redis under the hash of the refresh token
+type RefreshTokenValue struct {
+	AccountID uuid.UUID                `json:"account_id"`
+	ProfileID uuid.UUID                `json:"profile_id"`
+	Email     string                   `json:"email"`
+	Role      constant.AccountRoleType `json:"role"`
+	SessionID string                   `json:"session_id"`
+}
+
+var ErrRefreshTokenReused = errors.New("Refresh token has already been used")
+
+func refreshTokenKey(hash string) string {
+	return fmt.Sprintf("%s:%s", constant.REDIS_REFRESH_TOKEN, hash)
+}
+
+func refreshFamilyKey(sessionID string) string {
+	return fmt.Sprintf("%s:%s", constant.REDIS_REFRESH_FAMILY, sessionID)
+}
+
+func GetSessionMetadata(ctx *fiber.Ctx, device string) SessionMetadata {
+	return SessionMetadata{
+		Device:    device,
+		IPAddress: ctx.IP(),
+		UserAgent: ctx.Get(fiber.HeaderUserAgent),
+	}
+}
+
+// Create a new session with its own refresh token family and store it in Redis
+func CreateSession(account *model_account.Account, profileID uuid.UUID, modules []map[string]interface{}, metadata SessionMetadata) (*Session, error) {
+	value := RefreshTokenValue{
+		AccountID: account.ID,
+		ProfileID: profileID,
+		Email:     account.Email,
+		Role:      account.Role,
+		SessionID: uuid.New().String(),
+	}
+
+	session, err := issueTokens(value)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	data := utils.RedisValue{
+		Token:      session.Token,
+		SessionID:  value.SessionID,
+		Module:     modules,
+		Status:     constant.CREATED,
+		Device:     metadata.Device,
+		IPAddress:  metadata.IPAddress,
+		UserAgent:  metadata.UserAgent,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+
+	if err := setSession(account.ID, data); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	indexKey := utils.AccountSessionsKey(account.ID.String())
+
+	pipe := cache.Redis.RDB.TxPipeline()
+	pipe.SAdd(ctx, indexKey, value.SessionID)
+	pipe.Expire(ctx, indexKey, constant.REFRESH_TOKEN_EXPIRY)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Exchange a refresh token for a new access and refresh token of the same session.
+// A refresh token can only be used once, using it again revokes the whole session.
+func RotateSession(initializer *database.Initializer, refreshToken string, metadata SessionMetadata) (*Session, *RefreshTokenValue, error) {
+	ctx := context.Background()
+	key := refreshTokenKey(utils.HashToken(refreshToken))
+
+	results, err := cache.Redis.RDB.HGet(ctx, key, "data").Result()
+	if err != nil {
+		return nil, nil, errors.New(constant.ErrorInvalidToken)
+	}
+
+	var value RefreshTokenValue
+	if err := json.Unmarshal([]byte(results), &value); err != nil {
+		return nil, nil, errors.New(constant.ErrorInvalidToken)
+	}
+
+	isFirstUse, err := cache.Redis.RDB.HSetNX(ctx, key, "used_at", time.Now().Unix()).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !isFirstUse {
+		logger.Log.Error("Refresh token reuse detected for session ", value.SessionID)
+		if err := RevokeSession(value.AccountID, value.SessionID); err != nil {
+			logger.Log.Error(err)
+		}
+		return nil, nil, ErrRefreshTokenReused
+	}
+
+	data, err := GetSession(value.AccountID, value.SessionID)
+	if err != nil || data.Status == constant.UPDATED {
+		RevokeSession(value.AccountID, value.SessionID)
+		return nil, nil, errors.New(constant.ErrorInvalidToken)
+	}
+
+	account, err := model_account.GetAccountByID(initializer.DB, value.AccountID)
+	if err != nil || account.Status != constant.ACTIVE {
+		RevokeSession(value.AccountID, value.SessionID)
+		return nil, nil, errors.New(constant.ErrorInvalidToken)
+	}
+
+	session, err := issueTokens(value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data.Token = session.Token
+	data.IPAddress = metadata.IPAddress
+	data.UserAgent = metadata.UserAgent
+	data.LastUsedAt = time.Now()
+	if err := setSession(value.AccountID, *data); err != nil {
+		return nil, nil, err
+	}
+
+	return session, &value, nil
+}
+
+// Delete the session and every refresh token issued for it
+func RevokeSession(accountID uuid.UUID, sessionID string) error {
+	ctx := context.Background()
+	familyKey := refreshFamilyKey(sessionID)
+
+	hashes, err := cache.Redis.RDB.SMembers(ctx, familyKey).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{familyKey, utils.SessionKey(accountID.String(), sessionID)}
+	for _, hash := range hashes {
+		keys = append(keys, refreshTokenKey(hash))
+	}
+
+	pipe := cache.Redis.RDB.TxPipeline()
+	pipe.Del(ctx, keys...)
+	pipe.SRem(ctx, utils.AccountSessionsKey(accountID.String()), sessionID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Remove every session of the account except the one given, pass an empty ID to remove all
+func RevokeOtherSessions(accountID uuid.UUID, currentSessionID string) error {
+	sessionIDs, err := cache.Redis.RDB.SMembers(context.Background(), utils.AccountSessionsKey(accountID.String())).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if sessionID == currentSessionID {
+			continue
+		}
+		if err := RevokeSession(accountID, sessionID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Remove every session of the account from Redis
+func RevokeSessions(accountID uuid.UUID) error {
+	return RevokeOtherSessions(accountID, "")
+}
+
+// List the active sessions of the account, most recently used first
+func ListSessions(accountID uuid.UUID) ([]utils.RedisValue, error) {
+	ctx := context.Background()
+	indexKey := utils.AccountSessionsKey(accountID.String())
+
+	sessionIDs, err := cache.Redis.RDB.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]utils.RedisValue, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		data, err := GetSession(accountID, sessionID)
+		if err != nil {
+			// The session expired, drop it from the index
+			cache.Redis.RDB.SRem(ctx, indexKey, sessionID)
+			continue
+		}
+		sessions = append(sessions, *data)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	return sessions, nil
+}
+
+func GetSession(accountID uuid.UUID, sessionID string) (*utils.RedisValue, error) {
+	results, err := cache.Redis.RDB.Get(context.Background(), utils.SessionKey(accountID.String(), sessionID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var data utils.RedisValue
+	if err := json.Unmarshal([]byte(results), &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func issueTokens(value RefreshTokenValue) (*Session, error) {
+	ctx := context.Background()
+
+	expiry := time.Now().Add(constant.ACCESS_TOKEN_EXPIRY)
+	token, err := utils.GenerateJWT(&value.AccountID, &value.ProfileID, &value.Email, &expiry, &value.Role, &value.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, hash, err := utils.GenerateHashedToken()
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := jsoniter.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	key := refreshTokenKey(hash)
+	familyKey := refreshFamilyKey(value.SessionID)
+
+	pipe := cache.Redis.RDB.TxPipeline()
+	pipe.HSet(ctx, key, "data", jsonData)
+	pipe.Expire(ctx, key, constant.REFRESH_TOKEN_EXPIRY)
+	pipe.SAdd(ctx, familyKey, hash)
+	pipe.Expire(ctx, familyKey, constant.REFRESH_TOKEN_EXPIRY)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		ID:           value.SessionID,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpireAt:     expiry,
+	}, nil
+}
+
+func setSession(accountID uuid.UUID, data utils.RedisValue) error {
+	jsonData, err := jsoniter.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	// The session lives as long as its refresh tokens
+	return cache.Redis.RDB.Set(context.Background(), utils.SessionKey(accountID.String(), data.SessionID), jsonData, constant.REFRESH_TOKEN_EXPIRY).Err()
+}
@@ -0,0 +1,92 @@
+package handler_auth
+
+import (
+	"certification/cache"
+	"certification/config"
+	"certification/constant"
+	"certification/utils"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+// Stored in Redis under the nonce until the message is signed
+type WalletNonceValue struct {
+	Address string `json:"address"`
+	ChainID int64  `json:"chain_id"`
+	Message string `json:"message"`
+}
+
+var ErrWalletSignature = errors.New("Invalid or expired wallet signature")
+
+func walletNonceKey(nonce string) string {
+	return fmt.Sprintf("%s:%s", constant.REDIS_WALLET_NONCE, nonce)
+}
+
+// Build the EIP-4361 message the wallet signs and save it with a single-use nonce
+func CreateWalletMessage(address common.Address, chainID int64) (string, string, time.Time, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", time.Time{}, err
+	}
+	nonce := hex.EncodeToString(raw)
+
+	domain := config.API_URL
+	if u, err := url.Parse(config.API_URL); err == nil && u.Host != "" {
+		domain = u.Host
+	}
+
+	issuedAt := time.Now().UTC()
+	expireAt := issuedAt.Add(constant.WALLET_NONCE_EXPIRY)
+
+	message := fmt.Sprintf("%s wants you to sign in with your Ethereum account:\n%s\n\n%s\n\nURI: %s\nVersion: 1\nChain ID: %d\nNonce: %s\nIssued At: %s\nExpiration Time: %s",
+		domain,
+		address.Hex(),
+		constant.WALLET_STATEMENT,
+		config.API_URL,
+		chainID,
+		nonce,
+		issuedAt.Format(time.RFC3339),
+		expireAt.Format(time.RFC3339),
+	)
+
+	jsonData, err := jsoniter.Marshal(WalletNonceValue{
+		Address: address.Hex(),
+		ChainID: chainID,
+		Message: message,
+	})
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	err = cache.Redis.RDB.Set(context.Background(), walletNonceKey(nonce), jsonData, constant.WALLET_NONCE_EXPIRY).Err()
+	return nonce, message, expireAt, err
+}
+
+// Consume the nonce and check the signature of its message was made by the requested address
+func VerifyWalletSignature(nonce string, signature string) (*WalletNonceValue, error) {
+	results, err := cache.Redis.RDB.GetDel(context.Background(), walletNonceKey(nonce)).Result()
+	if err != nil {
+		return nil, ErrWalletSignature
+	}
+
+	var value WalletNonceValue
+	if err := json.Unmarshal([]byte(results), &value); err != nil {
+		return nil, ErrWalletSignature
+	}
+
+	address, err := utils.RecoverPersonalSignAddress(value.Message, signature)
+	if err != nil || address.Hex() != value.Address {
+		return nil, ErrWalletSignature
+	}
+
+	return &value, nil
+}
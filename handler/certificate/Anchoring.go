@@ -0,0 +1,322 @@
+package handler_certificate
+
+import (
+	"certification/anchor"
+	"certification/cache"
+	"certification/config"
+	"certification/constant"
+	"certification/logger"
+	model_anchor "certification/model/anchor"
+	model_certificate "certification/model/certificate"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Anchor the certificates on-chain on the ANCHOR_SCHEDULE when an RPC URL is configured,
+// a Redis lock keeps two instances from running a round at the same time
+func StartAnchoring(db *gorm.DB) {
+	anchorer, err := anchor.Dial(context.Background())
+	if err == anchor.ErrDisabled {
+		logger.Log.Info(err)
+		return
+	}
+	if err != nil {
+		logger.Log.Error("Anchoring not started: ", err)
+		return
+	}
+
+	_, err = config.SCHEDULER.AddFunc(constant.ANCHOR_SCHEDULE, func() {
+		locked, err := cache.Redis.Lock(constant.REDIS_ANCHOR, constant.ANCHOR_LOCK_EXPIRY)
+		if err != nil || !locked {
+			return
+		}
+		defer cache.Redis.Unlock(constant.REDIS_ANCHOR)
+
+		ctx, cancel := context.WithTimeout(context.Background(), constant.ANCHOR_LOCK_EXPIRY)
+		defer cancel()
+		RunAnchoring(ctx, db, anchorer)
+	})
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+	config.SCHEDULER.Start()
+
+	logger.Log.Info("Anchoring to registry ", anchorer.Registry.Address.Hex(), " on chain ", anchorer.ChainID())
+}
+
+// One anchoring round: confirm the submitted batches, send the batches left pending by a restart,
+// then batch the certificates and revocation events waiting to be anchored and send the root of their Merkle tree
+func RunAnchoring(ctx context.Context, db *gorm.DB, anchorer *anchor.Anchorer) {
+	submitted, err := model_anchor.GetBatchesByStatus(db, constant.SUBMITTED)
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+	for i := range submitted {
+		confirmBatch(ctx, db, anchorer, &submitted[i])
+	}
+
+	pending, err := model_anchor.GetBatchesByStatus(db, constant.PENDING)
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+	for i := range pending {
+		submitBatch(ctx, db, anchorer, &pending[i])
+	}
+
+	batch, err := createBatch(db, anchorer)
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+	if batch != nil {
+		submitBatch(ctx, db, anchorer, batch)
+	}
+}
+
+// Batch the oldest certificates and revocation events waiting to be anchored, nil when there are none.
+// The leaves of the events follow those of the certificates in the tree.
+func createBatch(db *gorm.DB, anchorer *anchor.Anchorer) (*model_anchor.AnchorBatch, error) {
+	tx := db.Begin()
+
+	certificates, err := model_certificate.GetAnchorableCertificates(tx, constant.ANCHOR_BATCH_SIZE)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	revocations, err := model_certificate.GetAnchorableRevocations(tx, constant.ANCHOR_BATCH_SIZE-len(certificates))
+	if err != nil || len(certificates)+len(revocations) == 0 {
+		tx.Rollback()
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(certificates))
+	leaves := make([]common.Hash, 0, len(certificates)+len(revocations))
+	for i, certificate := range certificates {
+		document, err := certificate.CanonicalJSON()
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		ids[i] = certificate.ID
+		leaves = append(leaves, anchor.HashLeaf(document))
+	}
+	for _, revocation := range revocations {
+		document, err := revocation.CanonicalJSON()
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		leaves = append(leaves, anchor.HashLeaf(document))
+	}
+
+	tree := anchor.NewTree(leaves)
+	proofs := make([]string, len(leaves))
+	for i := range leaves {
+		proof := tree.Proof(i)
+		hashes := make([]string, len(proof))
+		for j, hash := range proof {
+			hashes[j] = hash.Hex()
+		}
+		encoded, err := json.Marshal(hashes)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		proofs[i] = string(encoded)
+	}
+
+	anchors := make([]model_anchor.CertificateAnchor, len(certificates))
+	for i := range certificates {
+		anchors[i] = model_anchor.CertificateAnchor{
+			CertificateID: ids[i],
+			Leaf:          leaves[i].Hex(),
+			Proof:         proofs[i],
+		}
+	}
+	for i := range revocations {
+		revocations[i].Leaf = leaves[len(certificates)+i].Hex()
+		revocations[i].Proof = proofs[len(certificates)+i]
+	}
+
+	batch := model_anchor.AnchorBatch{
+		Root:     tree.Root().Hex(),
+		Size:     len(leaves),
+		Status:   constant.PENDING,
+		ChainID:  anchorer.ChainID().String(),
+		Registry: anchorer.Registry.Address.Hex(),
+	}
+	if err := model_anchor.CreateBatch(tx, &batch, anchors); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := model_certificate.UpdateStatusByIDs(tx, ids, constant.OFFCHAIN, constant.SUBMITTED); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := model_certificate.SetRevocationsBatch(tx, batch.ID, revocations); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Anchor batch ", batch.ID, " created with ", len(certificates), " certificates and ", len(revocations), " revocations, root ", batch.Root)
+	return &batch, nil
+}
+
+// Send the root of the batch to the registry. A root already anchored, e.g. by a transaction
+// sent before a restart or by a batch of the same certificates which timed out, is not sent again.
+// The batch stays PENDING when the node cannot be reached and is sent in the next round, only a
+// transaction the registry rejects fails it and uses up an attempt of its certificates.
+func submitBatch(ctx context.Context, db *gorm.DB, anchorer *anchor.Anchorer, batch *model_anchor.AnchorBatch) {
+	root := common.HexToHash(batch.Root)
+
+	receipt, err := anchorer.Find(ctx, root)
+	if err != nil {
+		logger.Log.Error("Anchor batch ", batch.ID, ": ", err)
+		return
+	}
+
+	var txHash common.Hash
+	if receipt != nil {
+		txHash = receipt.TxHash
+	} else if txHash, err = anchorer.Submit(ctx, root); err != nil {
+		logger.Log.Error("Anchor batch ", batch.ID, ": ", err)
+		if anchor.IsReverted(err) {
+			failBatch(db, batch, err.Error())
+		}
+		return
+	}
+
+	if err := model_anchor.SetBatchSubmitted(db, batch, txHash.Hex()); err != nil {
+		logger.Log.Error(err)
+		return
+	}
+	logger.Log.Info("Anchor batch ", batch.ID, " submitted in ", batch.TxHash)
+}
+
+// Check the transaction of the batch and mark its certificates and events ONCHAIN once it is confirmed
+func confirmBatch(ctx context.Context, db *gorm.DB, anchorer *anchor.Anchorer, batch *model_anchor.AnchorBatch) {
+	receipt, err := anchorer.Confirm(ctx, common.HexToHash(batch.TxHash))
+	if err != nil {
+		logger.Log.Error("Anchor batch ", batch.ID, ": ", err)
+		return // checked again in the next round
+	}
+
+	if receipt == nil {
+		if batch.SubmittedAt == nil || time.Since(*batch.SubmittedAt) < constant.ANCHOR_SUBMIT_TIMEOUT {
+			return
+		}
+		// The transaction may have been replaced, the root is what matters
+		if receipt, err = anchorer.Find(ctx, common.HexToHash(batch.Root)); err != nil || receipt == nil {
+			failBatch(db, batch, "transaction was not mined in time")
+			return
+		}
+	}
+
+	if !receipt.Success {
+		failBatch(db, batch, "transaction was reverted")
+		return
+	}
+
+	retry, exhausted, err := model_anchor.GetBatchCertificateIDs(db, batch.ID, constant.ANCHOR_MAX_ATTEMPTS)
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+	ids := append(retry, exhausted...)
+
+	tx := db.Begin()
+
+	if err := model_anchor.SetBatchAnchored(tx, batch, receipt.TxHash.Hex(), receipt.BlockNumber); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return
+	}
+	if err := model_certificate.UpdateStatusByIDs(tx, ids, constant.SUBMITTED, constant.ONCHAIN); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return
+	}
+	if err := model_certificate.UpdateRevocationStatusByBatch(tx, batch.ID, constant.SUBMITTED, constant.ONCHAIN); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return
+	}
+
+	invalidateVerifications(db, batch.ID, ids)
+	logger.Log.Info("Anchor batch ", batch.ID, " anchored in block ", batch.BlockNumber)
+}
+
+// Mark the batch FAILED, its certificates and events are batched again unless they were part of too many batches
+func failBatch(db *gorm.DB, batch *model_anchor.AnchorBatch, reason string) {
+	retry, exhausted, err := model_anchor.GetBatchCertificateIDs(db, batch.ID, constant.ANCHOR_MAX_ATTEMPTS)
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+
+	tx := db.Begin()
+
+	if err := model_anchor.SetBatchFailed(tx, batch, reason); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return
+	}
+	if err := model_certificate.UpdateStatusByIDs(tx, retry, constant.SUBMITTED, constant.OFFCHAIN); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return
+	}
+	if err := model_certificate.UpdateStatusByIDs(tx, exhausted, constant.SUBMITTED, constant.FAILED); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return
+	}
+	if err := model_certificate.FailRevocationsByBatch(tx, batch.ID, constant.ANCHOR_MAX_ATTEMPTS); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return
+	}
+
+	invalidateVerifications(db, batch.ID, append(retry, exhausted...))
+	logger.Log.Error("Anchor batch ", batch.ID, " failed: ", reason, ", ", len(exhausted), " certificates will not be retried")
+}
+
+// Drop the cached verifications of the certificates of the batch and of its revocation events
+func invalidateVerifications(db *gorm.DB, batchID uuid.UUID, ids []uuid.UUID) {
+	revoked, err := model_certificate.GetRevocationCertificateIDs(db, batchID)
+	if err != nil {
+		logger.Log.Error(err)
+	}
+	ids = append(ids, revoked...)
+
+	codes, err := model_certificate.GetCodes(db, ids)
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+	for _, code := range codes {
+		InvalidateVerification(code)
+	}
+}
@@ -0,0 +1,337 @@
+//go:build simulated
+
+// Runs against an in-process simulated chain, with: go test -tags simulated ./...
+
+package handler_certificate
+
+import (
+	"certification/anchor"
+	"certification/cache"
+	"certification/constant"
+	"certification/logger"
+	model_anchor "certification/model/anchor"
+	model_certificate "certification/model/certificate"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gorm_logger "gorm.io/gorm/logger"
+)
+
+// Random UUID in the same text form as the IDs written by the application
+const uuidDefault = `(lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-' || hex(randomblob(2)) || '-' ||
+	hex(randomblob(2)) || '-' || hex(randomblob(6))))`
+
+// In-memory database with the tables used by the anchoring, Redis is unreachable
+// so dropping the cached verifications only logs an error
+func newAnchorTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	logger.Log = zap.NewNop().Sugar()
+	cache.Redis.RDB = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gorm_logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	for _, stmt := range []string{
+		`CREATE TABLE certificates (id TEXT PRIMARY KEY, created_at DATETIME, updated_at DATETIME,
+			code TEXT UNIQUE, company_id TEXT, template_id TEXT, version_id TEXT, issued_by TEXT,
+			recipient_email TEXT, recipient_salt TEXT, recipient_user_id TEXT, claimed_at DATETIME, private BOOLEAN,
+			status TEXT, revoked_at DATETIME, revoke_reason TEXT, reissue_of_id TEXT, reissued_as_id TEXT,
+			status_list_id TEXT, status_list_index INTEGER)`,
+		`CREATE TABLE certificate_values (id INTEGER PRIMARY KEY AUTOINCREMENT, certificate_id TEXT,
+			key TEXT, value TEXT, UNIQUE (certificate_id, key))`,
+		`CREATE TABLE certificate_revocations (id TEXT PRIMARY KEY DEFAULT ` + uuidDefault + `,
+			created_at DATETIME, certificate_id TEXT, action TEXT, reason TEXT, actor_id TEXT, status TEXT,
+			batch_id TEXT, leaf TEXT, proof TEXT, attempts INTEGER)`,
+		`CREATE TABLE anchor_batches (id TEXT PRIMARY KEY DEFAULT ` + uuidDefault + `,
+			created_at DATETIME, updated_at DATETIME, root TEXT, size INTEGER, status TEXT, chain_id TEXT,
+			registry TEXT, tx_hash TEXT, block_number INTEGER, error TEXT, submitted_at DATETIME, anchored_at DATETIME)`,
+		`CREATE TABLE certificate_anchors (id INTEGER PRIMARY KEY AUTOINCREMENT, certificate_id TEXT UNIQUE,
+			batch_id TEXT, leaf TEXT, proof TEXT, attempts INTEGER)`,
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// Simulated chain with a registry and the anchorer of its owner, blocks are only mined on Commit
+func newTestAnchorer(t *testing.T) (*simulated.Backend, *anchor.Anchorer) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))},
+	}, simulated.WithBlockGasLimit(30_000_000))
+	t.Cleanup(func() { backend.Close() })
+
+	chainID, err := backend.Client().ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, _, err := anchor.DeployRegistry(opts, backend.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+
+	anchorer, err := anchor.New(context.Background(), backend.Client(), registry.Address, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backend, anchorer
+}
+
+func createTestCertificates(t *testing.T, db *gorm.DB, n int) []uuid.UUID {
+	t.Helper()
+
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		certificate := model_certificate.NewCertificate(uuid.New(), uuid.New(), uuid.New(), uuid.New(),
+			fmt.Sprintf("recipient%d@example.com", i), nil, map[string]string{"name": fmt.Sprintf("Recipient %d", i)})
+		certificate.ID = uuid.New()
+		certificate.Code = certificate.ID.String()
+		if err := db.Create(&certificate).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = certificate.ID
+	}
+	return ids
+}
+
+func expectCertificateStatus(t *testing.T, db *gorm.DB, status constant.Status, ids ...uuid.UUID) {
+	t.Helper()
+
+	for _, id := range ids {
+		var certificate model_certificate.Certificate
+		if err := db.Where("id = ?", id).First(&certificate).Error; err != nil {
+			t.Fatal(err)
+		}
+		if certificate.Status != status {
+			t.Fatalf("certificate %s is %s, expected %s", id, certificate.Status, status)
+		}
+	}
+}
+
+func batchesByStatus(t *testing.T, db *gorm.DB, status constant.Status) []model_anchor.AnchorBatch {
+	t.Helper()
+
+	batches, err := model_anchor.GetBatchesByStatus(db, status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return batches
+}
+
+// The leaf and proof stored for the certificate lead to the root of its batch, which is anchored on-chain
+func expectAnchored(t *testing.T, db *gorm.DB, anchorer *anchor.Anchorer, id uuid.UUID) {
+	t.Helper()
+
+	certificateAnchor, batch, err := model_anchor.GetCertificateAnchor(db, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var certificate model_certificate.Certificate
+	if err := db.Preload("Values").Where("id = ?", id).First(&certificate).Error; err != nil {
+		t.Fatal(err)
+	}
+	document, err := certificate.CanonicalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf := anchor.HashLeaf(document)
+	if leaf.Hex() != certificateAnchor.Leaf {
+		t.Fatalf("leaf of certificate %s does not match its document", id)
+	}
+
+	var hashes []string
+	if err := json.Unmarshal([]byte(certificateAnchor.Proof), &hashes); err != nil {
+		t.Fatal(err)
+	}
+	proof := make([]common.Hash, len(hashes))
+	for i, hash := range hashes {
+		proof[i] = common.HexToHash(hash)
+	}
+	root := common.HexToHash(batch.Root)
+	if !anchor.VerifyProof(leaf, proof, root) {
+		t.Fatalf("proof of certificate %s does not lead to the root of its batch", id)
+	}
+
+	receipt, err := anchorer.Find(context.Background(), root)
+	if err != nil || receipt == nil {
+		t.Fatalf("root of certificate %s is not anchored: %v", id, err)
+	}
+	if receipt.TxHash.Hex() != batch.TxHash || receipt.BlockNumber != batch.BlockNumber {
+		t.Fatalf("batch %+v does not match the anchor %+v", batch, receipt)
+	}
+}
+
+func TestRunAnchoring(t *testing.T) {
+	db := newAnchorTestDB(t)
+	backend, anchorer := newTestAnchorer(t)
+	ctx := context.Background()
+
+	// Batch and submit
+	first := createTestCertificates(t, db, 3)
+	RunAnchoring(ctx, db, anchorer)
+
+	submitted := batchesByStatus(t, db, constant.SUBMITTED)
+	if len(submitted) != 1 || submitted[0].Size != 3 || submitted[0].TxHash == "" {
+		t.Fatalf("unexpected submitted batches %+v", submitted)
+	}
+	expectCertificateStatus(t, db, constant.SUBMITTED, first...)
+
+	// Not mined yet, nothing changes
+	RunAnchoring(ctx, db, anchorer)
+	if batches := batchesByStatus(t, db, constant.SUBMITTED); len(batches) != 1 || batches[0].ID != submitted[0].ID {
+		t.Fatalf("unexpected submitted batches %+v", batches)
+	}
+
+	// Confirm
+	backend.Commit()
+	RunAnchoring(ctx, db, anchorer)
+
+	onchain := batchesByStatus(t, db, constant.ONCHAIN)
+	if len(onchain) != 1 || onchain[0].ID != submitted[0].ID || onchain[0].BlockNumber == 0 || onchain[0].AnchoredAt == nil {
+		t.Fatalf("unexpected anchored batches %+v", onchain)
+	}
+	expectCertificateStatus(t, db, constant.ONCHAIN, first...)
+	for _, id := range first {
+		expectAnchored(t, db, anchorer, id)
+	}
+}
+
+func TestRunAnchoringRetriesFailedBatch(t *testing.T) {
+	db := newAnchorTestDB(t)
+	backend, anchorer := newTestAnchorer(t)
+	ctx := context.Background()
+
+	ids := createTestCertificates(t, db, 2)
+	retried, exhausted := ids[0], ids[1]
+
+	RunAnchoring(ctx, db, anchorer)
+	submitted := batchesByStatus(t, db, constant.SUBMITTED)
+	if len(submitted) != 1 {
+		t.Fatalf("unexpected submitted batches %+v", submitted)
+	}
+
+	// The transaction is dropped and never mined, one certificate was already part of too many batches
+	backend.Rollback()
+	backend.Commit()
+	if err := db.Model(&model_anchor.AnchorBatch{}).Where("id = ?", submitted[0].ID).
+		Update("submitted_at", time.Now().Add(-constant.ANCHOR_SUBMIT_TIMEOUT-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&model_anchor.CertificateAnchor{}).Where("certificate_id = ?", exhausted).
+		Update("attempts", constant.ANCHOR_MAX_ATTEMPTS).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	RunAnchoring(ctx, db, anchorer)
+
+	failed := batchesByStatus(t, db, constant.FAILED)
+	if len(failed) != 1 || failed[0].ID != submitted[0].ID || failed[0].Error == "" {
+		t.Fatalf("unexpected failed batches %+v", failed)
+	}
+	expectCertificateStatus(t, db, constant.FAILED, exhausted)
+
+	// The other certificate is batched again in the same round
+	retry := batchesByStatus(t, db, constant.SUBMITTED)
+	if len(retry) != 1 || retry[0].ID == submitted[0].ID || retry[0].Size != 1 {
+		t.Fatalf("unexpected submitted batches %+v", retry)
+	}
+	expectCertificateStatus(t, db, constant.SUBMITTED, retried)
+
+	certificateAnchor, _, err := model_anchor.GetCertificateAnchor(db, retried)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if certificateAnchor.BatchID != retry[0].ID || certificateAnchor.Attempts != 2 {
+		t.Fatalf("unexpected anchor %+v", certificateAnchor)
+	}
+
+	backend.Commit()
+	RunAnchoring(ctx, db, anchorer)
+
+	expectCertificateStatus(t, db, constant.ONCHAIN, retried)
+	expectCertificateStatus(t, db, constant.FAILED, exhausted)
+	expectAnchored(t, db, anchorer, retried)
+}
+
+func TestRunAnchoringKeepsBatchWhenNodeIsUnreachable(t *testing.T) {
+	db := newAnchorTestDB(t)
+	backend, anchorer := newTestAnchorer(t)
+
+	ids := createTestCertificates(t, db, 2)
+
+	// Every call to the node fails
+	unreachable, cancel := context.WithCancel(context.Background())
+	cancel()
+	RunAnchoring(unreachable, db, anchorer)
+	RunAnchoring(unreachable, db, anchorer)
+
+	pending := batchesByStatus(t, db, constant.PENDING)
+	if len(pending) != 1 || pending[0].TxHash != "" {
+		t.Fatalf("unexpected pending batches %+v", pending)
+	}
+	if failed := batchesByStatus(t, db, constant.FAILED); len(failed) != 0 {
+		t.Fatalf("unexpected failed batches %+v", failed)
+	}
+	expectCertificateStatus(t, db, constant.SUBMITTED, ids...)
+
+	// The same batch is sent once the node is back, without using up an attempt
+	ctx := context.Background()
+	RunAnchoring(ctx, db, anchorer)
+	submitted := batchesByStatus(t, db, constant.SUBMITTED)
+	if len(submitted) != 1 || submitted[0].ID != pending[0].ID {
+		t.Fatalf("unexpected submitted batches %+v", submitted)
+	}
+
+	backend.Commit()
+	RunAnchoring(ctx, db, anchorer)
+
+	expectCertificateStatus(t, db, constant.ONCHAIN, ids...)
+	for _, id := range ids {
+		expectAnchored(t, db, anchorer, id)
+
+		certificateAnchor, _, err := model_anchor.GetCertificateAnchor(db, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if certificateAnchor.Attempts != 1 {
+			t.Fatalf("unexpected anchor %+v", certificateAnchor)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	model_account "certification/model/account"
+	model_certificate "certification/model/certificate"
+	model_template "certification/model/template"
+	model_user "certification/model/user"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ResponseCertificate struct {
+	model_certificate.Certificate
+	Version int                            `json:"version"`
+	Fields  []model_template.TemplateField `json:"fields"`
+}
+
+type ResponseCertificates struct {
+	Certificates []model_certificate.Certificate `json:"certificates"`
+	Total        int64                           `json:"total"`
+	Page         int                             `json:"page"`
+	Limit        int                             `json:"limit"`
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// The user profile of an active user account registered with the email, if any.
+// Certificates of other recipients are linked when they claim them.
+func FindRecipientUser(db *gorm.DB, email string) *uuid.UUID {
+	account, err := model_account.GetAccountByEmail(db, email)
+	if err != nil || account.Role != constant.ROLE_USER || account.Status != constant.ACTIVE {
+		return nil
+	}
+	user, err := model_user.GetUserByAccountID(db, account.ID)
+	if err != nil {
+		return nil
+	}
+	return &user.ID
+}
+
+// Create the certificate with values already normalized by TemplateVersion.NormalizeValues,
+// reissueOf is the certificate it replaces if any
+func IssueCertificate(tx *gorm.DB, companyID uuid.UUID, issuedBy uuid.UUID, version *model_template.TemplateVersion, email string, normalized map[string]string, reissueOf *uuid.UUID) (*model_certificate.Certificate, error) {
+	email = NormalizeEmail(email)
+	certificate := model_certificate.NewCertificate(companyID, version.TemplateID, version.ID, issuedBy, email, FindRecipientUser(tx, email), normalized)
+	certificate.ReissueOfID = reissueOf
+	if err := tx.Create(&certificate).Error; err != nil {
+		return nil, err
+	}
+	return &certificate, nil
+}
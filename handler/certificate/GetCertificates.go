@@ -0,0 +1,126 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_certificate "certification/model/certificate"
+	model_template "certification/model/template"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Get Certificates
+// @Description List the certificates issued by the company, newest first
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "Status"
+// @Param template_id query string false "Template ID"
+// @Param recipient_email query string false "Recipient email"
+// @Param claimed query bool false "Whether the recipient claimed the certificate"
+// @Param page query int false "Page, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Success 200 {object} response.DataResponse{data=ResponseCertificates} "Successful get certificates"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates [get]
+func GetCertificates(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	page := ctx.QueryInt("page", 1)
+	limit := ctx.QueryInt("limit", constant.CERTIFICATE_PAGE_SIZE)
+	if page < 1 || limit < 1 || limit > constant.CERTIFICATE_MAX_PAGE_SIZE {
+		errMsg := "Invalid page or limit"
+		logger.Log.Error(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	filter := model_certificate.CertificateFilter{
+		Status:         constant.Status(ctx.Query("status")),
+		RecipientEmail: NormalizeEmail(ctx.Query("recipient_email")),
+		Limit:          limit,
+		Offset:         (page - 1) * limit,
+	}
+	if claimed := ctx.Query("claimed"); claimed != "" {
+		value := ctx.QueryBool("claimed")
+		filter.Claimed = &value
+	}
+	if templateID := ctx.Query("template_id"); templateID != "" {
+		var id uuid.UUID
+		if !utils.IsValidUUID(templateID, &id) {
+			logger.Log.Error(constant.ErrorInvalidID, templateID)
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+		}
+		filter.TemplateID = &id
+	}
+
+	certificates, total, err := model_certificate.GetCertificatesByCompanyID(initializer.DB, company.ID, filter)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseCertificates{
+		Certificates: certificates,
+		Total:        total,
+		Page:         page,
+		Limit:        limit,
+	}, "Successfully get certificates"))
+}
+
+// @Summary Get Certificate
+// @Description Get a certificate with its values and the fields of its template version
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Certificate ID"
+// @Success 200 {object} response.DataResponse{data=ResponseCertificate} "Successful get certificate"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Router /certificates/{id} [get]
+func GetCertificate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByID(initializer.DB, company.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	version, err := model_template.GetVersionByID(initializer.DB, certificate.VersionID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseCertificate{
+		Certificate: *certificate,
+		Version:     version.Version,
+		Fields:      version.Fields,
+	}, "Successfully get certificate"))
+}
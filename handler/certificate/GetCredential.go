@@ -0,0 +1,176 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/credential"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_certificate "certification/model/certificate"
+	model_user "certification/model/user"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ResponseCredential struct {
+	JWT        string                 `json:"jwt"` // VC-JWT to import in a wallet
+	Credential *credential.Credential `json:"credential"`
+}
+
+// @Summary Get Certificate Credential
+// @Description Export a certificate of the company as a W3C Verifiable Credential signed with the signing key of the company
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Certificate ID"
+// @Success 200 {object} response.DataResponse{data=ResponseCredential} "Successful export"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 409 {object} response.MessageResponse "Certificate is revoked"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates/{id}/credential [get]
+func GetCertificateCredential(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByID(initializer.DB, company.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	return sendCredential(ctx, initializer.DB, certificate)
+}
+
+// @Summary Get My Certificate Credential
+// @Description Export a certificate claimed by the logged in user as a W3C Verifiable Credential to import in a digital wallet
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Certificate ID"
+// @Success 200 {object} response.DataResponse{data=ResponseCredential} "Successful export"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 409 {object} response.MessageResponse "Certificate is revoked"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /claims/certificates/{id}/credential [get]
+func GetClaimedCertificateCredential(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	user, err := model_user.GetUserByAccountID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error("Not a user account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByUserID(initializer.DB, user.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	return sendCredential(ctx, initializer.DB, certificate)
+}
+
+// Verifiable Credential of the certificate, its ID is the URN of the certificate ID. The certificate
+// gets its index in a status list of the company the first time, the credential points at it.
+func BuildCredential(db *gorm.DB, certificate *model_certificate.Certificate) (*credential.Credential, error) {
+	verification, err := BuildVerification(db, certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := db.Begin()
+	if err := model_certificate.AssignStatusIndex(tx, certificate); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	subject := credential.Subject{
+		Email: certificate.RecipientEmail,
+		Certificate: credential.Certificate{
+			Code:         certificate.Code,
+			Name:         verification.TemplateName,
+			Version:      verification.Version,
+			Verification: VerificationURL(certificate.Code),
+			Fields:       []credential.Field{},
+		},
+	}
+	if certificate.RecipientUserID != nil {
+		if user, err := model_user.GetUserByID(db, *certificate.RecipientUserID); err == nil {
+			subject.Name = user.FullName()
+		}
+	}
+	for _, field := range verification.Fields {
+		subject.Certificate.Fields = append(subject.Certificate.Fields, credential.Field{
+			Key:   field.Key,
+			Label: field.Label,
+			Value: field.Value,
+		})
+	}
+
+	issuer := credential.Issuer{
+		ID:   credential.IssuerDID(certificate.CompanyID),
+		Name: verification.IssuerName,
+	}
+	vc := credential.New(credential.CertificateURN(certificate.ID), issuer, certificate.CreatedAt, subject)
+	vc.SetStatus(StatusListURL(*certificate.StatusListID), *certificate.StatusListIndex)
+	return vc, nil
+}
+
+func sendCredential(ctx *fiber.Ctx, db *gorm.DB, certificate *model_certificate.Certificate) error {
+	if certificate.IsRevoked() {
+		errMsg := "Certificate is revoked"
+		logger.Log.Error(errMsg, " ", certificate.ID)
+		return ctx.Status(fiber.StatusConflict).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	vc, err := BuildCredential(db, certificate)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	key, privateKey, err := handler_company.GetSigningKey(db, certificate.CompanyID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	token, err := credential.Sign(vc, key.Algorithm, key.KeyID, privateKey)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseCredential{
+		JWT:        token,
+		Credential: vc,
+	}, "Successfully get credential"))
+}
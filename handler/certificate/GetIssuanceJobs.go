@@ -0,0 +1,119 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_issuance "certification/model/issuance"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Get Issuance Jobs
+// @Description List the bulk issuance jobs of the company, newest first
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]model_issuance.IssuanceJob} "Successful get jobs"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates/jobs [get]
+func GetIssuanceJobs(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	jobs, err := model_issuance.GetJobsByCompanyID(initializer.DB, company.ID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(jobs, "Successfully get jobs"))
+}
+
+// @Summary Get Issuance Job
+// @Description Get the progress of a bulk issuance job
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} response.DataResponse{data=model_issuance.IssuanceJob} "Successful get job"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Job not found"
+// @Router /certificates/jobs/{id} [get]
+func GetIssuanceJob(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var jobID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &jobID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	job, err := model_issuance.GetJobByID(initializer.DB, company.ID, jobID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Job not found"))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(job, "Successfully get job"))
+}
+
+// @Summary Get Issuance Job Rows
+// @Description List the rows of a bulk issuance job with their certificate or error
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param status query string false "pending, completed or failed"
+// @Success 200 {object} response.DataResponse{data=[]model_issuance.IssuanceRow} "Successful get rows"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Job not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates/jobs/{id}/rows [get]
+func GetIssuanceJobRows(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var jobID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &jobID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	job, err := model_issuance.GetJobByID(initializer.DB, company.ID, jobID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Job not found"))
+	}
+
+	rows, err := model_issuance.GetRows(initializer.DB, job.ID, constant.Status(ctx.Query("status")))
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(rows, "Successfully get rows"))
+}
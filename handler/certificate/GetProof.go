@@ -0,0 +1,157 @@
+package handler_certificate
+
+import (
+	"certification/anchor"
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_anchor "certification/model/anchor"
+	model_certificate "certification/model/certificate"
+	model_user "certification/model/user"
+	"certification/response"
+	"certification/utils"
+	"certification/verifier"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errNotAnchored = errors.New("Certificate is not anchored yet")
+
+// @Summary Download My Certificate Proof
+// @Description Download the signed proof bundle of a certificate claimed by the logged in user once it is anchored on-chain.
+// @Description The bundle is checked without the API by the verifier package or the verify command of the server binary.
+// @Description Only this bundle has the recipient_salt, which proves the certificate was issued to the email of the user.
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Certificate ID"
+// @Success 200 {object} verifier.Bundle "Proof bundle"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found or not anchored yet"
+// @Failure 409 {object} response.MessageResponse "Certificate is revoked"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /claims/certificates/{id}/proof [get]
+func GetClaimedCertificateProof(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	user, err := model_user.GetUserByAccountID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error("Not a user account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByUserID(initializer.DB, user.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	return sendProof(ctx, initializer.DB, certificate, true)
+}
+
+// @Summary Download Verified Certificate Proof
+// @Description Public download of the signed proof bundle of a certificate by its verification code once it is anchored on-chain, private certificates are not found.
+// @Description The document only has the email of the recipient hashed with a salt the bundle does not include.
+// @Tags Verification
+// @Produce json
+// @Param code path string true "Verification code"
+// @Success 200 {object} verifier.Bundle "Proof bundle"
+// @Failure 404 {object} response.MessageResponse "Certificate not found or not anchored yet"
+// @Failure 409 {object} response.MessageResponse "Certificate is revoked"
+// @Failure 429 {object} response.MessageResponse "Too many requests"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /verify/{code}/proof [get]
+func GetVerificationProof(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	code := utils.NormalizeVerificationCode(ctx.Params("code"))
+
+	certificate, err := model_certificate.GetCertificateByCode(initializer.DB, code)
+	if err != nil || certificate.Private {
+		logger.Log.Info("Certificate not found for verification ", code)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	return sendProof(ctx, initializer.DB, certificate, false)
+}
+
+// Proof bundle of an anchored certificate signed with the key of the owner of the registry
+func BuildProofBundle(db *gorm.DB, certificate *model_certificate.Certificate) (*verifier.Bundle, error) {
+	certificateAnchor, batch, err := model_anchor.GetCertificateAnchor(db, certificate.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && batch.Status != constant.ONCHAIN) {
+		return nil, errNotAnchored
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := certificate.CanonicalJSON()
+	if err != nil {
+		return nil, err
+	}
+	if leaf := anchor.HashLeaf(document); leaf.Hex() != certificateAnchor.Leaf {
+		return nil, fmt.Errorf("certificate %s does not match its anchored leaf", certificate.ID)
+	}
+
+	var proof []common.Hash
+	if err := json.Unmarshal([]byte(certificateAnchor.Proof), &proof); err != nil {
+		return nil, err
+	}
+
+	bundle := verifier.Bundle{
+		Version:     verifier.Version,
+		Document:    string(document),
+		Leaf:        common.HexToHash(certificateAnchor.Leaf),
+		Proof:       proof,
+		Root:        common.HexToHash(batch.Root),
+		ChainID:     batch.ChainID,
+		Registry:    common.HexToAddress(batch.Registry),
+		TxHash:      common.HexToHash(batch.TxHash),
+		BlockNumber: batch.BlockNumber,
+	}
+
+	key, err := anchor.Key()
+	if err != nil {
+		return nil, err
+	}
+	if err := bundle.Sign(key); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// The salt of the recipient hash is only sent to the holder
+func sendProof(ctx *fiber.Ctx, db *gorm.DB, certificate *model_certificate.Certificate, holder bool) error {
+	if certificate.IsRevoked() {
+		errMsg := "Certificate is revoked"
+		logger.Log.Error(errMsg, " ", certificate.ID)
+		return ctx.Status(fiber.StatusConflict).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	bundle, err := BuildProofBundle(db, certificate)
+	if err == errNotAnchored {
+		logger.Log.Info(err, " ", certificate.ID)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	if holder {
+		bundle.RecipientSalt = certificate.RecipientSalt
+	}
+
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"certificate-%s.proof.json\"", certificate.Code))
+	return ctx.Status(fiber.StatusOK).JSON(bundle)
+}
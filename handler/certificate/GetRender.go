@@ -0,0 +1,212 @@
+package handler_certificate
+
+import (
+	"bytes"
+	"certification/config"
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_certificate "certification/model/certificate"
+	model_template "certification/model/template"
+	model_user "certification/model/user"
+	"certification/render"
+	"certification/response"
+	"certification/utils"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// @Summary Render Certificate
+// @Description Download a certificate of the company as PNG or PDF rendered with the layout of its template
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce image/png,application/pdf
+// @Param id path string true "Certificate ID"
+// @Param format query string false "png or pdf, png by default"
+// @Success 200 {file} file "Rendered certificate"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates/{id}/render [get]
+func GetCertificateRender(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByID(initializer.DB, company.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	return sendRender(ctx, initializer.DB, certificate)
+}
+
+// @Summary Render My Certificate
+// @Description Download a certificate claimed by the logged in user as PNG or PDF
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce image/png,application/pdf
+// @Param id path string true "Certificate ID"
+// @Param format query string false "png or pdf, png by default"
+// @Success 200 {file} file "Rendered certificate"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /claims/certificates/{id}/render [get]
+func GetClaimedCertificateRender(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	user, err := model_user.GetUserByAccountID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error("Not a user account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByUserID(initializer.DB, user.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	return sendRender(ctx, initializer.DB, certificate)
+}
+
+// @Summary Render Verified Certificate
+// @Description Public download of a certificate by its verification code as PNG or PDF, private certificates are not found
+// @Tags Verification
+// @Produce image/png,application/pdf
+// @Param code path string true "Verification code"
+// @Param format query string false "png or pdf, png by default"
+// @Success 200 {file} file "Rendered certificate"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 429 {object} response.MessageResponse "Too many requests"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /verify/{code}/render [get]
+func GetVerificationRender(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	code := utils.NormalizeVerificationCode(ctx.Params("code"))
+
+	certificate, err := model_certificate.GetCertificateByCode(initializer.DB, code)
+	if err != nil || certificate.Private {
+		logger.Log.Info("Certificate not found for verification ", code)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	return sendRender(ctx, initializer.DB, certificate)
+}
+
+// Address of the public verification encoded in the QR code of the certificate
+func VerificationURL(code string) string {
+	return strings.TrimSuffix(config.API_URL, "/") + "/verify/" + code
+}
+
+// Rendered certificate in the given format, taken from the storage unless the layout of the
+// template or what is drawn on the certificate, e.g. its revocation, changed since it was last rendered
+func RenderCertificate(db *gorm.DB, certificate *model_certificate.Certificate, format string) ([]byte, error) {
+	layout, err := model_template.GetLayout(db, certificate.TemplateID)
+	if err != nil {
+		version, err := model_template.GetVersionByID(db, certificate.VersionID)
+		if err != nil {
+			return nil, err
+		}
+		layout = render.DefaultLayout(version)
+	}
+
+	verification, err := BuildVerification(db, certificate)
+	if err != nil {
+		return nil, err
+	}
+	placeholders := make(map[string]string, len(verification.Fields)+5)
+	for _, field := range verification.Fields {
+		placeholders[field.Key] = field.Value
+	}
+	placeholders["code"] = verification.Code
+	placeholders["recipient_name"] = verification.RecipientName
+	placeholders["issuer_name"] = verification.IssuerName
+	placeholders["template_name"] = verification.TemplateName
+	placeholders["issued_at"] = verification.IssuedAt.Format(constant.DATE_FORMAT)
+
+	data := render.Data{
+		URL:          VerificationURL(certificate.Code),
+		Revoked:      !verification.Valid,
+		Placeholders: placeholders,
+	}
+	fingerprint := render.Fingerprint(layout.Revision, data)
+	key := render.RenderKey(certificate.ID, fingerprint, format)
+	if file, err := render.LoadFile(key); err == nil {
+		return file, nil
+	}
+
+	var background image.Image
+	if layout.Background != "" {
+		raw, err := render.LoadFile(layout.Background)
+		if err != nil {
+			return nil, err
+		}
+		if background, _, err = image.Decode(bytes.NewReader(raw)); err != nil {
+			return nil, err
+		}
+	}
+
+	img, err := render.Render(layout, background, data)
+	if err != nil {
+		return nil, err
+	}
+	file, err := render.Encode(img, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := render.SaveFile(key, file); err != nil {
+		logger.Log.Error(err) // served anyway, it is rendered again on the next request
+	} else {
+		render.RemoveStaleRenders(certificate.ID, fingerprint)
+	}
+	return file, nil
+}
+
+func sendRender(ctx *fiber.Ctx, db *gorm.DB, certificate *model_certificate.Certificate) error {
+	format := strings.ToLower(ctx.Query("format", constant.RENDER_FORMAT_PNG))
+	if format != constant.RENDER_FORMAT_PNG && format != constant.RENDER_FORMAT_PDF {
+		errMsg := "Format must be png or pdf"
+		logger.Log.Error(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	data, err := RenderCertificate(db, certificate, format)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	ctx.Type(format)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=\"certificate-%s.%s\"", certificate.Code, format))
+	return ctx.Status(fiber.StatusOK).Send(data)
+}
@@ -0,0 +1,56 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_certificate "certification/model/certificate"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Get Certificate Revocations
+// @Description History of the revocations and unrevocations of a certificate with their reason, actor and on-chain status
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Certificate ID"
+// @Success 200 {object} response.DataResponse{data=[]model_certificate.CertificateRevocation} "Successful get revocations"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates/{id}/revocations [get]
+func GetCertificateRevocations(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByID(initializer.DB, company.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	revocations, err := model_certificate.GetRevocations(initializer.DB, certificate.ID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(revocations, "Successfully get revocations"))
+}
@@ -0,0 +1,116 @@
+package handler_certificate
+
+import (
+	"certification/cache"
+	"certification/config"
+	"certification/constant"
+	"certification/credential"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_certificate "certification/model/certificate"
+	model_company "certification/model/company"
+	"certification/response"
+	"certification/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Cached form of a status list
+type cachedStatusList struct {
+	JWT string `json:"jwt"`
+}
+
+// @Summary Get Status List
+// @Description Revocation status list of the credentials of a company as a StatusList2021Credential signed with the signing key of the company, the bit of a revoked certificate is set.
+// @Description This is the statusListCredential of the credentials, it is the bare VC-JWT so that verifiers can dereference it.
+// @Tags Verification
+// @Produce application/jwt
+// @Param id path string true "Status list ID"
+// @Success 200 {string} string "VC-JWT of the status list"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Status list not found"
+// @Failure 429 {object} response.MessageResponse "Too many requests"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /status-lists/{id} [get]
+func GetStatusList(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var listID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &listID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	if cached, err := cache.Redis.GetCacheById(constant.REDIS_STATUS_LIST, listID.String()); err == nil {
+		if token, ok := cached["jwt"].(string); ok && token != "" {
+			return sendStatusList(ctx, token)
+		}
+	}
+
+	list, err := model_certificate.GetStatusListByID(initializer.DB, listID)
+	if err != nil {
+		logger.Log.Info("Status list not found ", listID)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Status list not found"))
+	}
+
+	company, err := model_company.GetCompanyByID(initializer.DB, list.CompanyID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	revoked, err := model_certificate.GetRevokedStatusIndexes(initializer.DB, list.ID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	issuer := credential.Issuer{
+		ID:   credential.IssuerDID(company.ID),
+		Name: company.Name,
+	}
+	statusList, err := credential.NewStatusList(StatusListURL(list.ID), issuer, list.Size, revoked)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	key, privateKey, err := handler_company.GetSigningKey(initializer.DB, company.ID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	token, err := credential.SignStatusList(statusList, key.Algorithm, key.KeyID, privateKey)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := cache.Redis.SetCacheById(constant.REDIS_STATUS_LIST, list.ID.String(), cachedStatusList{JWT: token}, constant.STATUS_LIST_CACHE_EXPIRY); err != nil {
+		logger.Log.Error(err)
+	}
+
+	return sendStatusList(ctx, token)
+}
+
+func sendStatusList(ctx *fiber.Ctx, token string) error {
+	ctx.Set(fiber.HeaderContentType, credential.MediaTypeJWT)
+	return ctx.Status(fiber.StatusOK).SendString(token)
+}
+
+// Public URL of a status list, the statusListCredential of the credentials it covers
+func StatusListURL(listID uuid.UUID) string {
+	return strings.TrimSuffix(config.API_URL, "/") + "/status-lists/" + listID.String()
+}
+
+// Drop the cached status list of the certificate after its revocation status changed
+func InvalidateStatusList(certificate *model_certificate.Certificate) {
+	if certificate.StatusListID == nil {
+		return
+	}
+	if err := cache.Redis.DeleteCacheById(constant.REDIS_STATUS_LIST, certificate.StatusListID.String()); err != nil {
+		logger.Log.Error(err)
+	}
+}
@@ -0,0 +1,215 @@
+package handler_certificate
+
+import (
+	"certification/cache"
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_anchor "certification/model/anchor"
+	model_certificate "certification/model/certificate"
+	model_company "certification/model/company"
+	model_template "certification/model/template"
+	model_user "certification/model/user"
+	"certification/response"
+	"certification/utils"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type VerificationField struct {
+	Key   string             `json:"key"`
+	Label string             `json:"label"`
+	Type  constant.FieldType `json:"type"`
+	Value string             `json:"value"`
+}
+
+// On-chain proof of the certificate, the leaf is the keccak256 of the keccak256 of its canonical JSON.
+// Hashing the leaf with every hash of the proof in sorted pairs gives the root anchored in the registry.
+type ResponseAnchor struct {
+	ChainID     string    `json:"chain_id"`
+	Registry    string    `json:"registry"`
+	TxHash      string    `json:"tx_hash"`
+	BlockNumber uint64    `json:"block_number"`
+	Root        string    `json:"root"`
+	Leaf        string    `json:"leaf"`
+	Proof       []string  `json:"proof"`
+	AnchoredAt  time.Time `json:"anchored_at"`
+}
+
+// Last revocation or unrevocation of the certificate. Its leaf is the keccak256 of the keccak256 of
+// the canonical JSON of {id, certificate, action, reason, at} where certificate is the leaf of the certificate.
+type ResponseRevocation struct {
+	ID     string          `json:"id"`
+	Action string          `json:"action"` // revoke or unrevoke
+	Reason string          `json:"reason"`
+	At     time.Time       `json:"at"`
+	Status constant.Status `json:"status"`
+	Anchor *ResponseAnchor `json:"anchor"` // set once anchored on-chain
+}
+
+type ResponseVerification struct {
+	Code          string              `json:"code"`
+	Valid         bool                `json:"valid"` // false once revoked
+	Status        constant.Status     `json:"status"`
+	IssuedAt      time.Time           `json:"issued_at"`
+	RevokedAt     *time.Time          `json:"revoked_at"`
+	RevokeReason  string              `json:"revoke_reason"`
+	ReissuedAs    string              `json:"reissued_as"` // verification code of the replacement
+	IssuerID      string              `json:"issuer_id"`
+	IssuerName    string              `json:"issuer_name"`
+	RecipientName string              `json:"recipient_name"`
+	TemplateName  string              `json:"template_name"`
+	Version       int                 `json:"version"`
+	Fields        []VerificationField `json:"fields"`
+	Anchor        *ResponseAnchor     `json:"anchor"`     // set once anchored on-chain
+	Revocation    *ResponseRevocation `json:"revocation"` // set once revoked or unrevoked
+}
+
+// @Summary Verify Certificate
+// @Description Public verification of a certificate by its code, private certificates are not found
+// @Tags Verification
+// @Produce json
+// @Param code path string true "Verification code"
+// @Success 200 {object} response.DataResponse{data=ResponseVerification} "Successful verification"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 429 {object} response.MessageResponse "Too many requests"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /verify/{code} [get]
+func VerifyCertificate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	code := utils.NormalizeVerificationCode(ctx.Params("code"))
+
+	certificate, err := model_certificate.GetCertificateByCode(initializer.DB, code)
+	if err != nil || certificate.Private {
+		logger.Log.Info("Certificate not found for verification ", code)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	result, err := BuildVerification(initializer.DB, certificate)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := cache.Redis.SetCacheById(constant.REDIS_VERIFICATION, code, result, constant.VERIFICATION_CACHE_EXPIRY); err != nil {
+		logger.Log.Error(err)
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(result, "Successfully verified"))
+}
+
+// Public view of the certificate, the recipient email is never shown
+func BuildVerification(db *gorm.DB, certificate *model_certificate.Certificate) (*ResponseVerification, error) {
+	company, err := model_company.GetCompanyByID(db, certificate.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	certificateTemplate, err := model_template.GetTemplateByIDUnscoped(db, certificate.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := model_template.GetVersionByID(db, certificate.VersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := ResponseVerification{
+		Code:          certificate.Code,
+		Valid:         !certificate.IsRevoked(),
+		Status:        certificate.Status,
+		IssuedAt:      certificate.CreatedAt,
+		RevokedAt:     certificate.RevokedAt,
+		RevokeReason:  certificate.RevokeReason,
+		IssuerID:      company.ID.String(),
+		IssuerName:    company.Name,
+		RecipientName: MaskEmail(certificate.RecipientEmail),
+		TemplateName:  certificateTemplate.Name,
+		Version:       version.Version,
+	}
+
+	if certificate.RecipientUserID != nil {
+		if user, err := model_user.GetUserByID(db, *certificate.RecipientUserID); err == nil && user.FullName() != "" {
+			result.RecipientName = user.FullName()
+		}
+	}
+	if certificate.ReissuedAsID != nil {
+		result.ReissuedAs = model_certificate.GetCode(db, *certificate.ReissuedAsID)
+	}
+
+	if anchor, batch, err := model_anchor.GetCertificateAnchor(db, certificate.ID); err == nil && batch.Status == constant.ONCHAIN {
+		result.Anchor = &ResponseAnchor{
+			ChainID:     batch.ChainID,
+			Registry:    batch.Registry,
+			TxHash:      batch.TxHash,
+			BlockNumber: batch.BlockNumber,
+			Root:        batch.Root,
+			Leaf:        anchor.Leaf,
+			AnchoredAt:  *batch.AnchoredAt,
+		}
+		if err := json.Unmarshal([]byte(anchor.Proof), &result.Anchor.Proof); err != nil {
+			return nil, err
+		}
+	}
+
+	if revocation, err := model_certificate.GetLastRevocation(db, certificate.ID); err == nil {
+		result.Revocation = &ResponseRevocation{
+			ID:     revocation.ID.String(),
+			Action: revocation.Action,
+			Reason: revocation.Reason,
+			At:     revocation.CreatedAt.UTC().Truncate(time.Second),
+			Status: revocation.Status,
+		}
+		if revocation.Status == constant.ONCHAIN && revocation.BatchID != nil {
+			batch, err := model_anchor.GetBatchByID(db, *revocation.BatchID)
+			if err != nil {
+				return nil, err
+			}
+			result.Revocation.Anchor = &ResponseAnchor{
+				ChainID:     batch.ChainID,
+				Registry:    batch.Registry,
+				TxHash:      batch.TxHash,
+				BlockNumber: batch.BlockNumber,
+				Root:        batch.Root,
+				Leaf:        revocation.Leaf,
+				AnchoredAt:  *batch.AnchoredAt,
+			}
+			if err := json.Unmarshal([]byte(revocation.Proof), &result.Revocation.Anchor.Proof); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	values := certificate.ValueMap()
+	for _, field := range version.Fields {
+		value, ok := values[field.Key]
+		if !ok {
+			continue
+		}
+		result.Fields = append(result.Fields, VerificationField{
+			Key:   field.Key,
+			Label: field.Label,
+			Type:  field.Type,
+			Value: value,
+		})
+	}
+	return &result, nil
+}
+
+// Drop the cached verification of the certificate after it changed
+func InvalidateVerification(code string) {
+	if err := cache.Redis.DeleteCacheById(constant.REDIS_VERIFICATION, code); err != nil {
+		logger.Log.Error(err)
+	}
+}
+
+// Shown as the recipient until the certificate is claimed, e.g. j***@example.com
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
@@ -0,0 +1,189 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/logger"
+	"certification/mailer"
+	model_certificate "certification/model/certificate"
+	model_company "certification/model/company"
+	model_issuance "certification/model/issuance"
+	model_template "certification/model/template"
+	"certification/template"
+	"certification/utils"
+	"context"
+	"encoding/json"
+
+	"github.com/Boostport/mjml-go"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IssuanceProgress struct {
+	JobID  uuid.UUID       `json:"job_id"`
+	Status constant.Status `json:"status"`
+	Total  int             `json:"total"`
+	Issued int             `json:"issued"`
+	Failed int             `json:"failed"`
+}
+
+// Resume the jobs interrupted by a restart, rows already issued are not issued again
+func ResumeIssuanceJobs(db *gorm.DB) {
+	ids, err := model_issuance.GetUnfinishedJobIDs(db)
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+	for _, id := range ids {
+		go RunIssuanceJob(db, id)
+	}
+}
+
+// Issue the certificates of the pending rows in batches, notifying the recipients of each batch
+// and pushing the progress to the websocket connections of the account which uploaded the file
+func RunIssuanceJob(db *gorm.DB, jobID uuid.UUID) {
+	if started, err := model_issuance.StartJob(db, jobID); err != nil || !started {
+		if err != nil {
+			logger.Log.Error(err)
+		}
+		return
+	}
+
+	job, err := model_issuance.GetJobByIDUnscoped(db, jobID)
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+
+	version, err := model_template.GetVersionByID(db, job.VersionID)
+	if err != nil {
+		logger.Log.Error(err)
+		failIssuanceJob(db, job)
+		return
+	}
+	certificateTemplate, err := model_template.GetTemplateByIDUnscoped(db, job.TemplateID)
+	if err != nil {
+		logger.Log.Error(err)
+		failIssuanceJob(db, job)
+		return
+	}
+	company, err := model_company.GetCompanyByID(db, job.CompanyID)
+	if err != nil {
+		logger.Log.Error(err)
+		failIssuanceJob(db, job)
+		return
+	}
+
+	logger.Log.Info("Issuance job started ", job.ID)
+	for {
+		rows, err := model_issuance.GetPendingRows(db, job.ID, constant.ISSUANCE_BATCH_SIZE)
+		if err != nil {
+			logger.Log.Error(err)
+			return // stays ACTIVE and is resumed on the next start
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		var messages []mailer.Message
+		for _, row := range rows {
+			certificate, err := issueRow(db, job, version, &row)
+			if err != nil {
+				logger.Log.Error("Issuance job ", job.ID, " row ", row.Row, ": ", err)
+				if err := model_issuance.FailRow(db, row.ID, err.Error()); err != nil {
+					logger.Log.Error(err)
+				}
+				continue
+			}
+			if certificate == nil {
+				continue
+			}
+
+			html, err := mjml.ToHTML(context.Background(), template.TemplateCertificateIssued(company.Name, certificateTemplate.Name, certificate.Code), mjml.WithMinify(true))
+			if err != nil {
+				logger.Log.Error(err)
+				continue
+			}
+			messages = append(messages, mailer.Message{
+				To:       row.RecipientEmail,
+				Subject:  "You received a certificate from " + company.Name,
+				BodyHTML: html,
+			})
+		}
+
+		if err := mailer.SendEmailBatch(messages); err != nil {
+			logger.Log.Error(err)
+		}
+
+		if err := model_issuance.UpdateJobProgress(db, job); err != nil {
+			logger.Log.Error(err)
+		}
+		publishIssuanceProgress(job)
+	}
+
+	if err := model_issuance.UpdateJobProgress(db, job); err != nil {
+		logger.Log.Error(err)
+	}
+	status := constant.COMPLETED
+	if job.Issued == 0 && job.Failed > 0 {
+		status = constant.FAILED
+	}
+	if err := model_issuance.FinishJob(db, job, status); err != nil {
+		logger.Log.Error(err)
+	}
+	publishIssuanceProgress(job)
+
+	logger.Log.Info("Issuance job ", job.ID, " finished, issued ", job.Issued, " failed ", job.Failed)
+}
+
+// Issue the certificate of a row, the row is claimed in the same transaction so that
+// a job resumed by two instances does not issue it twice. nil means it was already handled.
+func issueRow(db *gorm.DB, job *model_issuance.IssuanceJob, version *model_template.TemplateVersion, row *model_issuance.IssuanceRow) (*model_certificate.Certificate, error) {
+	var values map[string]string
+	if err := json.Unmarshal([]byte(row.Values), &values); err != nil {
+		return nil, err
+	}
+
+	tx := db.Begin()
+
+	claimed, err := model_issuance.ClaimRow(tx, row.ID)
+	if err != nil || !claimed {
+		tx.Rollback()
+		return nil, err
+	}
+
+	certificate, err := IssueCertificate(tx, job.CompanyID, job.CreatedBy, version, row.RecipientEmail, values, nil)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := model_issuance.SetRowCertificate(tx, row.ID, certificate.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+func failIssuanceJob(db *gorm.DB, job *model_issuance.IssuanceJob) {
+	if err := model_issuance.FinishJob(db, job, constant.FAILED); err != nil {
+		logger.Log.Error(err)
+	}
+	publishIssuanceProgress(job)
+}
+
+func publishIssuanceProgress(job *model_issuance.IssuanceJob) {
+	err := utils.PublishAccountEvent(job.CreatedBy.String(), constant.EVENT_ISSUANCE_PROGRESS, IssuanceProgress{
+		JobID:  job.ID,
+		Status: job.Status,
+		Total:  job.Total,
+		Issued: job.Issued,
+		Failed: job.Failed,
+	})
+	if err != nil {
+		logger.Log.Error(err)
+	}
+}
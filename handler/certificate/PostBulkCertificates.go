@@ -0,0 +1,288 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_issuance "certification/model/issuance"
+	model_template "certification/model/template"
+	"certification/response"
+	"certification/spreadsheet"
+	"certification/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RowError struct {
+	Row   int    `json:"row"` // line in the file, the header is row 1
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+// @Summary Bulk Issue Certificates
+// @Description Upload a .csv or .xlsx file with a header row to issue one certificate per row.
+// @Description Columns match the field keys or labels of the template unless a mapping of column to field key is given, an empty key ignores the column.
+// @Description Every row is validated first, nothing is issued when a row is invalid. Valid files are issued by a background job,
+// @Description its progress is pushed as issuance_progress events over the websocket of the account.
+// @Tags Certificate
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or XLSX file"
+// @Param template_id formData string true "Template ID"
+// @Param version formData int false "Template version, the latest when empty"
+// @Param email_column formData string false "Column of the recipient email, email by default"
+// @Param mapping formData string false "JSON object of column to field key"
+// @Success 202 {object} response.DataResponse{data=model_issuance.IssuanceJob} "Job created"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Failure 422 {object} response.DataResponse{data=[]RowError} "Invalid rows"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates/bulk [post]
+func CreateBulkCertificates(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.FormValue("template_id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.FormValue("template_id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	mapping := map[string]string{}
+	if raw := ctx.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			errMsg := "Mapping must be a JSON object of column to field key"
+			logger.Log.Error(errMsg)
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+		}
+	}
+
+	emailColumn := ctx.FormValue("email_column", constant.ISSUANCE_EMAIL_COLUMN)
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("File is required"))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	number := template.LatestVersion
+	if raw := ctx.FormValue("version"); raw != "" {
+		if number, err = strconv.Atoi(raw); err != nil {
+			logger.Log.Error("Invalid version ", raw)
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Invalid version"))
+		}
+	}
+	version, err := model_template.GetVersion(initializer.DB, template.ID, number)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Version not found"))
+	}
+
+	// The header row comes on top of the rows
+	records, err := spreadsheet.ReadFile(header, constant.ISSUANCE_MAX_ROWS+1)
+	if errors.Is(err, spreadsheet.ErrTooManyRecords) {
+		errMsg := fmt.Sprintf("File has more than %d rows", constant.ISSUANCE_MAX_ROWS)
+		logger.Log.Error(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	if len(records) < 2 {
+		errMsg := "File must have a header row and at least one row"
+		logger.Log.Error(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	columns, emailIndex, err := mapColumns(records[0], version, emailColumn, mapping)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	rows, rowErrors := validateRows(records, columns, emailIndex, version)
+	if len(rowErrors) > 0 {
+		logger.Log.Error("Bulk issuance rejected, invalid rows: ", len(rowErrors))
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(response.ErrorDataResponseBody(rowErrors, "Some rows are invalid, nothing was issued"))
+	}
+	if len(rows) == 0 {
+		errMsg := "File must have a header row and at least one row"
+		logger.Log.Error(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	tx := initializer.DB.Begin()
+
+	job := model_issuance.IssuanceJob{
+		CompanyID:  company.ID,
+		CreatedBy:  accountID,
+		TemplateID: template.ID,
+		VersionID:  version.ID,
+		FileName:   header.Filename,
+		Status:     constant.PENDING,
+		Total:      len(rows),
+	}
+	if err := tx.Create(&job).Error; err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	for i := range rows {
+		rows[i].JobID = job.ID
+	}
+	if err := tx.CreateInBatches(&rows, 1000).Error; err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	go RunIssuanceJob(initializer.DB, job.ID)
+
+	logger.Log.Info("Issuance job created ", job.ID, " with ", job.Total, " rows for ", company.ID)
+	return ctx.Status(fiber.StatusAccepted).JSON(response.DataResponseBody(job, constant.SuccessCreateRecord))
+}
+
+// Field key of every column of the header and the index of the email column, ignored columns have an empty key
+func mapColumns(header []string, version *model_template.TemplateVersion, emailColumn string, mapping map[string]string) ([]string, int, error) {
+	byKey := make(map[string]string, len(version.Fields))
+	byLabel := make(map[string]string, len(version.Fields))
+	for _, f := range version.Fields {
+		byKey[f.Key] = f.Key
+		byLabel[strings.ToLower(f.Label)] = f.Key
+	}
+
+	columns := make([]string, len(header))
+	emailIndex := -1
+	mapped := make(map[string]bool, len(header))
+	var unknown []string
+
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		if strings.EqualFold(name, emailColumn) {
+			emailIndex = i
+			continue
+		}
+
+		key, ok := mapping[name]
+		if ok && key == "" {
+			continue
+		}
+		if !ok {
+			if key, ok = byKey[name]; !ok {
+				key, ok = byLabel[strings.ToLower(name)]
+			}
+		}
+		if _, exists := byKey[key]; !ok || !exists {
+			unknown = append(unknown, name)
+			continue
+		}
+		if mapped[key] {
+			return nil, -1, fmt.Errorf("More than one column is mapped to field %s", key)
+		}
+		mapped[key] = true
+		columns[i] = key
+	}
+
+	if emailIndex < 0 {
+		return nil, -1, fmt.Errorf("Column %s with the recipient email is missing", emailColumn)
+	}
+	if len(unknown) > 0 {
+		return nil, -1, fmt.Errorf("Columns %s do not match a field of the template", strings.Join(unknown, ", "))
+	}
+	return columns, emailIndex, nil
+}
+
+// Validate every row against the template version, blank rows are skipped
+func validateRows(records [][]string, columns []string, emailIndex int, version *model_template.TemplateVersion) ([]model_issuance.IssuanceRow, []RowError) {
+	validate := validator.New()
+
+	var rows []model_issuance.IssuanceRow
+	var rowErrors []RowError
+
+	for i, record := range records[1:] {
+		line := i + 2
+
+		blank := true
+		for _, cell := range record {
+			if strings.TrimSpace(cell) != "" {
+				blank = false
+				break
+			}
+		}
+		if blank {
+			continue
+		}
+
+		email := ""
+		if emailIndex < len(record) {
+			email = NormalizeEmail(record[emailIndex])
+		}
+
+		values := make(map[string]interface{})
+		for j, cell := range record {
+			if j >= len(columns) || columns[j] == "" {
+				continue
+			}
+			if cell = strings.TrimSpace(cell); cell != "" {
+				values[columns[j]] = cell
+			}
+		}
+
+		if err := validate.Var(email, "required,email"); err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Email: email, Error: "Invalid recipient email"})
+			continue
+		}
+
+		normalized, err := version.NormalizeValues(values)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Email: email, Error: err.Error()})
+			continue
+		}
+
+		encoded, err := json.Marshal(normalized)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: line, Email: email, Error: err.Error()})
+			continue
+		}
+
+		rows = append(rows, model_issuance.IssuanceRow{
+			Row:            line,
+			RecipientEmail: email,
+			Values:         string(encoded),
+			Status:         constant.PENDING,
+		})
+	}
+	return rows, rowErrors
+}
@@ -0,0 +1,85 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_template "certification/model/template"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingCertificate struct {
+	TemplateID     string                 `json:"template_id" validate:"required,uuid"`
+	Version        *int                   `json:"version"` // null issues with the latest version
+	RecipientEmail string                 `json:"recipient_email" validate:"required,email"`
+	Values         map[string]interface{} `json:"values"`
+}
+
+// @Summary Issue Certificate
+// @Description Issue a certificate to a recipient, the values are validated against the fields of the template version
+// @Tags Certificate
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingCertificate body IncomingCertificate true "Template, recipient and field values"
+// @Success 200 {object} response.DataResponse{data=ResponseCertificate} "Successful issue"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates [post]
+func CreateCertificate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingCertificate
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, uuid.MustParse(body.TemplateID))
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	number := template.LatestVersion
+	if body.Version != nil {
+		number = *body.Version
+	}
+	version, err := model_template.GetVersion(initializer.DB, template.ID, number)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Version not found"))
+	}
+
+	values, err := version.NormalizeValues(body.Values)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	certificate, err := IssueCertificate(initializer.DB, company.ID, accountID, version, body.RecipientEmail, values, nil)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Certificate issued ", certificate.ID, " by ", company.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseCertificate{
+		Certificate: *certificate,
+		Version:     version.Version,
+		Fields:      version.Fields,
+	}, constant.SuccessCreateRecord))
+}
@@ -0,0 +1,297 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_auth "certification/handler/auth"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_certificate "certification/model/certificate"
+	model_user "certification/model/user"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingClaimRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type IncomingClaimVerify struct {
+	Email    string `json:"email" validate:"required,email"`
+	Code     string `json:"code" validate:"required,len=6,numeric"`
+	Password string `json:"password" validate:"omitempty,min=8"` // activates an account created by the claim
+}
+
+type IncomingClaimedCertificate struct {
+	Private *bool `json:"private" validate:"required"`
+}
+
+type ResponseClaim struct {
+	Claimed       int64           `json:"claimed"`
+	AccountStatus constant.Status `json:"account_status"`
+}
+
+const claimRequestMessage = "If certificates were issued to this email, a code has been sent"
+
+// @Summary Request Certificate Claim
+// @Description Email a one-time password to the recipient of unclaimed certificates.
+// @Description Recipients without an account get a pending user account which is activated once a password is set.
+// @Tags Certificate
+// @Accept json
+// @Produce json
+// @Param IncomingClaimRequest body IncomingClaimRequest true "Recipient email"
+// @Success 200 {object} response.MessageResponse "Code sent if certificates were issued to the email"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 429 {object} response.MessageResponse "Too many requests"
+// @Router /claims/request [post]
+func RequestClaim(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingClaimRequest
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	email := NormalizeEmail(body.Email)
+
+	// The account and the code are made in the background so that the response is the same,
+	// and takes as long, whether or not certificates were issued to the email
+	go sendClaimCode(initializer, email)
+
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(claimRequestMessage))
+}
+
+// Email a claim code to the recipient of unclaimed certificates, creating a pending account
+// when the email has none. Errors, including a locked or cooling down OTP, are only logged.
+func sendClaimCode(initializer *database.Initializer, email string) {
+	if model_certificate.CountClaimableCertificates(initializer.DB, email) == 0 {
+		logger.Log.Info("No certificates to claim for ", email)
+		return
+	}
+
+	account, err := model_account.GetAccountByEmail(initializer.DB, email)
+	if err != nil {
+		if account, err = createClaimAccount(initializer, email); err != nil {
+			logger.Log.Error(err)
+			return
+		}
+	}
+
+	if account.Role != constant.ROLE_USER || account.Status == constant.INACTIVE || account.Status == constant.DELETED {
+		logger.Log.Info("Account cannot claim certificates: ", account.ID)
+		return
+	}
+
+	if err := handler_auth.SendOTP(initializer, account, constant.OTP_PURPOSE_CLAIM_CERTIFICATE); err != nil {
+		logger.Log.Error(err, account.ID)
+		return
+	}
+
+	logger.Log.Info("Claim OTP sent to ", account.ID)
+}
+
+// @Summary Verify Certificate Claim
+// @Description Verify the claim code and attach the certificates issued to the email to its user account.
+// @Description A pending account is activated when a password is given, otherwise it can be set with the forgot password flow.
+// @Tags Certificate
+// @Accept json
+// @Produce json
+// @Param IncomingClaimVerify body IncomingClaimVerify true "Recipient email, code and optional password"
+// @Success 200 {object} response.DataResponse{data=ResponseClaim} "Certificates claimed"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 429 {object} response.MessageResponse "Too many failed attempts"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /claims/verify [post]
+func VerifyClaim(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingClaimVerify
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	email := NormalizeEmail(body.Email)
+
+	account, err := model_account.GetAccountByEmail(initializer.DB, email)
+	if err != nil || account.Role != constant.ROLE_USER {
+		logger.Log.Error("No account to claim for ", email)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(handler_auth.ErrOTPInvalid.Error()))
+	}
+
+	err = handler_auth.VerifyOTP(initializer.DB, account.ID, constant.OTP_PURPOSE_CLAIM_CERTIFICATE, body.Code)
+	if err == handler_auth.ErrOTPLocked {
+		logger.Log.Error(err, account.ID)
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	if err != nil {
+		logger.Log.Error(err, account.ID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	user, err := model_user.GetUserByAccountID(initializer.DB, account.ID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// The recipient name of the verifications changes once claimed
+	codes, err := model_certificate.GetClaimableCodes(initializer.DB, email)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	tx := initializer.DB.Begin()
+	defer utils.DiscardPendingSessions(tx)
+
+	claimed, err := model_certificate.ClaimCertificates(tx, email, user.ID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// The code proves the recipient owns the email, so a pending account can be activated
+	if account.Status == constant.PENDING && body.Password != "" {
+		hashPassword, err := handler_auth.HashPassword(body.Password)
+		if err != nil {
+			tx.Rollback()
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Unable to hash password"))
+		}
+		if err := model_account.UpdateAccountPassword(tx, account.ID, hashPassword); err != nil {
+			tx.Rollback()
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+		}
+		if err := model_account.ActivatePendingAccount(tx, account.ID); err != nil {
+			tx.Rollback()
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+		}
+		account.Status = constant.ACTIVE
+	}
+
+	if err := utils.CommitTransaction(tx); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	for _, code := range codes {
+		InvalidateVerification(code)
+	}
+
+	logger.Log.Info("Claimed ", claimed, " certificates for ", account.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseClaim{
+		Claimed:       claimed,
+		AccountStatus: account.Status,
+	}, "Successfully claimed"))
+}
+
+// @Summary Get My Certificates
+// @Description List the certificates claimed by the logged in user
+// @Tags Certificate
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]model_certificate.Certificate} "Successful get certificates"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /claims/certificates [get]
+func GetClaimedCertificates(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	user, err := model_user.GetUserByAccountID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error("Not a user account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificates, err := model_certificate.GetCertificatesByUserID(initializer.DB, user.ID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(certificates, "Successfully get certificates"))
+}
+
+// @Summary Update My Certificate
+// @Description Make a claimed certificate private to hide it from public verification, or public again
+// @Tags Certificate
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Certificate ID"
+// @Param IncomingClaimedCertificate body IncomingClaimedCertificate true "Visibility of the certificate"
+// @Success 200 {object} response.MessageResponse "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /claims/certificates/{id} [patch]
+func UpdateClaimedCertificate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingClaimedCertificate
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	user, err := model_user.GetUserByAccountID(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error("Not a user account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByUserID(initializer.DB, user.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	if err := model_certificate.UpdatePrivate(initializer.DB, certificate.ID, *body.Private); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	InvalidateVerification(certificate.Code)
+
+	logger.Log.Info("Certificate ", certificate.ID, " private set to ", *body.Private)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessUpdateRecord))
+}
+
+// Create a pending user account without password for a recipient who has none yet
+func createClaimAccount(initializer *database.Initializer, email string) (*model_account.Account, error) {
+	accountID := uuid.New()
+	account := model_account.Account{
+		ID:     accountID,
+		Email:  email,
+		Role:   constant.ROLE_USER,
+		Status: constant.PENDING,
+	}
+	user := model_user.User{
+		AccountID: &accountID,
+	}
+
+	tx := initializer.DB.Begin()
+
+	if err := tx.Create(&account).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	logger.Log.Info("Pending account created for claim ", account.ID)
+	return &account, nil
+}
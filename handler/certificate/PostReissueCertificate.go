@@ -0,0 +1,145 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_certificate "certification/model/certificate"
+	model_template "certification/model/template"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingReissueCertificate struct {
+	Reason           string                 `json:"reason" validate:"required,max=500"`
+	RecipientEmail   string                 `json:"recipient_email" validate:"omitempty,email"` // empty keeps the recipient
+	Values           map[string]interface{} `json:"values"`                                     // override the values of the original
+	UseLatestVersion bool                   `json:"use_latest_version"`
+}
+
+// @Summary Reissue Certificate
+// @Description Revoke a certificate and issue a corrected copy, values not given are taken from the original
+// @Tags Certificate
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Certificate ID"
+// @Param IncomingReissueCertificate body IncomingReissueCertificate true "Reason and corrections"
+// @Success 200 {object} response.DataResponse{data=ResponseCertificate} "Successful reissue"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 409 {object} response.MessageResponse "Certificate already revoked"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates/{id}/reissue [post]
+func ReissueCertificate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingReissueCertificate
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	original, err := model_certificate.GetCertificateByID(initializer.DB, company.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	if original.IsRevoked() {
+		errMsg := "Certificate already revoked"
+		logger.Log.Error(errMsg, original.ID)
+		return ctx.Status(fiber.StatusConflict).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	version, err := model_template.GetVersionByID(initializer.DB, original.VersionID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if body.UseLatestVersion {
+		template, err := model_template.GetTemplateByID(initializer.DB, company.ID, original.TemplateID)
+		if err != nil {
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+		}
+		if version, err = model_template.GetVersion(initializer.DB, template.ID, template.LatestVersion); err != nil {
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+		}
+	}
+
+	// Start from the original values the version still has, then apply the corrections
+	values := make(map[string]interface{})
+	for key, value := range original.ValueMap() {
+		for _, field := range version.Fields {
+			if field.Key == key {
+				values[key] = value
+				break
+			}
+		}
+	}
+	for key, value := range body.Values {
+		values[key] = value
+	}
+
+	normalized, err := version.NormalizeValues(values)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	email := original.RecipientEmail
+	if body.RecipientEmail != "" {
+		email = body.RecipientEmail
+	}
+
+	tx := initializer.DB.Begin()
+
+	certificate, err := IssueCertificate(tx, company.ID, accountID, version, email, normalized, &original.ID)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// Fails when the original was revoked concurrently
+	if err := model_certificate.RevokeCertificate(tx, original.ID, body.Reason, &certificate.ID, accountID); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusConflict).JSON(response.ErrorResponseBody("Certificate already revoked"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	InvalidateVerification(original.Code)
+	InvalidateStatusList(original)
+
+	logger.Log.Info("Certificate ", original.ID, " reissued as ", certificate.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseCertificate{
+		Certificate: *certificate,
+		Version:     version.Version,
+		Fields:      version.Fields,
+	}, "Successfully reissued"))
+}
@@ -0,0 +1,81 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_certificate "certification/model/certificate"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingRevokeCertificate struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// @Summary Revoke Certificate
+// @Description Revoke a certificate, it stays visible with its revocation reason. The revocation is published in the
+// @Description status list of its credential and anchored on-chain when the certificate is.
+// @Tags Certificate
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Certificate ID"
+// @Param IncomingRevokeCertificate body IncomingRevokeCertificate true "Reason of the revocation"
+// @Success 200 {object} response.MessageResponse "Successful revoke"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 409 {object} response.MessageResponse "Certificate already revoked"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates/{id}/revoke [post]
+func RevokeCertificate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingRevokeCertificate
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByID(initializer.DB, company.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	tx := initializer.DB.Begin()
+
+	if err := model_certificate.RevokeCertificate(tx, certificate.ID, body.Reason, nil, accountID); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusConflict).JSON(response.ErrorResponseBody("Certificate already revoked"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	InvalidateVerification(certificate.Code)
+	InvalidateStatusList(certificate)
+
+	logger.Log.Info("Certificate revoked ", certificate.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody("Successfully revoked"))
+}
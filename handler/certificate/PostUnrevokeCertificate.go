@@ -0,0 +1,98 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_anchor "certification/model/anchor"
+	model_certificate "certification/model/certificate"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// @Summary Unrevoke Certificate
+// @Description Make a revoked certificate valid again, e.g. after a revocation by mistake. Certificates revoked by a reissue cannot be unrevoked.
+// @Tags Certificate
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Certificate ID"
+// @Param IncomingRevokeCertificate body IncomingRevokeCertificate true "Reason of the unrevocation"
+// @Success 200 {object} response.MessageResponse "Successful unrevoke"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Certificate not found"
+// @Failure 409 {object} response.MessageResponse "Certificate is not revoked or was reissued"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /certificates/{id}/unrevoke [post]
+func UnrevokeCertificate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var certificateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &certificateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingRevokeCertificate
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	certificate, err := model_certificate.GetCertificateByID(initializer.DB, company.ID, certificateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Certificate not found"))
+	}
+
+	tx := initializer.DB.Begin()
+
+	if err := model_certificate.UnrevokeCertificate(tx, certificate.ID, anchoredStatus(tx, certificate.ID), body.Reason, accountID); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusConflict).JSON(response.ErrorResponseBody("Certificate is not revoked or was reissued"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	InvalidateVerification(certificate.Code)
+	InvalidateStatusList(certificate)
+
+	logger.Log.Info("Certificate unrevoked ", certificate.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody("Successfully unrevoked"))
+}
+
+// Status of an unrevoked certificate, where its anchoring stands. A certificate revoked
+// during its anchoring is not moved by the batch, so it is taken from the batch.
+func anchoredStatus(db *gorm.DB, certificateID uuid.UUID) constant.Status {
+	anchor, batch, err := model_anchor.GetCertificateAnchor(db, certificateID)
+	if err != nil {
+		return constant.OFFCHAIN
+	}
+
+	switch batch.Status {
+	case constant.ONCHAIN:
+		return constant.ONCHAIN
+	case constant.PENDING, constant.SUBMITTED:
+		return constant.SUBMITTED
+	}
+	if anchor.Attempts >= constant.ANCHOR_MAX_ATTEMPTS {
+		return constant.FAILED
+	}
+	return constant.OFFCHAIN
+}
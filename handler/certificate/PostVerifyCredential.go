@@ -0,0 +1,90 @@
+package handler_certificate
+
+import (
+	"certification/constant"
+	"certification/credential"
+	"certification/database"
+	"certification/logger"
+	model_certificate "certification/model/certificate"
+	model_company "certification/model/company"
+	"certification/response"
+	"certification/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type IncomingCredential struct {
+	Credential string `json:"credential" validate:"required"` // VC-JWT
+}
+
+type ResponseCredentialVerification struct {
+	Valid      bool                   `json:"valid"` // false once the certificate is revoked or deleted
+	Status     constant.Status        `json:"status"`
+	RevokedAt  *time.Time             `json:"revoked_at"`
+	KeyID      string                 `json:"kid"` // verification method of the issuer which signed the credential
+	Credential *credential.Credential `json:"credential"`
+}
+
+// @Summary Verify Credential
+// @Description Check the signature of a Verifiable Credential issued by a company of the platform and the current status of its certificate
+// @Tags Verification
+// @Accept json
+// @Produce json
+// @Param IncomingCredential body IncomingCredential true "VC-JWT"
+// @Success 200 {object} response.DataResponse{data=ResponseCredentialVerification} "Signature is valid"
+// @Failure 400 {object} response.MessageResponse "Invalid credential"
+// @Failure 429 {object} response.MessageResponse "Too many requests"
+// @Router /credentials/verify [post]
+func VerifyCredential(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingCredential
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	vc, keyID, err := credential.Parse(body.Credential, issuerKeyFunc(initializer.DB))
+	if err != nil {
+		errMsg := "Invalid credential: " + err.Error()
+		logger.Log.Info(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	result := ResponseCredentialVerification{
+		Status:     constant.DELETED,
+		KeyID:      keyID,
+		Credential: vc,
+	}
+
+	companyID, _ := credential.CompanyIDFromDID(vc.Issuer.ID)
+	certificateID, err := credential.CertificateIDFromURN(vc.ID)
+	if err == nil {
+		if certificate, err := model_certificate.GetCertificateByID(initializer.DB, companyID, certificateID); err == nil {
+			result.Valid = !certificate.IsRevoked()
+			result.Status = certificate.Status
+			result.RevokedAt = certificate.RevokedAt
+		}
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(result, "Successfully verified"))
+}
+
+// Public keys of the companies, retired keys still verify the credentials they signed
+func issuerKeyFunc(db *gorm.DB) credential.KeyFunc {
+	return func(issuer string, keyID string) (string, interface{}, error) {
+		companyID, err := credential.CompanyIDFromDID(issuer)
+		if err != nil {
+			return "", nil, err
+		}
+		key, err := model_company.GetKeyByKeyID(db, companyID, keyID)
+		if err != nil {
+			return "", nil, err
+		}
+		publicKey, err := credential.ParsePublicKey(key.Algorithm, key.PublicKey)
+		if err != nil {
+			return "", nil, err
+		}
+		return key.Algorithm, publicKey, nil
+	}
+}
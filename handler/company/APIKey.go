@@ -0,0 +1,63 @@
+package handler_company
+
+import (
+	"certification/constant"
+	handler_auth "certification/handler/auth"
+	model_apikey "certification/model/apikey"
+	model_permission "certification/model/permission"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IncomingAPIKeyScope struct {
+	ModuleID     uint `json:"module_id" validate:"required"`
+	ReadAccess   bool `json:"read_access"`
+	WriteAccess  bool `json:"write_access"`
+	DeleteAccess bool `json:"delete_access"`
+}
+
+type ResponseAPIKey struct {
+	ID       uuid.UUID  `json:"id"`
+	Name     string     `json:"name"`
+	Key      string     `json:"key"` // only returned on create and rotate
+	Prefix   string     `json:"prefix"`
+	ExpireAt *time.Time `json:"expire_at"`
+}
+
+// A key cannot be granted more than the account creating it has on the module
+func ValidateScopes(ctx *fiber.Ctx, db *gorm.DB, scopes []IncomingAPIKeyScope) error {
+	for _, scope := range scopes {
+		if scope.WriteAccess && !scope.ReadAccess {
+			return errors.New(constant.ErrorWriteAccess)
+		}
+		if scope.DeleteAccess && !scope.ReadAccess {
+			return errors.New(constant.ErrorDeleteAccess)
+		}
+		if !model_permission.IsModuleExist(db, scope.ModuleID) {
+			return fmt.Errorf("%s%d", constant.ErrorInvalidID, scope.ModuleID)
+		}
+
+		access, _ := ctx.Locals(strconv.FormatUint(uint64(scope.ModuleID), 10)).(handler_auth.Access)
+		if (scope.ReadAccess && !access.ReadAccess) ||
+			(scope.WriteAccess && !access.WriteAccess) ||
+			(scope.DeleteAccess && !access.DeleteAccess) {
+			return fmt.Errorf("scope exceeds your own permission on module %d", scope.ModuleID)
+		}
+	}
+	return nil
+}
+
+func (s IncomingAPIKeyScope) ToScope() model_apikey.APIKeyScope {
+	return model_apikey.APIKeyScope{
+		ModuleID:     s.ModuleID,
+		ReadAccess:   s.ReadAccess,
+		WriteAccess:  s.WriteAccess,
+		DeleteAccess: s.DeleteAccess,
+	}
+}
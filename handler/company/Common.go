@@ -0,0 +1,60 @@
+package handler_company
+
+import (
+	"certification/constant"
+	model_company "certification/model/company"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IncomingMemberRole struct {
+	Role string `json:"role" validate:"required,oneof=owner admin"`
+}
+
+// Membership of the logged in account. Owners registered before memberships existed
+// only have Company.AccountID set, they are treated as an active owner.
+func GetCallerMembership(db *gorm.DB, accountID uuid.UUID) (*model_company.CompanyMember, error) {
+	member, err := model_company.GetActiveMemberByAccountID(db, accountID)
+	if err == nil {
+		return member, nil
+	}
+
+	company, err := model_company.GetCompanyByAccountID(db, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model_company.CompanyMember{
+		CompanyID: company.ID,
+		AccountID: &accountID,
+		Role:      constant.PROJECT_OWNER,
+		Status:    constant.ACTIVE,
+	}, nil
+}
+
+func IsOwner(member *model_company.CompanyMember) bool {
+	return member.Role == constant.PROJECT_OWNER
+}
+
+func CanManageMembers(member *model_company.CompanyMember) bool {
+	return member.Role == constant.PROJECT_OWNER || member.Role == constant.PROJECT_ADMIN
+}
+
+// An owner cannot be demoted or removed when no other active owner is left
+func IsLastOwner(db *gorm.DB, member *model_company.CompanyMember) bool {
+	return member.Role == constant.PROJECT_OWNER &&
+		member.Status == constant.ACTIVE &&
+		model_company.CountOwners(db, member.CompanyID) <= 1
+}
+
+// The company of the caller. For company accounts and API keys the profile ID is the company ID.
+func GetCallerCompany(ctx *fiber.Ctx, db *gorm.DB) (*model_company.Company, error) {
+	profileID, ok := ctx.Locals("profile_id").(uuid.UUID)
+	if !ok {
+		return nil, errors.New("Failed to extract profile ID from Locals")
+	}
+	return model_company.GetCompanyByID(db, profileID)
+}
@@ -0,0 +1,55 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_apikey "certification/model/apikey"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Revoke API Key
+// @Description Revoke an API key, it cannot be used or rotated anymore
+// @Tags Company
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} response.MessageResponse "Successful revoke"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "API key not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/api-keys/{id} [delete]
+func RevokeAPIKey(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var keyID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &keyID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	caller, err := GetCallerMembership(initializer.DB, accountID)
+	if err != nil || !CanManageMembers(caller) {
+		logger.Log.Error("Not allowed to manage API keys: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	apiKey, err := model_apikey.GetAPIKeyByID(initializer.DB, caller.CompanyID, keyID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("API key not found"))
+	}
+
+	if err := model_apikey.RevokeAPIKey(initializer.DB, apiKey.ID); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("API key revoked ", apiKey.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody("Successfully revoked"))
+}
@@ -0,0 +1,95 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_auth "certification/handler/auth"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_company "certification/model/company"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Remove Member
+// @Description Remove a member or cancel an invitation. Admins can only remove admins
+// @Tags Company
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {object} response.MessageResponse "Successful remove"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Member not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/members/{id} [delete]
+func DeleteMember(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var memberID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &memberID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	caller, err := GetCallerMembership(initializer.DB, accountID)
+	if err != nil || !CanManageMembers(caller) {
+		logger.Log.Error("Not allowed to remove members: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	member, err := model_company.GetMemberByID(initializer.DB, caller.CompanyID, memberID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Member not found"))
+	}
+
+	if member.Role == constant.PROJECT_OWNER && !IsOwner(caller) {
+		logger.Log.Error("Admin cannot remove an owner: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	if IsLastOwner(initializer.DB, member) {
+		errMsg := "The company must keep at least one owner"
+		logger.Log.Error(errMsg, member.CompanyID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	tx := initializer.DB.Begin()
+	defer utils.DiscardPendingSessions(tx)
+
+	err = model_company.UpdateMemberStatus(tx, member.ID, constant.DELETED)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	// The account only exists for this company, so it cannot log in anymore
+	if member.AccountID != nil {
+		err = model_account.UpdateAccountStatus(tx, *member.AccountID, constant.INACTIVE)
+		if err != nil {
+			tx.Rollback()
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+		}
+	}
+
+	if err := utils.CommitTransaction(tx); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if member.AccountID != nil {
+		if err := handler_auth.RevokeSessions(*member.AccountID); err != nil {
+			logger.Log.Errorf("unable to revoke sessions for %s in Redis", *member.AccountID)
+		}
+	}
+
+	logger.Log.Info(constant.SuccessDeleteRecord, member.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessDeleteRecord))
+}
@@ -0,0 +1,31 @@
+package handler_company
+
+import (
+	"certification/database"
+	model_company "certification/model/company"
+	"certification/template"
+	"certification/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Accept Invitation Page
+// @Description Page of the invitation link sent by email, it asks for a password and submits it with the token to POST /company/members/accept
+// @Tags Company
+// @Produce html
+// @Param token query string true "Invitation token"
+// @Success 200 {string} string "Invitation page"
+// @Router /company/members/accept [get]
+func GetAcceptInvitation(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	token := ctx.Query("token")
+
+	valid := false
+	if member, err := model_company.GetMemberByInviteToken(initializer.DB, utils.HashToken(token)); err == nil {
+		valid = time.Now().Before(member.InviteExpireAt)
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return ctx.Status(fiber.StatusOK).SendString(template.TemplateAcceptInvitationPage(token, valid))
+}
@@ -0,0 +1,51 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/credential"
+	"certification/database"
+	"certification/logger"
+	model_company "certification/model/company"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Issuer DID Document
+// @Description did:web document of a company, lists the keys its verifiable credentials are signed with
+// @Tags Verification
+// @Produce json
+// @Param id path string true "Company ID"
+// @Success 200 {object} credential.DIDDocument "DID document"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Issuer not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /issuers/{id}/did.json [get]
+func GetDIDDocument(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var companyID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &companyID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	keys, err := model_company.GetKeysByCompanyID(initializer.DB, companyID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	if len(keys) == 0 {
+		logger.Log.Info("Issuer not found ", companyID)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Issuer not found"))
+	}
+
+	document, err := credential.NewDIDDocument(companyID, keys)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return ctx.Status(fiber.StatusOK).JSON(document, "application/did+json")
+}
@@ -0,0 +1,38 @@
+package handler_company
+
+import (
+	"certification/database"
+	"certification/logger"
+	model_company "certification/model/company"
+	"certification/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Get Members
+// @Description List the members and pending invitations of the company
+// @Tags Company
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]model_company.CompanyMember} "Successful get members"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/members [get]
+func GetMembers(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	caller, err := GetCallerMembership(initializer.DB, accountID)
+	if err != nil {
+		logger.Log.Error("Account is not a company member: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	members, err := model_company.GetMembersByCompanyID(initializer.DB, caller.CompanyID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(members, "Successfully get members"))
+}
@@ -0,0 +1,38 @@
+package handler_company
+
+import (
+	"certification/database"
+	"certification/logger"
+	model_company "certification/model/company"
+	"certification/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Get Signing Keys
+// @Description List the keys the company signs its verifiable credentials with, the private keys are never returned
+// @Tags Company
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]model_company.CompanyKey} "Successful get signing keys"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/signing-keys [get]
+func GetSigningKeys(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	caller, err := GetCallerMembership(initializer.DB, accountID)
+	if err != nil || !CanManageMembers(caller) {
+		logger.Log.Error("Not allowed to manage signing keys: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	keys, err := model_company.GetKeysByCompanyID(initializer.DB, caller.CompanyID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(keys, "Successfully get signing keys"))
+}
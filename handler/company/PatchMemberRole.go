@@ -0,0 +1,69 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_company "certification/model/company"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Update Member Role
+// @Description Change the role of a member, only owners can change roles
+// @Tags Company
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param IncomingMemberRole body IncomingMemberRole true "New role"
+// @Success 200 {object} response.MessageResponse "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Member not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/members/{id} [patch]
+func UpdateMemberRole(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var memberID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &memberID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingMemberRole
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	caller, err := GetCallerMembership(initializer.DB, accountID)
+	if err != nil || !IsOwner(caller) {
+		logger.Log.Error("Not allowed to change roles: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	member, err := model_company.GetMemberByID(initializer.DB, caller.CompanyID, memberID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Member not found"))
+	}
+
+	if body.Role != constant.PROJECT_OWNER && IsLastOwner(initializer.DB, member) {
+		errMsg := "The company must keep at least one owner"
+		logger.Log.Error(errMsg, member.CompanyID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	if err := model_company.UpdateMemberRole(initializer.DB, member.ID, body.Role); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info(constant.SuccessUpdateRecord, member.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessUpdateRecord))
+}
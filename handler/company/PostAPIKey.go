@@ -0,0 +1,93 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_apikey "certification/model/apikey"
+	"certification/response"
+	"certification/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingAPIKey struct {
+	Name     string                `json:"name" validate:"required"`
+	ExpireAt *time.Time            `json:"expire_at"` // null never expires
+	Scopes   []IncomingAPIKeyScope `json:"scopes" validate:"required,min=1,dive"`
+}
+
+// @Summary Create API Key
+// @Description Create a company API key, the key is only returned once
+// @Tags Company
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingAPIKey body IncomingAPIKey true "Name, expiry and scopes"
+// @Param X-OTP-Token header string true "Step-up token from /auth/otp/verify"
+// @Success 200 {object} response.DataResponse{data=ResponseAPIKey} "Successful create"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/api-keys [post]
+func CreateAPIKey(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingAPIKey
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	caller, err := GetCallerMembership(initializer.DB, accountID)
+	if err != nil || !CanManageMembers(caller) {
+		logger.Log.Error("Not allowed to manage API keys: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	if body.ExpireAt != nil && body.ExpireAt.Before(time.Now()) {
+		errMsg := "Expiry must be in the future"
+		logger.Log.Error(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	if err := ValidateScopes(ctx, initializer.DB, body.Scopes); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody("Error in generating API key"))
+	}
+
+	apiKey := model_apikey.APIKey{
+		CompanyID: caller.CompanyID,
+		CreatedBy: accountID,
+		Name:      body.Name,
+		Prefix:    prefix,
+		Key:       hash,
+		Status:    constant.ACTIVE,
+		ExpireAt:  body.ExpireAt,
+	}
+	for _, scope := range body.Scopes {
+		apiKey.Scopes = append(apiKey.Scopes, scope.ToScope())
+	}
+
+	if err := initializer.DB.Create(&apiKey).Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("API key created ", apiKey.ID, " for ", caller.CompanyID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseAPIKey{
+		ID:       apiKey.ID,
+		Name:     apiKey.Name,
+		Key:      key,
+		Prefix:   prefix,
+		ExpireAt: apiKey.ExpireAt,
+	}, constant.SuccessCreateRecord))
+}
@@ -0,0 +1,97 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_auth "certification/handler/auth"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_company "certification/model/company"
+	"certification/response"
+	"certification/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingAcceptInvitation struct {
+	Token    string `json:"token" form:"token" validate:"required"`
+	Password string `json:"password" form:"password" validate:"required,min=8"`
+}
+
+// @Summary Accept Invitation
+// @Description Accept a company invitation and create the linked company account
+// @Tags Company
+// @Accept json,x-www-form-urlencoded
+// @Produce json
+// @Param IncomingAcceptInvitation body IncomingAcceptInvitation true "Invitation token and password"
+// @Success 200 {object} response.DataResponse{data=handler_auth.ResponseSignUp} "Successful accept"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/members/accept [post]
+func AcceptInvitation(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingAcceptInvitation
+	// The page of the invitation link posts a form, API clients post JSON
+	if err := utils.ValidateFormParser(&body, ctx); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	member, err := model_company.GetMemberByInviteToken(initializer.DB, utils.HashToken(body.Token))
+	if err != nil || time.Now().After(member.InviteExpireAt) {
+		logger.Log.Error("Invitation is invalid or expired")
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidToken))
+	}
+
+	if _, err := model_account.GetAccountByEmail(initializer.DB, member.Email); err == nil {
+		logger.Log.Error("Email already exists: ", member.Email)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Email already exists"))
+	}
+
+	hashPassword, err := handler_auth.HashPassword(body.Password)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Unable to hash password"))
+	}
+
+	// The invitation link proves the email, so the account is active right away
+	accountID := uuid.New()
+	account := model_account.Account{
+		ID:       accountID,
+		Email:    member.Email,
+		Password: hashPassword,
+		Role:     constant.ROLE_COMPANY,
+		Status:   constant.ACTIVE,
+	}
+
+	tx := initializer.DB.Begin()
+	defer utils.DiscardPendingSessions(tx)
+
+	err = tx.Create(&account).Error
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	err = tx.Model(member).Updates(map[string]interface{}{
+		"account_id":   accountID,
+		"status":       constant.ACTIVE,
+		"invite_token": "",
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := utils.CommitTransaction(tx); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Invitation accepted ", member.ID, " by ", accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(handler_auth.ResponseSignUp{AccountID: accountID}, constant.SuccessSignUp))
+}
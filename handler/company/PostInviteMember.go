@@ -0,0 +1,97 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_auth "certification/handler/auth"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_company "certification/model/company"
+	"certification/response"
+	"certification/template"
+	"certification/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingInviteMember struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=owner admin"`
+}
+
+// @Summary Invite Member
+// @Description Invite a staff member to the company by email
+// @Tags Company
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingInviteMember body IncomingInviteMember true "Member email and role"
+// @Success 200 {object} response.DataResponse{data=response.ResponseCreated} "Successful invite"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/members [post]
+func InviteMember(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingInviteMember
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	caller, err := GetCallerMembership(initializer.DB, accountID)
+	if err != nil || !CanManageMembers(caller) || (body.Role == constant.PROJECT_OWNER && !IsOwner(caller)) {
+		logger.Log.Error("Not allowed to invite members: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	if _, err := model_account.GetAccountByEmail(initializer.DB, body.Email); err == nil {
+		logger.Log.Error("Email already exists: ", body.Email)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Email already exists"))
+	}
+
+	if _, err := model_company.GetMemberByEmail(initializer.DB, caller.CompanyID, body.Email); err == nil {
+		logger.Log.Error("Email already invited: ", body.Email)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Email already invited"))
+	}
+
+	company, err := model_company.GetCompanyByID(initializer.DB, caller.CompanyID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	token, hash, err := utils.GenerateHashedToken()
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody("Error in generating token"))
+	}
+
+	member := model_company.CompanyMember{
+		CompanyID:      company.ID,
+		Email:          body.Email,
+		Role:           body.Role,
+		Status:         constant.PENDING,
+		InvitedBy:      &accountID,
+		InviteToken:    hash,
+		InviteExpireAt: time.Now().Add(constant.INVITATION_EXPIRY),
+	}
+
+	if err := initializer.DB.Create(&member).Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	mjmlTemplate := template.TemplateCompanyInvitation(initializer, body.Email, token)
+	err = handler_auth.SendTemplateEmail(mjmlTemplate, "You are invited to join "+company.Name+" on CertFirst", body.Email)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusServiceUnavailable).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Member invited ", member.ID, " to ", company.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(response.ResponseCreated{ID: member.ID.String()}, constant.SuccessCreateRecord))
+}
@@ -0,0 +1,68 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_apikey "certification/model/apikey"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Rotate API Key
+// @Description Replace the secret of an API key keeping its scopes, the old key stops working
+// @Tags Company
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "API key ID"
+// @Param X-OTP-Token header string true "Step-up token from /auth/otp/verify"
+// @Success 200 {object} response.DataResponse{data=ResponseAPIKey} "Successful rotate"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "API key not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/api-keys/{id}/rotate [post]
+func RotateAPIKey(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var keyID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &keyID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	caller, err := GetCallerMembership(initializer.DB, accountID)
+	if err != nil || !CanManageMembers(caller) {
+		logger.Log.Error("Not allowed to manage API keys: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	apiKey, err := model_apikey.GetAPIKeyByID(initializer.DB, caller.CompanyID, keyID)
+	if err != nil || apiKey.Status != constant.ACTIVE {
+		logger.Log.Error("API key not found: ", keyID)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("API key not found"))
+	}
+
+	key, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody("Error in generating API key"))
+	}
+
+	if err := model_apikey.RotateAPIKey(initializer.DB, apiKey.ID, prefix, hash); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("API key rotated ", apiKey.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseAPIKey{
+		ID:       apiKey.ID,
+		Name:     apiKey.Name,
+		Key:      key,
+		Prefix:   prefix,
+		ExpireAt: apiKey.ExpireAt,
+	}, constant.SuccessUpdateRecord))
+}
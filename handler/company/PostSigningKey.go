@@ -0,0 +1,51 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Rotate Signing Key
+// @Description Create a new Ed25519 or secp256k1 key to sign the verifiable credentials of the company.
+// @Description The previous key is retired, it stays in the DID document so the credentials it signed remain verifiable.
+// @Tags Company
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingSigningKey body IncomingSigningKey true "Key algorithm"
+// @Param X-OTP-Token header string true "Step-up token from /auth/otp/verify"
+// @Success 200 {object} response.DataResponse{data=model_company.CompanyKey} "Successful create"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /company/signing-keys [post]
+func RotateSigningKey(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingSigningKey
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	caller, err := GetCallerMembership(initializer.DB, accountID)
+	if err != nil || !IsOwner(caller) {
+		logger.Log.Error("Not allowed to rotate signing keys: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	key, err := CreateSigningKey(initializer.DB, caller.CompanyID, body.Algorithm)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Signing key ", key.KeyID, " created for ", caller.CompanyID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(key, constant.SuccessCreateRecord))
+}
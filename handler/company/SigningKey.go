@@ -0,0 +1,75 @@
+package handler_company
+
+import (
+	"certification/constant"
+	"certification/credential"
+	model_company "certification/model/company"
+	"certification/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IncomingSigningKey struct {
+	Algorithm string `json:"algorithm" validate:"required,oneof=Ed25519 secp256k1"`
+}
+
+// Create the active signing key of the company, the previous key is retired
+func CreateSigningKey(db *gorm.DB, companyID uuid.UUID, algorithm string) (*model_company.CompanyKey, error) {
+	privateKey, publicKey, err := credential.GenerateKey(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := utils.EncryptString(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+
+	key := model_company.CompanyKey{
+		CompanyID:  companyID,
+		KeyID:      hex.EncodeToString(raw),
+		Algorithm:  algorithm,
+		PublicKey:  publicKey,
+		PrivateKey: encrypted,
+	}
+
+	tx := db.Begin()
+	if err := model_company.CreateKey(tx, &key); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Active signing key of the company with its decrypted private key,
+// a key of constant.ISSUER_KEY_DEFAULT is created for companies without one
+func GetSigningKey(db *gorm.DB, companyID uuid.UUID) (*model_company.CompanyKey, interface{}, error) {
+	key, err := model_company.GetActiveKey(db, companyID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		key, err = CreateSigningKey(db, companyID, constant.ISSUER_KEY_DEFAULT)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decrypted, err := utils.DecryptString(key.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey, err := credential.ParsePrivateKey(key.Algorithm, decrypted)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, privateKey, nil
+}
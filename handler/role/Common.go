@@ -0,0 +1,56 @@
+package handler_role
+
+import (
+	"certification/constant"
+	model_permission "certification/model/permission"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type IncomingPermission struct {
+	ModuleID     uint `json:"module_id" validate:"required"`
+	ModuleAccess bool `json:"module_access"`
+	ReadAccess   bool `json:"read_access"`
+	WriteAccess  bool `json:"write_access"`
+	DeleteAccess bool `json:"delete_access"`
+}
+
+type IncomingRolePermissions struct {
+	Permissions []IncomingPermission `json:"permissions" validate:"required,dive"`
+}
+
+// Write and delete both imply read, the same rule middleware.ValidateModulePermission checks
+func ValidatePermissions(db *gorm.DB, permissions []IncomingPermission) error {
+	for _, p := range permissions {
+		if p.WriteAccess && !p.ReadAccess {
+			return errors.New(constant.ErrorWriteAccess)
+		}
+		if p.DeleteAccess && !p.ReadAccess {
+			return errors.New(constant.ErrorDeleteAccess)
+		}
+		if !model_permission.IsModuleExist(db, p.ModuleID) {
+			return fmt.Errorf("%s%d", constant.ErrorInvalidID, p.ModuleID)
+		}
+	}
+	return nil
+}
+
+func (p IncomingPermission) ToPermission(roleID uint) model_permission.Permission {
+	return model_permission.Permission{
+		RoleID:       roleID,
+		ModuleID:     p.ModuleID,
+		ModuleAccess: p.ModuleAccess || p.ReadAccess,
+		ReadAccess:   p.ReadAccess,
+		WriteAccess:  p.WriteAccess,
+		DeleteAccess: p.DeleteAccess,
+	}
+}
+
+// Default roles are looked up by name at login, they cannot be renamed or deleted
+func IsDefaultRole(name string) bool {
+	return name == constant.ROLE_NAME_ADMIN ||
+		name == string(constant.ROLE_COMPANY) ||
+		name == string(constant.ROLE_USER)
+}
@@ -0,0 +1,67 @@
+package handler_role
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_permission "certification/model/permission"
+	"certification/response"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Delete Role
+// @Description Delete a role that is not a default role and not assigned to any account
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} response.MessageResponse "Successful delete"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Role not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /roles/{id} [delete]
+func DeleteRole(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	role, err := model_permission.GetRoleByID(initializer.DB, uint(id))
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Role not found"))
+	}
+
+	if IsDefaultRole(role.Name) {
+		errMsg := "Default roles cannot be deleted"
+		logger.Log.Error(errMsg, role.ID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	if model_account.CountAccountsByRoleID(initializer.DB, role.ID) > 0 {
+		errMsg := "Role is still assigned to accounts"
+		logger.Log.Error(errMsg, role.ID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	tx := initializer.DB.Begin()
+
+	if err := model_permission.DeleteRole(tx, role.ID); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(constant.ErrorDeleteRecord))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(constant.ErrorDeleteRecord))
+	}
+
+	logger.Log.Info(constant.SuccessDeleteRecord, role.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessDeleteRecord))
+}
@@ -0,0 +1,75 @@
+package handler_role
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_permission "certification/model/permission"
+	"certification/response"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get Roles
+// @Description List the permission roles with their module grants
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]model_permission.Role} "Successful get roles"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /roles [get]
+func GetRoles(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	roles, err := model_permission.GetRoles(initializer.DB)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(roles, "Successfully get roles"))
+}
+
+// @Summary Get Role
+// @Description Get a permission role with its module grants
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Role ID"
+// @Success 200 {object} response.DataResponse{data=model_permission.Role} "Successful get role"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Role not found"
+// @Router /roles/{id} [get]
+func GetRole(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	role, err := model_permission.GetRoleByID(initializer.DB, uint(id))
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Role not found"))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(role, "Successfully get role"))
+}
+
+// @Summary Get Modules
+// @Description List the modules permissions can be granted on
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]model_permission.Module} "Successful get modules"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /roles/modules [get]
+func GetModules(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	modules, err := model_permission.GetModules(initializer.DB)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(modules, "Successfully get modules"))
+}
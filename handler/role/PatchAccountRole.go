@@ -0,0 +1,65 @@
+package handler_role
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_permission "certification/model/permission"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingAccountRole struct {
+	RoleID *uint `json:"role_id"` // null restores the default role of the account
+}
+
+// @Summary Assign Account Role
+// @Description Assign a permission role to an account, takes effect on the next login
+// @Tags Role
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param IncomingAccountRole body IncomingAccountRole true "Role ID"
+// @Success 200 {object} response.MessageResponse "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Account or role not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /roles/accounts/{id} [patch]
+func UpdateAccountRole(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var accountID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &accountID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingAccountRole
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if _, err := model_account.GetAccountByID(initializer.DB, accountID); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Account not found"))
+	}
+
+	if body.RoleID != nil {
+		if _, err := model_permission.GetRoleByID(initializer.DB, *body.RoleID); err != nil {
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Role not found"))
+		}
+	}
+
+	if err := model_account.UpdateAccountRoleID(initializer.DB, accountID, body.RoleID); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info(constant.SuccessUpdateRecord, accountID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessUpdateRecord))
+}
@@ -0,0 +1,70 @@
+package handler_role
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_permission "certification/model/permission"
+	"certification/response"
+	"certification/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IncomingUpdateRole struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+// @Summary Update Role
+// @Description Update the name and description of a role, default roles keep their name
+// @Tags Role
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param IncomingUpdateRole body IncomingUpdateRole true "Role name and description"
+// @Success 200 {object} response.MessageResponse "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Role not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /roles/{id} [patch]
+func UpdateRole(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingUpdateRole
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	role, err := model_permission.GetRoleByID(initializer.DB, uint(id))
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Role not found"))
+	}
+
+	if IsDefaultRole(role.Name) && body.Name != role.Name {
+		errMsg := "Default roles cannot be renamed"
+		logger.Log.Error(errMsg, role.ID)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	if other, err := model_permission.GetRoleByName(initializer.DB, body.Name); err == nil && other.ID != role.ID {
+		logger.Log.Error(constant.ErrorDuplicateEntry, body.Name)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorDuplicateEntry + body.Name))
+	}
+
+	if err := model_permission.UpdateRole(initializer.DB, role.ID, body.Name, body.Description); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info(constant.SuccessUpdateRecord, role.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessUpdateRecord))
+}
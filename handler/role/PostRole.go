@@ -0,0 +1,64 @@
+package handler_role
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_permission "certification/model/permission"
+	"certification/response"
+	"certification/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IncomingRole struct {
+	Name        string               `json:"name" validate:"required"`
+	Description string               `json:"description"`
+	Permissions []IncomingPermission `json:"permissions" validate:"dive"`
+}
+
+// @Summary Create Role
+// @Description Create a permission role with its module grants
+// @Tags Role
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingRole body IncomingRole true "Role and grants"
+// @Success 200 {object} response.DataResponse{data=response.ResponseCreated} "Successful create"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /roles [post]
+func CreateRole(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	var body IncomingRole
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := ValidatePermissions(initializer.DB, body.Permissions); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if _, err := model_permission.GetRoleByName(initializer.DB, body.Name); err == nil {
+		logger.Log.Error(constant.ErrorDuplicateEntry, body.Name)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorDuplicateEntry + body.Name))
+	}
+
+	role := model_permission.Role{
+		Name:        body.Name,
+		Description: body.Description,
+	}
+	for _, p := range body.Permissions {
+		role.Permissions = append(role.Permissions, p.ToPermission(0))
+	}
+
+	if err := initializer.DB.Create(&role).Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info(constant.SuccessCreateRecord, role.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(response.ResponseCreated{ID: strconv.FormatUint(uint64(role.ID), 10)}, constant.SuccessCreateRecord))
+}
@@ -0,0 +1,105 @@
+package handler_role
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_permission "certification/model/permission"
+	"certification/response"
+	"certification/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Update Role Permissions
+// @Description Create or update the read/write/delete grants of a role per module
+// @Tags Role
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param IncomingRolePermissions body IncomingRolePermissions true "Module grants"
+// @Success 200 {object} response.MessageResponse "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 404 {object} response.MessageResponse "Role not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /roles/{id}/permissions [put]
+func UpdateRolePermissions(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingRolePermissions
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := ValidatePermissions(initializer.DB, body.Permissions); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	role, err := model_permission.GetRoleByID(initializer.DB, uint(id))
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Role not found"))
+	}
+
+	tx := initializer.DB.Begin()
+	defer utils.DiscardPendingSessions(tx)
+
+	for _, p := range body.Permissions {
+		permission := p.ToPermission(role.ID)
+		if err := model_permission.UpsertPermission(tx, &permission); err != nil {
+			tx.Rollback()
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+		}
+	}
+
+	if err := utils.CommitTransaction(tx); err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info(constant.SuccessUpdateRecord, role.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessUpdateRecord))
+}
+
+// @Summary Delete Role Permission
+// @Description Remove every grant of a role on a module
+// @Tags Role
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Role ID"
+// @Param module_id path int true "Module ID"
+// @Success 200 {object} response.MessageResponse "Successful delete"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /roles/{id}/permissions/{module_id} [delete]
+func DeleteRolePermission(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	id, err := strconv.ParseUint(ctx.Params("id"), 10, 32)
+	if err != nil {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	moduleID, err := strconv.ParseUint(ctx.Params("module_id"), 10, 32)
+	if err != nil {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("module_id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	if err := model_permission.DeletePermission(initializer.DB, uint(id), uint(moduleID)); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(constant.ErrorDeleteRecord))
+	}
+
+	logger.Log.Info(constant.SuccessDeleteRecord, id, moduleID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessDeleteRecord))
+}
@@ -0,0 +1,45 @@
+package handler_storage
+
+import (
+	"certification/logger"
+	"certification/response"
+	"certification/storage"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get File
+// @Description File of the local storage behind a presigned URL, the URL expires
+// @Tags Storage
+// @Produce octet-stream
+// @Param key path string true "File key"
+// @Param expires query int true "Expiry of the URL, unix seconds"
+// @Param signature query string true "Signature of the URL"
+// @Success 200 {file} file "File"
+// @Failure 403 {object} response.MessageResponse "Invalid or expired URL"
+// @Failure 404 {object} response.MessageResponse "File not found"
+// @Router /files/{key} [get]
+func GetFile(ctx *fiber.Ctx) error {
+	key := ctx.Params("*")
+	expires, _ := strconv.ParseInt(ctx.Query("expires"), 10, 64)
+	if !storage.VerifySignature(key, expires, ctx.Query("signature")) {
+		errMsg := "Invalid or expired URL"
+		logger.Log.Info(errMsg, " ", key)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	data, err := storage.Files.Get(key)
+	if err != nil {
+		logger.Log.Info(err, " ", key)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("File not found"))
+	}
+
+	// Uploaded files are served from the API origin, an SVG or HTML file must not run its scripts there
+	ctx.Set(fiber.HeaderContentType, storage.ContentType(data))
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	ctx.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; sandbox")
+	ctx.Set(fiber.HeaderContentDisposition, "attachment")
+	ctx.Set(fiber.HeaderCacheControl, "private, max-age=60")
+	return ctx.Status(fiber.StatusOK).Send(data)
+}
@@ -0,0 +1,136 @@
+package handler_template
+
+import (
+	"certification/constant"
+	model_template "certification/model/template"
+	"certification/render"
+	"certification/utils"
+	"fmt"
+	"strings"
+)
+
+type IncomingField struct {
+	Key          string             `json:"key" validate:"required,max=64"`
+	Label        string             `json:"label" validate:"required"`
+	Type         constant.FieldType `json:"type" validate:"required,oneof=text number boolean date datetime"`
+	Required     bool               `json:"required"`
+	DefaultValue interface{}        `json:"default_value"`
+	MinLength    *int               `json:"min_length" validate:"omitempty,min=0"`
+	MaxLength    *int               `json:"max_length" validate:"omitempty,min=0"`
+	Min          *float64           `json:"min"`
+	Max          *float64           `json:"max"`
+	Pattern      string             `json:"pattern"`
+}
+
+type ResponseTemplate struct {
+	model_template.CertificateTemplate
+	Fields []model_template.TemplateField `json:"fields"`
+}
+
+// Convert the incoming fields to template fields in their given order,
+// checking that keys are unique and the rules and defaults fit the type
+func ToFields(incoming []IncomingField) ([]model_template.TemplateField, error) {
+	keys := make(map[string]bool, len(incoming))
+	fields := make([]model_template.TemplateField, 0, len(incoming))
+
+	for i, f := range incoming {
+		if keys[f.Key] {
+			return nil, fmt.Errorf("field %s is defined more than once", f.Key)
+		}
+		keys[f.Key] = true
+
+		field := model_template.TemplateField{
+			Key:       f.Key,
+			Position:  i,
+			Label:     f.Label,
+			Type:      f.Type,
+			Required:  f.Required,
+			MinLength: f.MinLength,
+			MaxLength: f.MaxLength,
+			Min:       f.Min,
+			Max:       f.Max,
+			Pattern:   f.Pattern,
+		}
+		if f.DefaultValue != nil {
+			value, err := field.Normalize(f.DefaultValue)
+			if err != nil {
+				return nil, fmt.Errorf("default value of %w", err)
+			}
+			field.DefaultValue = &value
+		}
+		if err := field.ValidateRules(); err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+type IncomingLayoutElement struct {
+	Type     constant.ElementType `json:"type" validate:"required,oneof=text qr"`
+	Text     string               `json:"text" validate:"max=1000"`
+	Font     string               `json:"font"`
+	FontSize float64              `json:"font_size" validate:"gte=0,lte=1000"`
+	Color    string               `json:"color"`
+	Align    constant.Align       `json:"align" validate:"omitempty,oneof=left center right"`
+	X        float64              `json:"x" validate:"gte=0"`
+	Y        float64              `json:"y" validate:"gte=0"`
+	Width    float64              `json:"width" validate:"gte=0"`
+}
+
+type ResponseLayout struct {
+	model_template.TemplateLayout
+	HasBackground bool     `json:"has_background"`
+	Fonts         []string `json:"fonts"` // fonts the elements can use
+}
+
+// Convert the incoming elements to layout elements in their given order, checking
+// that fonts and colors are known and that the elements are inside of the layout
+func ToElements(incoming []IncomingLayoutElement, width int, height int) ([]model_template.LayoutElement, error) {
+	elements := make([]model_template.LayoutElement, 0, len(incoming))
+
+	for i, e := range incoming {
+		if e.X > float64(width) || e.Y > float64(height) {
+			return nil, fmt.Errorf("element %d is outside of the layout", i)
+		}
+		if e.Color != "" {
+			if _, _, _, err := utils.HexToRGB(e.Color); err != nil {
+				return nil, fmt.Errorf("element %d has an invalid color %s", i, e.Color)
+			}
+		}
+
+		element := model_template.LayoutElement{
+			Position: i,
+			Type:     e.Type,
+			Text:     e.Text,
+			Font:     e.Font,
+			FontSize: e.FontSize,
+			Color:    e.Color,
+			Align:    e.Align,
+			X:        e.X,
+			Y:        e.Y,
+			Width:    e.Width,
+		}
+		if element.Align == "" {
+			element.Align = constant.ALIGN_LEFT
+		}
+
+		switch e.Type {
+		case constant.ELEMENT_TEXT:
+			if e.Text == "" || e.FontSize <= 0 {
+				return nil, fmt.Errorf("text element %d needs a text and a font size", i)
+			}
+			if e.Font == "" {
+				element.Font = render.DefaultFont
+			} else if !render.HasFont(e.Font) {
+				return nil, fmt.Errorf("element %d has an unknown font %s, use one of %s", i, e.Font, strings.Join(render.Fonts(), ", "))
+			}
+		case constant.ELEMENT_QR:
+			if e.Width < 21 {
+				return nil, fmt.Errorf("qr element %d needs a width of at least 21 pixels", i)
+			}
+		}
+		elements = append(elements, element)
+	}
+	return elements, nil
+}
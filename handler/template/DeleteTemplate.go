@@ -0,0 +1,56 @@
+package handler_template
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_template "certification/model/template"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Delete Template
+// @Description Delete a certificate template, certificates already issued keep their version
+// @Tags Template
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} response.MessageResponse "Successful delete"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /templates/{id} [delete]
+func DeleteTemplate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	if err := model_template.DeleteTemplate(initializer.DB, template.ID); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Template deleted ", template.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessDeleteRecord))
+}
@@ -0,0 +1,175 @@
+package handler_template
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_template "certification/model/template"
+	"certification/response"
+	"certification/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Get Templates
+// @Description List the certificate templates of the company
+// @Tags Template
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} response.DataResponse{data=[]model_template.CertificateTemplate} "Successful get templates"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /templates [get]
+func GetTemplates(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	templates, err := model_template.GetTemplatesByCompanyID(initializer.DB, company.ID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(templates, "Successfully get templates"))
+}
+
+// @Summary Get Template
+// @Description Get a certificate template with the fields of its latest version
+// @Tags Template
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} response.DataResponse{data=ResponseTemplate} "Successful get template"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Router /templates/{id} [get]
+func GetTemplate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	version, err := model_template.GetVersion(initializer.DB, template.ID, template.LatestVersion)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseTemplate{
+		CertificateTemplate: *template,
+		Fields:              version.Fields,
+	}, "Successfully get template"))
+}
+
+// @Summary Get Template Versions
+// @Description List the versions of a certificate template, newest first
+// @Tags Template
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} response.DataResponse{data=[]model_template.TemplateVersion} "Successful get versions"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Router /templates/{id}/versions [get]
+func GetTemplateVersions(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	versions, err := model_template.GetVersions(initializer.DB, template.ID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(versions, "Successfully get versions"))
+}
+
+// @Summary Get Template Version
+// @Description Get a version of a certificate template with its fields
+// @Tags Template
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param version path int true "Version number"
+// @Success 200 {object} response.DataResponse{data=model_template.TemplateVersion} "Successful get version"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Version not found"
+// @Router /templates/{id}/versions/{version} [get]
+func GetTemplateVersion(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	number, err := strconv.Atoi(ctx.Params("version"))
+	if err != nil {
+		logger.Log.Error("Invalid version ", ctx.Params("version"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("Invalid version"))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	version, err := model_template.GetVersion(initializer.DB, template.ID, number)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Version not found"))
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(version, "Successfully get version"))
+}
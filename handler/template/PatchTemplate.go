@@ -0,0 +1,69 @@
+package handler_template
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_template "certification/model/template"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingTemplateDetail struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+// @Summary Update Template
+// @Description Update the name and description of a certificate template, this does not create a new version
+// @Tags Template
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param IncomingTemplateDetail body IncomingTemplateDetail true "Name and description"
+// @Success 200 {object} response.MessageResponse "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /templates/{id} [patch]
+func UpdateTemplate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingTemplateDetail
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	if err := model_template.UpdateTemplate(initializer.DB, template.ID, body.Name, body.Description); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Template updated ", template.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.SuccessResponseBody(constant.SuccessUpdateRecord))
+}
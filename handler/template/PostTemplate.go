@@ -0,0 +1,87 @@
+package handler_template
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_template "certification/model/template"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingTemplate struct {
+	Name        string          `json:"name" validate:"required"`
+	Description string          `json:"description"`
+	Fields      []IncomingField `json:"fields" validate:"required,min=1,dive"`
+}
+
+// @Summary Create Template
+// @Description Create a certificate template with its first version of fields
+// @Tags Template
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param IncomingTemplate body IncomingTemplate true "Name, description and ordered fields"
+// @Success 200 {object} response.DataResponse{data=ResponseTemplate} "Successful create"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /templates [post]
+func CreateTemplate(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var body IncomingTemplate
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	fields, err := ToFields(body.Fields)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	tx := initializer.DB.Begin()
+
+	template := model_template.CertificateTemplate{
+		CompanyID:   company.ID,
+		CreatedBy:   accountID,
+		Name:        body.Name,
+		Description: body.Description,
+		Status:      constant.ACTIVE,
+	}
+	if err := tx.Create(&template).Error; err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	version, err := model_template.CreateVersion(tx, &template, accountID, fields)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Template created ", template.ID, " for ", company.ID)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseTemplate{
+		CertificateTemplate: template,
+		Fields:              version.Fields,
+	}, constant.SuccessCreateRecord))
+}
@@ -0,0 +1,129 @@
+package handler_template
+
+import (
+	"bytes"
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_template "certification/model/template"
+	"certification/render"
+	"certification/response"
+	"certification/utils"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// @Summary Update Template Background
+// @Description Upload the PNG or JPEG background image of the layout of a template, it is stretched to the size of the layout.
+// @Description Templates without a layout get one of the size of the image. Certificates already issued are rendered again with it.
+// @Tags Template
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param file formData file true "PNG or JPEG image"
+// @Success 200 {object} response.DataResponse{data=model_template.TemplateLayout} "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /templates/{id}/background [put]
+func UpdateTemplateBackground(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody("File is required"))
+	}
+	if header.Size > constant.RENDER_BACKGROUND_MAX {
+		errMsg := fmt.Sprintf("Background image is larger than %d MB", constant.RENDER_BACKGROUND_MAX>>20)
+		logger.Log.Error(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg") {
+		errMsg := "Background must be a PNG or JPEG image"
+		logger.Log.Error(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+	if config.Width > constant.RENDER_MAX_SIZE || config.Height > constant.RENDER_MAX_SIZE {
+		errMsg := fmt.Sprintf("Background image is larger than %dx%d pixels", constant.RENDER_MAX_SIZE, constant.RENDER_MAX_SIZE)
+		logger.Log.Error(errMsg)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	previous := ""
+	if layout, err := model_template.GetLayout(initializer.DB, template.ID); err == nil {
+		previous = layout.Background
+	}
+
+	key := render.BackgroundKey(template.ID)
+	if err := render.SaveFile(key, data); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	tx := initializer.DB.Begin()
+
+	layout, err := model_template.SetBackground(tx, template.ID, key, config.Width, config.Height)
+	if err != nil {
+		tx.Rollback()
+		render.RemoveFile(key)
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		render.RemoveFile(key)
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if previous != "" {
+		if err := render.RemoveFile(previous); err != nil {
+			logger.Log.Error(err)
+		}
+	}
+
+	logger.Log.Info("Template background updated ", template.ID, " revision ", layout.Revision)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(layout, constant.SuccessUpdateRecord))
+}
@@ -0,0 +1,84 @@
+package handler_template
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_template "certification/model/template"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingTemplateFields struct {
+	Fields []IncomingField `json:"fields" validate:"required,min=1,dive"`
+}
+
+// @Summary Update Template Fields
+// @Description Replace the fields of a certificate template by creating a new version, certificates already issued keep their version
+// @Tags Template
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param IncomingTemplateFields body IncomingTemplateFields true "Ordered fields"
+// @Success 200 {object} response.DataResponse{data=model_template.TemplateVersion} "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Failure 409 {object} response.MessageResponse "Template was changed concurrently"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /templates/{id}/fields [put]
+func UpdateTemplateFields(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingTemplateFields
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	fields, err := ToFields(body.Fields)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	tx := initializer.DB.Begin()
+
+	version, err := model_template.CreateVersion(tx, template, accountID, fields)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusConflict).JSON(response.ErrorResponseBody("Template was changed concurrently, please retry"))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Template ", template.ID, " updated to version ", version.Version)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(version, constant.SuccessUpdateRecord))
+}
@@ -0,0 +1,147 @@
+package handler_template
+
+import (
+	"certification/constant"
+	"certification/database"
+	handler_company "certification/handler/company"
+	"certification/logger"
+	model_template "certification/model/template"
+	"certification/render"
+	"certification/response"
+	"certification/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type IncomingTemplateLayout struct {
+	Width           int                     `json:"width" validate:"required,min=100,max=6000"`
+	Height          int                     `json:"height" validate:"required,min=100,max=6000"`
+	BackgroundColor string                  `json:"background_color"`
+	Elements        []IncomingLayoutElement `json:"elements" validate:"dive"`
+}
+
+// @Summary Get Template Layout
+// @Description Get the layout the certificates of the template are rendered with, templates without one are rendered with a default layout listing their fields
+// @Tags Template
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Template ID"
+// @Success 200 {object} response.DataResponse{data=ResponseLayout} "Successful get layout"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /templates/{id}/layout [get]
+func GetTemplateLayout(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	layout, err := model_template.GetLayout(initializer.DB, template.ID)
+	if err != nil {
+		version, err := model_template.GetVersion(initializer.DB, template.ID, template.LatestVersion)
+		if err != nil {
+			logger.Log.Error(err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+		}
+		layout = render.DefaultLayout(version)
+		layout.TemplateID = template.ID
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(ResponseLayout{
+		TemplateLayout: *layout,
+		HasBackground:  layout.Background != "",
+		Fonts:          render.Fonts(),
+	}, "Successfully get layout"))
+}
+
+// @Summary Update Template Layout
+// @Description Replace the size, background color and elements of the layout of a template, the background image is kept.
+// @Description Text elements may contain {{key}} placeholders for the field values and code, recipient_name, issuer_name, template_name and issued_at.
+// @Description Certificates already issued are rendered again with the new layout.
+// @Tags Template
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Template ID"
+// @Param IncomingTemplateLayout body IncomingTemplateLayout true "Layout in pixels"
+// @Success 200 {object} response.DataResponse{data=model_template.TemplateLayout} "Successful update"
+// @Failure 400 {object} response.MessageResponse "Bad request"
+// @Failure 403 {object} response.MessageResponse "Forbidden"
+// @Failure 404 {object} response.MessageResponse "Template not found"
+// @Failure 500 {object} response.MessageResponse "Internal server error"
+// @Router /templates/{id}/layout [put]
+func UpdateTemplateLayout(ctx *fiber.Ctx, initializer *database.Initializer) error {
+	accountID := ctx.Locals("id").(uuid.UUID)
+
+	var templateID uuid.UUID
+	if !utils.IsValidUUID(ctx.Params("id"), &templateID) {
+		logger.Log.Error(constant.ErrorInvalidID, ctx.Params("id"))
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(constant.ErrorInvalidID))
+	}
+
+	var body IncomingTemplateLayout
+	if err := utils.ValidateParser(&body, ctx, constant.VALIDATE); err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+	if body.BackgroundColor != "" {
+		if _, _, _, err := utils.HexToRGB(body.BackgroundColor); err != nil {
+			errMsg := "Invalid background color " + body.BackgroundColor
+			logger.Log.Error(errMsg)
+			return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(errMsg))
+		}
+	}
+
+	elements, err := ToElements(body.Elements, body.Width, body.Height)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusBadRequest).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	company, err := handler_company.GetCallerCompany(ctx, initializer.DB)
+	if err != nil {
+		logger.Log.Error("Not a company account: ", accountID)
+		return ctx.Status(fiber.StatusForbidden).JSON(response.AccessDeniedResponseBody(accountID.String()))
+	}
+
+	template, err := model_template.GetTemplateByID(initializer.DB, company.ID, templateID)
+	if err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusNotFound).JSON(response.ErrorResponseBody("Template not found"))
+	}
+
+	tx := initializer.DB.Begin()
+
+	layout, err := model_template.SaveLayout(tx, template.ID, body.Width, body.Height, body.BackgroundColor, elements)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		logger.Log.Error(err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(err.Error()))
+	}
+
+	logger.Log.Info("Template layout updated ", template.ID, " revision ", layout.Revision)
+	return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(layout, constant.SuccessUpdateRecord))
+}
@@ -0,0 +1,314 @@
+package jwtkey
+
+import (
+	"certification/config"
+	"certification/constant"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Signing key described in the manifest, the private key is stored next to it as <kid>.pem
+type Key struct {
+	ID        string     `json:"kid"`
+	Algorithm string     `json:"alg"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at"` // still verifies tokens for JWT_KEY_GRACE_PERIOD
+
+	privateKey crypto.Signer
+}
+
+type manifest struct {
+	Keys []*Key `json:"keys"`
+}
+
+type keySet struct {
+	keys     map[string]*Key
+	active   *Key
+	oldest   time.Time // creation of the oldest key, HS256 tokens are accepted for ACCESS_TOKEN_EXPIRY after it
+	loadedAt time.Time
+}
+
+var (
+	current = &keySet{keys: map[string]*Key{}}
+	mu      sync.RWMutex
+)
+
+const manifestFile = "keys.json"
+
+// Load the keyset from JWT_KEYS_PATH, without a keyset tokens are signed with HS256 and SECRET
+func Load() error {
+	set, err := readKeySet(config.JWT_KEYS_PATH)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	current = set
+	mu.Unlock()
+	return nil
+}
+
+func readKeySet(path string) (*keySet, error) {
+	set := &keySet{keys: map[string]*Key{}, loadedAt: time.Now()}
+	if path == "" {
+		return set, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(path, manifestFile))
+	if os.IsNotExist(err) {
+		return set, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid key manifest: %v", err)
+	}
+
+	for _, key := range m.Keys {
+		pemData, err := os.ReadFile(filepath.Join(path, key.ID+".pem"))
+		if err != nil {
+			return nil, err
+		}
+
+		block, _ := pem.Decode(pemData)
+		if block == nil {
+			return nil, fmt.Errorf("invalid pem for key %s", key.ID)
+		}
+
+		privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key %s: %v", key.ID, err)
+		}
+
+		signer, ok := privateKey.(crypto.Signer)
+		if !ok || signingMethod(key.Algorithm) == nil {
+			return nil, fmt.Errorf("unsupported key %s", key.ID)
+		}
+		key.privateKey = signer
+
+		set.keys[key.ID] = key
+		if set.oldest.IsZero() || key.CreatedAt.Before(set.oldest) {
+			set.oldest = key.CreatedAt
+		}
+		if key.RetiredAt == nil && (set.active == nil || key.CreatedAt.After(set.active.CreatedAt)) {
+			set.active = key
+		}
+	}
+
+	return set, nil
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	switch alg {
+	case constant.JWT_ALG_RS256:
+		return jwt.SigningMethodRS256
+	case constant.JWT_ALG_EDDSA:
+		return jwt.SigningMethodEdDSA
+	}
+	return nil
+}
+
+func (k *Key) isValid() bool {
+	return k.RetiredAt == nil || time.Since(*k.RetiredAt) < constant.JWT_KEY_GRACE_PERIOD
+}
+
+// Sign the claims with the active key and put its ID in the kid header
+func Sign(claims jwt.MapClaims) (string, error) {
+	mu.RLock()
+	active := current.active
+	mu.RUnlock()
+
+	if active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.SECRET))
+	}
+
+	token := jwt.NewWithClaims(signingMethod(active.Algorithm), claims)
+	token.Header["kid"] = active.ID
+	return token.SignedString(active.privateKey)
+}
+
+// Verification key of the token, picked by its kid header
+func KeyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	mu.RLock()
+	acceptsHMAC := len(current.keys) == 0 || time.Since(current.oldest) < constant.ACCESS_TOKEN_EXPIRY
+	mu.RUnlock()
+
+	// Without a keyset only HS256 tokens exist, those signed before the first key
+	// was created stay valid until they expire
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || !acceptsHMAC {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return []byte(config.SECRET), nil
+	}
+
+	key, ok := lookup(kid)
+	if !ok || !key.isValid() {
+		return nil, fmt.Errorf("unknown or expired key id %s", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.privateKey.Public(), nil
+}
+
+// Find the key, reloading the keyset when another instance may have rotated it
+func lookup(kid string) (*Key, bool) {
+	mu.RLock()
+	key, ok := current.keys[kid]
+	loadedAt := current.loadedAt
+	mu.RUnlock()
+
+	if ok || time.Since(loadedAt) < constant.JWT_KEY_RELOAD_INTERVAL {
+		return key, ok
+	}
+
+	if err := Load(); err != nil {
+		return nil, false
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	key, ok = current.keys[kid]
+	return key, ok
+}
+
+// Public keys that can verify tokens, served at /.well-known/jwks.json
+func PublicJWKS() JWKS {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range sortedKeys(current) {
+		if !key.isValid() {
+			continue
+		}
+		jwk, err := NewJWK(key.ID, key.Algorithm, key.privateKey.Public())
+		if err != nil {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+// Metadata of every key in the keyset, newest first
+func Keys() []Key {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	keys := []Key{}
+	for _, key := range sortedKeys(current) {
+		keys = append(keys, Key{ID: key.ID, Algorithm: key.Algorithm, CreatedAt: key.CreatedAt, RetiredAt: key.RetiredAt})
+	}
+	return keys
+}
+
+func sortedKeys(set *keySet) []*Key {
+	keys := make([]*Key, 0, len(set.keys))
+	for _, key := range set.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys
+}
+
+// Generate a new active key and retire the previous one, its tokens stay valid for
+// JWT_KEY_GRACE_PERIOD. Keys past the grace period are removed from the keyset.
+func Rotate(alg string) (*Key, error) {
+	path := config.JWT_KEYS_PATH
+	if path == "" {
+		return nil, fmt.Errorf("JWT_KEYS_PATH is not configured")
+	}
+	if signingMethod(alg) == nil {
+		return nil, fmt.Errorf("unsupported algorithm %s", alg)
+	}
+
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+
+	set, err := readKeySet(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var privateKey crypto.Signer
+	switch alg {
+	case constant.JWT_ALG_RS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	case constant.JWT_ALG_EDDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 8)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	key := &Key{ID: hex.EncodeToString(raw), Algorithm: alg, CreatedAt: now}
+
+	if err := writeFile(filepath.Join(path, key.ID+".pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})); err != nil {
+		return nil, err
+	}
+
+	m := manifest{Keys: []*Key{key}}
+	for _, old := range sortedKeys(set) {
+		if old.RetiredAt == nil {
+			old.RetiredAt = &now
+		}
+		if !old.isValid() {
+			os.Remove(filepath.Join(path, old.ID+".pem"))
+			continue
+		}
+		m.Keys = append(m.Keys, old)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(filepath.Join(path, manifestFile), data); err != nil {
+		return nil, err
+	}
+
+	return key, Load()
+}
+
+// Write through a temporary file so a reader never sees a partial file
+func writeFile(name string, data []byte) error {
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
@@ -60,3 +60,41 @@ func SendEmail(bodyhtml, subject string, to_emails []string, file *multipart.Fil
 	}
 	return nil
 }
+
+type Message struct {
+	To       string
+	Subject  string
+	BodyHTML string
+}
+
+// Send the messages over a single SMTP connection, failed recipients are logged and skipped
+func SendEmailBatch(messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	dialer, err := SetUpSMTP(config.SMTP_FROM)
+	if err != nil {
+		return err
+	}
+
+	sender, err := dialer.Dial()
+	if err != nil {
+		return err
+	}
+	defer sender.Close()
+
+	for _, message := range messages {
+		m := gomail.NewMessage()
+		m.SetHeader("From", config.SMTP_FROM)
+		m.SetHeader("To", message.To)
+		m.SetHeader("Subject", message.Subject)
+		m.SetBody("text/html", message.BodyHTML)
+
+		if err := gomail.Send(sender, m); err != nil {
+			logger.Log.Errorf("failed to send email to %v. %v", message.To, err)
+			continue
+		}
+	}
+	return nil
+}
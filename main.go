@@ -4,11 +4,16 @@ import (
 	"certification/config"
 	"certification/database"
 	_ "certification/docs"
+	handler_certificate "certification/handler/certificate"
+	"certification/jwtkey"
 	"certification/logger"
 	"certification/router"
 	"certification/socket"
+	"certification/storage"
+	"certification/utils"
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -34,13 +39,17 @@ var (
 // @name Authorization
 // @BasePath /
 func main() {
+	if RunCommand(os.Args[1:]) {
+		return
+	}
+
 	app := fiber.New(fiber.Config{
 		BodyLimit: 500 * 1024 * 1024, // 500 MB
 	})
 
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-OTP-Token",
 	}))
 
 	Initialize(app)
@@ -59,18 +68,31 @@ func Initialize(app *fiber.App) {
 	logger.Initialize("", config.LOG_PATH)
 	defer logger.Log.Sync()
 
+	if err := jwtkey.Load(); err != nil {
+		logger.Log.Fatal(err)
+	}
+	if err := utils.LoadEncryptionKey(); err != nil {
+		logger.Log.Fatal(err)
+	}
+
 	var initializer = database.Initializer{}
 
 	initializer.ConnectDB(config.DATABASE_URL)
 	initializer.MigrateDB()
 	initializer.ConnectRedis(config.REDIS_HOST, config.REDIS_PORT, config.REDIS_PASSWORD, config.REDIS_DB_NUMBER)
 
+	if err := storage.Connect(); err != nil {
+		logger.Log.Fatal(err)
+	}
+
 	// initializer.ConnectFirebase(config.FIREBASE_CREDENTIALS)
-	// initializer.ConnectS3(config.AWS_ACCESS_KEY_ID, config.AWS_SECRET_ACCESS_KEY, config.AWS_REGION)
 	// // database.ConnectMongoDB(&initializer)
 
 	router.SetupRoutes(app, &initializer)
 	socket.InitializeWebSocket(app, &initializer)
+
+	handler_certificate.ResumeIssuanceJobs(initializer.DB)
+	handler_certificate.StartAnchoring(initializer.DB)
 }
 
 func GetBuildVersion() {
@@ -5,6 +5,7 @@ import (
 	handler_auth "certification/handler/auth"
 	"certification/logger"
 	"certification/response"
+	"fmt"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -25,7 +26,7 @@ func ValidatePermission(moduleID string, permission string) fiber.Handler {
 func ValidateModulePermission(ctx *fiber.Ctx, moduleID string, permission string) response.MessageResponse {
 	access, ok := ctx.Locals(moduleID).(handler_auth.Access)
 	if !ok {
-		return response.AccessDeniedResponseBody(ctx.Locals("id").(string))
+		return response.AccessDeniedResponseBody(fmt.Sprint(ctx.Locals("id")))
 	}
 
 	isAccessPass := access.ReadAccess // Read access only
@@ -38,7 +39,7 @@ func ValidateModulePermission(ctx *fiber.Ctx, moduleID string, permission string
 	}
 
 	if !isAccessPass {
-		return response.AccessDeniedResponseBody(ctx.Locals("id").(string))
+		return response.AccessDeniedResponseBody(fmt.Sprint(ctx.Locals("id")))
 	}
 
 	return response.MessageResponse{}
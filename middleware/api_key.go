@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"certification/constant"
+	"certification/database"
+	"certification/logger"
+	model_account "certification/model/account"
+	model_apikey "certification/model/apikey"
+	model_company "certification/model/company"
+	model_permission "certification/model/permission"
+	"certification/response"
+	"certification/utils"
+	"crypto/subtle"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Alternative to ValidateToken for machine-to-machine access with `Authorization: ApiKey <key>`.
+// The scopes of the key are placed in Locals the same way as the modules of a session.
+func ValidateAPIKey(initializer *database.Initializer) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		parts := strings.Split(ctx.Get("Authorization"), " ")
+		if len(parts) != 2 || parts[0] != constant.API_KEY_SCHEME {
+			errMsg := "Invalid authorization header"
+			logger.Log.Error(errMsg)
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(errMsg))
+		}
+
+		key, ok := FindAPIKey(initializer, parts[1])
+		if !ok {
+			errMsg := "Invalid API key"
+			logger.Log.Error(errMsg)
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(errMsg))
+		}
+
+		if key.IsExpired() {
+			errMsg := "API key has expired"
+			logger.Log.Error(errMsg, key.ID)
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(errMsg))
+		}
+
+		scopes, err := CreatorScopes(initializer, key)
+		if err != nil {
+			errMsg := "API key creator no longer has access to the company"
+			logger.Log.Error(errMsg, key.ID, err)
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(errMsg))
+		}
+
+		modules := make([]map[string]interface{}, 0, len(scopes))
+		for _, scope := range scopes {
+			modules = append(modules, map[string]interface{}{
+				"module_id":     strconv.FormatUint(uint64(scope.ModuleID), 10),
+				"module_access": scope.ReadAccess,
+				"read_access":   scope.ReadAccess,
+				"write_access":  scope.WriteAccess,
+				"delete_access": scope.DeleteAccess,
+			})
+		}
+		ParseModulePermission(ctx, modules)
+
+		if err := model_apikey.UpdateLastUsed(initializer.DB, key.ID, ctx.IP()); err != nil {
+			logger.Log.Error(err)
+		}
+
+		ctx.Locals("id", key.CreatedBy)
+		ctx.Locals("profile_id", key.CompanyID)
+		ctx.Locals("api_key_id", key.ID)
+
+		return ctx.Next()
+	}
+}
+
+// Accept either a bearer access token or an API key
+func ValidateTokenOrAPIKey(initializer *database.Initializer) fiber.Handler {
+	validateToken := ValidateToken(initializer)
+	validateAPIKey := ValidateAPIKey(initializer)
+
+	return func(ctx *fiber.Ctx) error {
+		if strings.HasPrefix(ctx.Get("Authorization"), constant.API_KEY_SCHEME+" ") {
+			return validateAPIKey(ctx)
+		}
+		return validateToken(ctx)
+	}
+}
+
+// Look up the key by its first group and compare the hash in constant time
+func FindAPIKey(initializer *database.Initializer, rawKey string) (*model_apikey.APIKey, bool) {
+	prefix, _, found := strings.Cut(rawKey, "-")
+	if !found {
+		return nil, false
+	}
+
+	keys, err := model_apikey.GetAPIKeysByPrefix(initializer.DB, prefix)
+	if err != nil {
+		logger.Log.Error(err)
+		return nil, false
+	}
+
+	hash := utils.HashToken(rawKey)
+	for i := range keys {
+		if subtle.ConstantTimeCompare([]byte(keys[i].Key), []byte(hash)) == 1 {
+			return &keys[i], true
+		}
+	}
+	return nil, false
+}
+
+// Scopes of the key limited to what its creator may still do in the company.
+// Fails when the creator is no longer active or no longer part of the company.
+func CreatorScopes(initializer *database.Initializer, key *model_apikey.APIKey) ([]model_apikey.APIKeyScope, error) {
+	account, err := model_account.GetAccountByID(initializer.DB, key.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if account.Status != constant.ACTIVE {
+		return nil, errors.New("account is not active")
+	}
+	if !model_company.IsAccountInCompany(initializer.DB, key.CompanyID, key.CreatedBy) {
+		return nil, errors.New("account is not part of the company")
+	}
+
+	role, err := model_permission.GetRoleForAccount(initializer.DB, account.RoleID, account.Role)
+	if err != nil {
+		return nil, err
+	}
+	permissions := make(map[uint]model_permission.Permission, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions[permission.ModuleID] = permission
+	}
+
+	scopes := make([]model_apikey.APIKeyScope, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		permission, ok := permissions[scope.ModuleID]
+		if !ok || !permission.ModuleAccess {
+			continue
+		}
+		scopes = append(scopes, model_apikey.APIKeyScope{
+			ModuleID:     scope.ModuleID,
+			ReadAccess:   scope.ReadAccess && permission.ReadAccess,
+			WriteAccess:  scope.WriteAccess && permission.WriteAccess,
+			DeleteAccess: scope.DeleteAccess && permission.DeleteAccess,
+		})
+	}
+	return scopes, nil
+}
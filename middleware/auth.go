@@ -2,21 +2,21 @@ package middleware
 
 import (
 	"certification/cache"
-	"certification/config"
 	"certification/constant"
 	"certification/database"
 	handler_auth "certification/handler/auth"
+	"certification/jwtkey"
 	"certification/logger"
 	"certification/response"
 	"certification/utils"
 	"context"
 	"encoding/json"
-	"fmt"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 )
 
 func ValidateToken(initializer *database.Initializer) fiber.Handler {
@@ -74,8 +74,21 @@ func ValidateToken(initializer *database.Initializer) fiber.Handler {
 			return ctx.Status(fiber.StatusInternalServerError).JSON(response.ErrorResponseBody(errMsg))
 		}
 
+		sessionID, ok := claims["jti"].(string)
+		if !ok {
+			errMsg := "Failed to extract session from token"
+			logger.Log.Error(errMsg, token)
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(errMsg))
+		}
+
 		// Retrieve permissions from Redis
-		results, err := cache.Redis.RDB.Get(context.Background(), id.(string)).Result()
+		results, err := cache.Redis.RDB.Get(context.Background(), utils.SessionKey(id.(string), sessionID)).Result()
+		if err == redis.Nil {
+			logger.Log.Error("Session not found for ID ", id)
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(
+				"You are not logged in. Token is not valid",
+			))
+		}
 		if err != nil {
 			errMsg := "Failed to extract permission from redis"
 			logger.Log.Error(errMsg, id)
@@ -91,6 +104,14 @@ func ValidateToken(initializer *database.Initializer) fiber.Handler {
 			))
 		}
 
+		// Only the latest access token of the session is accepted, older ones were rotated out
+		if data.SessionID != sessionID || data.Token != parts[1] {
+			logger.Log.Error("Token has been rotated or revoked for ID ", id)
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(
+				"You are not logged in. Token is not valid",
+			))
+		}
+
 		// Validate the redis status, if it's updated, return an error
 		if data.Status == constant.UPDATED {
 			logger.Log.Error("The status is updated")
@@ -116,17 +137,14 @@ func ValidateToken(initializer *database.Initializer) fiber.Handler {
 		ctx.Locals("id", id)
 		ctx.Locals("profile_id", profile_id)
 		ctx.Locals("email", email)
+		ctx.Locals("session_id", sessionID)
 
 		return ctx.Next()
 	}
 }
 
 func JWTKeyFunc(token *jwt.Token) (interface{}, error) {
-	_, ok := token.Method.(*jwt.SigningMethodHMAC)
-	if !ok {
-		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-	}
-	return []byte(config.SECRET), nil
+	return jwtkey.KeyFunc(token)
 }
 
 func ParseModulePermission(ctx *fiber.Ctx, accessesJSON []map[string]interface{}) {
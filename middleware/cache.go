@@ -3,6 +3,7 @@ package middleware
 import (
 	"certification/cache"
 	"certification/response"
+	"certification/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -30,6 +31,18 @@ func GetCacheById(key, id string) fiber.Handler {
 	}
 }
 
+// Same as GetCacheById for a verification code, which is cached in its normalized form
+func GetCacheByVerificationCode(key, id string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		code := utils.NormalizeVerificationCode(ctx.Params(id))
+		cache, err := cache.Redis.GetCacheById(key, code)
+		if err != nil {
+			return ctx.Next()
+		}
+		return ctx.Status(fiber.StatusOK).JSON(response.DataResponseBody(cache, "Cache retrieved successfully"))
+	}
+}
+
 func GetCacheByIdForMe(key, id string) fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		userId := ctx.Locals("id").(uuid.UUID)
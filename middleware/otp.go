@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	handler_auth "certification/handler/auth"
+	"certification/logger"
+	"certification/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RequireStepUp must run after ValidateToken. The request has to carry the token
+// returned by /auth/otp/verify for the same purpose in the X-OTP-Token header.
+func RequireStepUp(purpose string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		accountID, ok := ctx.Locals("id").(uuid.UUID)
+		if !ok {
+			errMsg := "Failed to extract account ID from Locals"
+			logger.Log.Error(errMsg)
+			return ctx.Status(fiber.StatusUnauthorized).JSON(response.ErrorResponseBody(errMsg))
+		}
+
+		token := ctx.Get("X-OTP-Token")
+		if token == "" || !handler_auth.ConsumeStepUpToken(token, accountID, purpose) {
+			errMsg := "A verified OTP is required for this action"
+			logger.Log.Error(errMsg, accountID)
+			return ctx.Status(fiber.StatusForbidden).JSON(response.ErrorResponseBody(errMsg))
+		}
+
+		return ctx.Next()
+	}
+}
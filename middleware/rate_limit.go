@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"certification/cache"
+	"certification/constant"
+	"certification/logger"
+	"certification/response"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimit allows at most limit requests per key inside a sliding window.
+// Requests whose key is empty are not limited.
+func RateLimit(name string, limit int, window time.Duration, keyFunc func(*fiber.Ctx) string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := keyFunc(ctx)
+		if key == "" {
+			return ctx.Next()
+		}
+
+		redisKey := fmt.Sprintf("%s:%s:%s", constant.REDIS_RATE_LIMIT, name, key)
+		now := time.Now()
+
+		pipe := cache.Redis.RDB.TxPipeline()
+		pipe.ZRemRangeByScore(context.Background(), redisKey, "-inf", strconv.FormatInt(now.Add(-window).UnixNano(), 10))
+		pipe.ZAdd(context.Background(), redisKey, redis.Z{Score: float64(now.UnixNano()), Member: uuid.New().String()})
+		count := pipe.ZCard(context.Background(), redisKey)
+		pipe.Expire(context.Background(), redisKey, window)
+		if _, err := pipe.Exec(context.Background()); err != nil {
+			// Do not lock everybody out when Redis is unavailable
+			logger.Log.Error(err)
+			return ctx.Next()
+		}
+
+		if count.Val() > int64(limit) {
+			logger.Log.Error("Rate limit exceeded for ", name, " by ", key)
+			ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(window.Seconds())))
+			return ctx.Status(fiber.StatusTooManyRequests).JSON(response.ErrorResponseBody(constant.ErrorTooManyRequest))
+		}
+
+		return ctx.Next()
+	}
+}
+
+func KeyByIP(ctx *fiber.Ctx) string {
+	return ctx.IP()
+}
+
+func KeyByEmail(ctx *fiber.Ctx) string {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := ctx.BodyParser(&body); err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(body.Email))
+}
+
+func KeyByAccount(ctx *fiber.Ctx) string {
+	accountID, ok := ctx.Locals("id").(uuid.UUID)
+	if !ok {
+		return ""
+	}
+	return accountID.String()
+}
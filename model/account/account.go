@@ -18,6 +18,7 @@ type Account struct {
 	Password string                   `json:"password"`
 	Role     constant.AccountRoleType `json:"role"`
 	Status   constant.Status          `json:"status"`
+	RoleID   *uint                    `json:"role_id"` // permission role, nil uses the default role of Role
 
 	Company model_company.Company `json:"company" gorm:"foreignKey:AccountID"`
 	User    model_user.User       `json:"user" gorm:"foreignKey:AccountID"`
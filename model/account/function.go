@@ -1,6 +1,7 @@
 package model_account
 
 import (
+	"certification/constant"
 	"certification/logger"
 
 	"github.com/google/uuid"
@@ -46,3 +47,30 @@ func GetProfileByAccountID(db *gorm.DB, accountID uuid.UUID) (*ResponseProfile,
 		User:    a.User,
 	}, nil
 }
+
+// update account status
+func UpdateAccountStatus(tx *gorm.DB, id uuid.UUID, status constant.Status) error {
+	return tx.Model(&Account{ID: id}).Update("status", status).Error
+}
+
+// activate the account if it is still pending, e.g. once a password was set from an emailed token
+func ActivatePendingAccount(tx *gorm.DB, id uuid.UUID) error {
+	return tx.Model(&Account{ID: id}).Where("status = ?", constant.PENDING).Update("status", constant.ACTIVE).Error
+}
+
+// assign a permission role to the account, nil falls back to the default role
+func UpdateAccountRoleID(tx *gorm.DB, id uuid.UUID, roleID *uint) error {
+	return tx.Model(&Account{ID: id}).Update("role_id", roleID).Error
+}
+
+// count accounts assigned to the permission role
+func CountAccountsByRoleID(db *gorm.DB, roleID uint) int64 {
+	var count int64
+	db.Model(&Account{}).Where("role_id = ?", roleID).Count(&count)
+	return count
+}
+
+// update account password hash
+func UpdateAccountPassword(tx *gorm.DB, id uuid.UUID, password string) error {
+	return tx.Model(&Account{}).Where("id = ?", id).Update("password", password).Error
+}
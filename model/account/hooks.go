@@ -0,0 +1,27 @@
+package model_account
+
+import (
+	"certification/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const invalidateSessionsKey = "account:invalidate_sessions"
+
+// The permissions of a session are read at login, a new role or status must log the account in again
+func (a *Account) BeforeUpdate(tx *gorm.DB) error {
+	if tx.Statement.Changed("Role", "RoleID", "Status") {
+		tx.InstanceSet(invalidateSessionsKey, true)
+	}
+	return nil
+}
+
+func (a *Account) AfterUpdate(tx *gorm.DB) error {
+	if _, ok := tx.InstanceGet(invalidateSessionsKey); !ok || a.ID == uuid.Nil {
+		return nil
+	}
+
+	utils.InvalidateSessionsAfterCommit(tx, a.ID.String())
+	return nil
+}
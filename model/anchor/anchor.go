@@ -0,0 +1,38 @@
+package model_anchor
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Merkle root of a batch of certificates anchored in the registry contract.
+// Batches are PENDING until the transaction is sent, then SUBMITTED, ONCHAIN or FAILED.
+type AnchorBatch struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Root        string          `json:"root" gorm:"index"`
+	Size        int             `json:"size"`
+	Status      constant.Status `json:"status" gorm:"index"`
+	ChainID     string          `json:"chain_id"`
+	Registry    string          `json:"registry"` // address of the registry contract
+	TxHash      string          `json:"tx_hash"`
+	BlockNumber uint64          `json:"block_number"`
+	Error       string          `json:"error"`
+	SubmittedAt *time.Time      `json:"submitted_at"`
+	AnchoredAt  *time.Time      `json:"anchored_at"`
+}
+
+// Leaf and Merkle proof of a certificate in the last batch it was part of
+type CertificateAnchor struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	CertificateID uuid.UUID `json:"certificate_id" gorm:"type:uuid;uniqueIndex"`
+	BatchID       uuid.UUID `json:"batch_id" gorm:"type:uuid;index"`
+	Leaf          string    `json:"leaf"`
+	Proof         string    `json:"proof"`    // JSON array of the sibling hashes from the leaf to the root
+	Attempts      int       `json:"attempts"` // batches the certificate was part of
+}
@@ -0,0 +1,124 @@
+package model_anchor
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ----------------- Batch Functions -----------------
+
+// create the batch with the anchors of its certificates, the anchors of a previous batch are replaced.
+// Revocations of the batch are set with model_certificate.SetRevocationsBatch.
+func CreateBatch(tx *gorm.DB, batch *AnchorBatch, anchors []CertificateAnchor) error {
+	if err := tx.Create(batch).Error; err != nil {
+		return err
+	}
+	if len(anchors) == 0 {
+		return nil
+	}
+
+	for i := range anchors {
+		anchors[i].BatchID = batch.ID
+		anchors[i].Attempts = 1
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "certificate_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"batch_id": gorm.Expr("excluded.batch_id"),
+			"leaf":     gorm.Expr("excluded.leaf"),
+			"proof":    gorm.Expr("excluded.proof"),
+			"attempts": gorm.Expr("certificate_anchors.attempts + 1"),
+		}),
+	}).CreateInBatches(&anchors, 500).Error
+}
+
+// get the batches with the status, oldest first
+func GetBatchesByStatus(db *gorm.DB, status constant.Status) ([]AnchorBatch, error) {
+	var b []AnchorBatch
+	if err := db.Where("status = ?", status).Order("created_at").Find(&b).Error; err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func GetBatchByID(db *gorm.DB, id uuid.UUID) (*AnchorBatch, error) {
+	var b AnchorBatch
+	if err := db.Where("id = ?", id).First(&b).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// record the transaction sent for the batch
+func SetBatchSubmitted(db *gorm.DB, batch *AnchorBatch, txHash string) error {
+	now := time.Now()
+	batch.Status = constant.SUBMITTED
+	batch.TxHash = txHash
+	batch.SubmittedAt = &now
+	return db.Model(&AnchorBatch{}).Where("id = ?", batch.ID).Updates(map[string]interface{}{
+		"status":       batch.Status,
+		"tx_hash":      txHash,
+		"submitted_at": now,
+	}).Error
+}
+
+// record the block the root of the batch was anchored in
+func SetBatchAnchored(tx *gorm.DB, batch *AnchorBatch, txHash string, blockNumber uint64) error {
+	now := time.Now()
+	batch.Status = constant.ONCHAIN
+	batch.TxHash = txHash
+	batch.BlockNumber = blockNumber
+	batch.AnchoredAt = &now
+	return tx.Model(&AnchorBatch{}).Where("id = ?", batch.ID).Updates(map[string]interface{}{
+		"status":       batch.Status,
+		"tx_hash":      txHash,
+		"block_number": blockNumber,
+		"anchored_at":  now,
+	}).Error
+}
+
+func SetBatchFailed(tx *gorm.DB, batch *AnchorBatch, reason string) error {
+	batch.Status = constant.FAILED
+	batch.Error = reason
+	return tx.Model(&AnchorBatch{}).Where("id = ?", batch.ID).Updates(map[string]interface{}{
+		"status": batch.Status,
+		"error":  reason,
+	}).Error
+}
+
+// ----------------- Certificate Anchor Functions -----------------
+
+// get the ids of the certificates of the batch, those part of maxAttempts batches or more are exhausted
+func GetBatchCertificateIDs(db *gorm.DB, batchID uuid.UUID, maxAttempts int) ([]uuid.UUID, []uuid.UUID, error) {
+	var anchors []CertificateAnchor
+	if err := db.Select("certificate_id", "attempts").Where("batch_id = ?", batchID).Find(&anchors).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var retry, exhausted []uuid.UUID
+	for _, a := range anchors {
+		if a.Attempts >= maxAttempts {
+			exhausted = append(exhausted, a.CertificateID)
+		} else {
+			retry = append(retry, a.CertificateID)
+		}
+	}
+	return retry, exhausted, nil
+}
+
+// get the anchor of the certificate with its batch
+func GetCertificateAnchor(db *gorm.DB, certificateID uuid.UUID) (*CertificateAnchor, *AnchorBatch, error) {
+	var a CertificateAnchor
+	if err := db.Where("certificate_id = ?", certificateID).First(&a).Error; err != nil {
+		return nil, nil, err
+	}
+	batch, err := GetBatchByID(db, a.BatchID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &a, batch, nil
+}
@@ -0,0 +1,41 @@
+package model_apikey
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	CompanyID  uuid.UUID       `json:"company_id" gorm:"type:uuid;index"`
+	CreatedBy  uuid.UUID       `json:"created_by" gorm:"type:uuid"`
+	Name       string          `json:"name"`
+	Prefix     string          `json:"prefix" gorm:"index"` // first group of the key, shown to identify it
+	Key        string          `json:"-"`                   // SHA-256 hash of the key
+	Status     constant.Status `json:"status"`
+	ExpireAt   *time.Time      `json:"expire_at"`
+	LastUsedAt *time.Time      `json:"last_used_at"`
+	LastUsedIP string          `json:"last_used_ip"`
+
+	Scopes []APIKeyScope `json:"scopes" gorm:"foreignKey:APIKeyID"`
+}
+
+// Grant of the key on a module, the same flags as model_permission.Permission
+type APIKeyScope struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	APIKeyID     uuid.UUID `json:"api_key_id" gorm:"type:uuid;uniqueIndex:idx_api_key_module"`
+	ModuleID     uint      `json:"module_id" gorm:"uniqueIndex:idx_api_key_module"`
+	ReadAccess   bool      `json:"read_access"`
+	WriteAccess  bool      `json:"write_access"`
+	DeleteAccess bool      `json:"delete_access"`
+}
+
+func (k *APIKey) IsExpired() bool {
+	return k.ExpireAt != nil && time.Now().After(*k.ExpireAt)
+}
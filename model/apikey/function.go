@@ -0,0 +1,60 @@
+package model_apikey
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// get active keys with the given prefix, the caller compares the hash
+func GetAPIKeysByPrefix(db *gorm.DB, prefix string) ([]APIKey, error) {
+	var k []APIKey
+	if err := db.Preload("Scopes").Where("prefix = ? AND status = ?", prefix, constant.ACTIVE).Find(&k).Error; err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// get key of the company by id
+func GetAPIKeyByID(db *gorm.DB, companyID uuid.UUID, id uuid.UUID) (*APIKey, error) {
+	var k APIKey
+	if err := db.Preload("Scopes").Where("id = ? AND company_id = ?", id, companyID).First(&k).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// get all keys of the company
+func GetAPIKeysByCompanyID(db *gorm.DB, companyID uuid.UUID) ([]APIKey, error) {
+	var k []APIKey
+	if err := db.Preload("Scopes").Where("company_id = ?", companyID).Order("created_at DESC").Find(&k).Error; err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// replace the secret of the key, the old key stops working right away
+func RotateAPIKey(db *gorm.DB, id uuid.UUID, prefix string, hash string) error {
+	return db.Model(&APIKey{}).Where("id = ? AND status = ?", id, constant.ACTIVE).Updates(map[string]interface{}{
+		"prefix": prefix,
+		"key":    hash,
+	}).Error
+}
+
+// revoke the key
+func RevokeAPIKey(db *gorm.DB, id uuid.UUID) error {
+	return db.Model(&APIKey{}).Where("id = ?", id).Update("status", constant.REVOKED).Error
+}
+
+// record the last use of the key, at most once per API_KEY_LAST_USED_INTERVAL
+func UpdateLastUsed(db *gorm.DB, id uuid.UUID, ip string) error {
+	now := time.Now()
+	return db.Model(&APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-constant.API_KEY_LAST_USED_INTERVAL)).
+		Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		}).Error
+}
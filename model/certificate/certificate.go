@@ -0,0 +1,58 @@
+package model_certificate
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Issued certificates start OFFCHAIN, anchoring moves them through SUBMITTED to ONCHAIN or FAILED.
+// Revoked certificates are REVOKED, a reissue revokes the original and links both ways.
+// Certificates issued to an email without a user account are unclaimed until the recipient claims them.
+type Certificate struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Code            string          `json:"code" gorm:"uniqueIndex"` // public verification code
+	CompanyID       uuid.UUID       `json:"company_id" gorm:"type:uuid;index"`
+	TemplateID      uuid.UUID       `json:"template_id" gorm:"type:uuid;index"`
+	VersionID       uuid.UUID       `json:"version_id" gorm:"type:uuid"`
+	IssuedBy        uuid.UUID       `json:"issued_by" gorm:"type:uuid"`
+	RecipientEmail  string          `json:"recipient_email" gorm:"index"`
+	RecipientSalt   string          `json:"-"` // the anchored document only has the email hashed with it, see verifier.HashRecipient
+	RecipientUserID *uuid.UUID      `json:"recipient_user_id" gorm:"type:uuid;index"`
+	ClaimedAt       *time.Time      `json:"claimed_at"` // set once the certificate is attached to the recipient's user account
+	Private         bool            `json:"private"`    // the recipient opted out of public verification
+	Status          constant.Status `json:"status"`
+	RevokedAt       *time.Time      `json:"revoked_at"`
+	RevokeReason    string          `json:"revoke_reason"`
+	ReissueOfID     *uuid.UUID      `json:"reissue_of_id" gorm:"type:uuid"`
+	ReissuedAsID    *uuid.UUID      `json:"reissued_as_id" gorm:"type:uuid"`
+	StatusListID    *uuid.UUID      `json:"status_list_id" gorm:"type:uuid;index"` // set with the index once exported as a credential
+	StatusListIndex *int            `json:"status_list_index"`
+
+	Values []CertificateValue `json:"values" gorm:"foreignKey:CertificateID"`
+}
+
+// Value of a template field, stored in the form returned by model_template.TemplateField.Normalize
+type CertificateValue struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	CertificateID uuid.UUID `json:"certificate_id" gorm:"type:uuid;uniqueIndex:idx_certificate_key"`
+	Key           string    `json:"key" gorm:"uniqueIndex:idx_certificate_key"`
+	Value         string    `json:"value"`
+}
+
+// Fields of the certificate in the document returned by Certificate.CanonicalJSON
+type CanonicalCertificate struct {
+	Code       string            `json:"code"`
+	CompanyID  string            `json:"company_id"`
+	TemplateID string            `json:"template_id"`
+	VersionID  string            `json:"version_id"`
+	Recipient  string            `json:"recipient"` // verifier.HashRecipient of the email
+	IssuedAt   string            `json:"issued_at"`
+	ReissueOf  string            `json:"reissue_of,omitempty"`
+	Values     map[string]string `json:"values"`
+}
@@ -0,0 +1,442 @@
+package model_certificate
+
+import (
+	"bytes"
+	"certification/constant"
+	"certification/verifier"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CertificateFilter struct {
+	Status         constant.Status
+	TemplateID     *uuid.UUID
+	RecipientEmail string
+	Claimed        *bool
+	Limit          int
+	Offset         int
+}
+
+// create a certificate for the recipient with the normalized values of its template version
+func NewCertificate(companyID, templateID, versionID, issuedBy uuid.UUID, email string, userID *uuid.UUID, values map[string]string) Certificate {
+	c := Certificate{
+		CompanyID:       companyID,
+		TemplateID:      templateID,
+		VersionID:       versionID,
+		IssuedBy:        issuedBy,
+		RecipientEmail:  email,
+		RecipientUserID: userID,
+		Status:          constant.OFFCHAIN,
+	}
+	if userID != nil {
+		now := time.Now()
+		c.ClaimedAt = &now
+	}
+	for key, value := range values {
+		c.Values = append(c.Values, CertificateValue{Key: key, Value: value})
+	}
+	return c
+}
+
+// get certificates of the company, newest first
+func GetCertificatesByCompanyID(db *gorm.DB, companyID uuid.UUID, filter CertificateFilter) ([]Certificate, int64, error) {
+	query := db.Model(&Certificate{}).Where("company_id = ? AND status <> ?", companyID, constant.DELETED)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.TemplateID != nil {
+		query = query.Where("template_id = ?", *filter.TemplateID)
+	}
+	if filter.RecipientEmail != "" {
+		query = query.Where("recipient_email = ?", filter.RecipientEmail)
+	}
+	if filter.Claimed != nil && *filter.Claimed {
+		query = query.Where("claimed_at IS NOT NULL")
+	} else if filter.Claimed != nil {
+		query = query.Where("claimed_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var c []Certificate
+	if err := query.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&c).Error; err != nil {
+		return nil, 0, err
+	}
+	return c, total, nil
+}
+
+// get certificate by id within a company with its values
+func GetCertificateByID(db *gorm.DB, companyID uuid.UUID, id uuid.UUID) (*Certificate, error) {
+	var c Certificate
+	if err := db.Preload("Values").Where("company_id = ? AND id = ? AND status <> ?", companyID, id, constant.DELETED).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// get the values of the certificate as a map of field key to value
+func (c *Certificate) ValueMap() map[string]string {
+	values := make(map[string]string, len(c.Values))
+	for _, v := range c.Values {
+		values[v.Key] = v.Value
+	}
+	return values
+}
+
+// Document hashed into the Merkle leaf of the certificate when it is anchored. The fields are in a
+// fixed order, the values are sorted by key and the issue time is in UTC seconds so that anyone
+// holding the certificate can rebuild the exact bytes. HTML characters are not escaped. The email is
+// only hashed with the salt of the certificate, so a published document does not reveal the recipient.
+func (c *Certificate) CanonicalJSON() ([]byte, error) {
+	document := CanonicalCertificate{
+		Code:       c.Code,
+		CompanyID:  c.CompanyID.String(),
+		TemplateID: c.TemplateID.String(),
+		VersionID:  c.VersionID.String(),
+		Recipient:  verifier.HashRecipient(c.RecipientSalt, c.RecipientEmail),
+		IssuedAt:   c.CreatedAt.UTC().Format(time.RFC3339),
+		Values:     c.ValueMap(),
+	}
+	if c.ReissueOfID != nil {
+		document.ReissueOf = c.ReissueOfID.String()
+	}
+	return canonicalJSON(document)
+}
+
+func canonicalJSON(document interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func (c *Certificate) IsRevoked() bool {
+	return c.Status == constant.REVOKED
+}
+
+// revoke the certificate and record it in its history, returns gorm.ErrRecordNotFound when it was already revoked
+func RevokeCertificate(tx *gorm.DB, id uuid.UUID, reason string, reissuedAs *uuid.UUID, actorID uuid.UUID) error {
+	result := tx.Model(&Certificate{}).Where("id = ? AND status NOT IN ?", id, []constant.Status{constant.REVOKED, constant.DELETED}).Updates(map[string]interface{}{
+		"status":         constant.REVOKED,
+		"revoked_at":     time.Now(),
+		"revoke_reason":  reason,
+		"reissued_as_id": reissuedAs,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return createRevocation(tx, id, constant.REVOCATION_REVOKE, reason, actorID)
+}
+
+// ----------------- Claim Functions -----------------
+
+// count the certificates issued to the email which can still be claimed
+func CountClaimableCertificates(db *gorm.DB, email string) int64 {
+	var count int64
+	db.Model(&Certificate{}).
+		Where("recipient_email = ? AND recipient_user_id IS NULL AND status NOT IN ?", email, []constant.Status{constant.REVOKED, constant.DELETED}).
+		Count(&count)
+	return count
+}
+
+// attach the unclaimed certificates issued to the email to the user, returns how many were claimed
+func ClaimCertificates(tx *gorm.DB, email string, userID uuid.UUID) (int64, error) {
+	result := tx.Model(&Certificate{}).
+		Where("recipient_email = ? AND recipient_user_id IS NULL AND status NOT IN ?", email, []constant.Status{constant.REVOKED, constant.DELETED}).
+		Updates(map[string]interface{}{
+			"recipient_user_id": userID,
+			"claimed_at":        time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// get the certificates of the user, newest first
+func GetCertificatesByUserID(db *gorm.DB, userID uuid.UUID) ([]Certificate, error) {
+	var c []Certificate
+	if err := db.Preload("Values").Where("recipient_user_id = ? AND status <> ?", userID, constant.DELETED).Order("created_at DESC").Find(&c).Error; err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// get the codes of the certificates issued to the email which can still be claimed
+func GetClaimableCodes(db *gorm.DB, email string) ([]string, error) {
+	var codes []string
+	err := db.Model(&Certificate{}).
+		Where("recipient_email = ? AND recipient_user_id IS NULL AND status NOT IN ?", email, []constant.Status{constant.REVOKED, constant.DELETED}).
+		Pluck("code", &codes).Error
+	return codes, err
+}
+
+// ----------------- Verification Functions -----------------
+
+// get certificate by its public verification code with its values
+func GetCertificateByCode(db *gorm.DB, code string) (*Certificate, error) {
+	var c Certificate
+	if err := db.Preload("Values").Where("code = ? AND status <> ?", code, constant.DELETED).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// get certificate by id within the certificates of the user
+func GetCertificateByUserID(db *gorm.DB, userID uuid.UUID, id uuid.UUID) (*Certificate, error) {
+	var c Certificate
+	if err := db.Preload("Values").Where("recipient_user_id = ? AND id = ? AND status <> ?", userID, id, constant.DELETED).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// hide the certificate from public verification or show it again
+func UpdatePrivate(db *gorm.DB, id uuid.UUID, private bool) error {
+	return db.Model(&Certificate{}).Where("id = ?", id).Update("private", private).Error
+}
+
+// get the public verification code of a certificate
+func GetCode(db *gorm.DB, id uuid.UUID) string {
+	var code string
+	db.Model(&Certificate{}).Where("id = ?", id).Pluck("code", &code)
+	return code
+}
+
+// ----------------- Anchoring Functions -----------------
+
+// get and lock the oldest certificates waiting to be anchored with their values,
+// certificates locked by another transaction are skipped
+func GetAnchorableCertificates(tx *gorm.DB, limit int) ([]Certificate, error) {
+	var c []Certificate
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ?", constant.OFFCHAIN).Order("created_at").Limit(limit).Find(&c).Error; err != nil {
+		return nil, err
+	}
+	if len(c) == 0 {
+		return c, nil
+	}
+
+	ids := make([]uuid.UUID, len(c))
+	for i := range c {
+		ids[i] = c[i].ID
+	}
+	var values []CertificateValue
+	if err := tx.Where("certificate_id IN ?", ids).Find(&values).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID][]CertificateValue, len(c))
+	for _, v := range values {
+		byID[v.CertificateID] = append(byID[v.CertificateID], v)
+	}
+	for i := range c {
+		c[i].Values = byID[c[i].ID]
+	}
+	return c, nil
+}
+
+// change the status of the certificates which still have the status from,
+// a certificate revoked in the meantime keeps its status
+func UpdateStatusByIDs(tx *gorm.DB, ids []uuid.UUID, from constant.Status, to constant.Status) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Model(&Certificate{}).Where("id IN ? AND status = ?", ids, from).Update("status", to).Error
+}
+
+// get the public verification codes of the certificates
+func GetCodes(db *gorm.DB, ids []uuid.UUID) ([]string, error) {
+	var codes []string
+	if len(ids) == 0 {
+		return codes, nil
+	}
+	err := db.Model(&Certificate{}).Where("id IN ?", ids).Pluck("code", &codes).Error
+	return codes, err
+}
+
+// ----------------- Revocation Functions -----------------
+
+// make a revoked certificate valid again with the given status and record it in its history,
+// returns gorm.ErrRecordNotFound when it is not revoked or was revoked by a reissue
+func UnrevokeCertificate(tx *gorm.DB, id uuid.UUID, status constant.Status, reason string, actorID uuid.UUID) error {
+	result := tx.Model(&Certificate{}).Where("id = ? AND status = ? AND reissued_as_id IS NULL", id, constant.REVOKED).Updates(map[string]interface{}{
+		"status":        status,
+		"revoked_at":    nil,
+		"revoke_reason": "",
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return createRevocation(tx, id, constant.REVOCATION_UNREVOKE, reason, actorID)
+}
+
+func createRevocation(tx *gorm.DB, certificateID uuid.UUID, action string, reason string, actorID uuid.UUID) error {
+	return tx.Create(&CertificateRevocation{
+		CertificateID: certificateID,
+		Action:        action,
+		Reason:        reason,
+		ActorID:       actorID,
+		Status:        constant.OFFCHAIN,
+	}).Error
+}
+
+// get the revocation history of the certificate, oldest first
+func GetRevocations(db *gorm.DB, certificateID uuid.UUID) ([]CertificateRevocation, error) {
+	var r []CertificateRevocation
+	if err := db.Where("certificate_id = ?", certificateID).Order("created_at").Find(&r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// get the last revocation or unrevocation of the certificate
+func GetLastRevocation(db *gorm.DB, certificateID uuid.UUID) (*CertificateRevocation, error) {
+	var r CertificateRevocation
+	if err := db.Where("certificate_id = ?", certificateID).Order("created_at DESC").First(&r).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Document hashed into the Merkle leaf of the event, built like Certificate.CanonicalJSON.
+// CertificateLeaf must be loaded.
+func (r *CertificateRevocation) CanonicalJSON() ([]byte, error) {
+	return canonicalJSON(CanonicalRevocation{
+		ID:          r.ID.String(),
+		Certificate: r.CertificateLeaf,
+		Action:      r.Action,
+		Reason:      r.Reason,
+		At:          r.CreatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// get and lock the oldest events waiting to be anchored whose certificate is anchored on-chain,
+// with the leaf of the certificate. Events locked by another transaction are skipped.
+func GetAnchorableRevocations(tx *gorm.DB, limit int) ([]CertificateRevocation, error) {
+	var r []CertificateRevocation
+	if limit <= 0 {
+		return r, nil
+	}
+	err := tx.Select("certificate_revocations.*, certificate_anchors.leaf AS certificate_leaf").
+		Joins("JOIN certificate_anchors ON certificate_anchors.certificate_id = certificate_revocations.certificate_id").
+		Joins("JOIN anchor_batches ON anchor_batches.id = certificate_anchors.batch_id").
+		Where("certificate_revocations.status = ? AND anchor_batches.status = ?", constant.OFFCHAIN, constant.ONCHAIN).
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "certificate_revocations"}, Options: "SKIP LOCKED"}).
+		Order("certificate_revocations.created_at").Limit(limit).Find(&r).Error
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// put the events with their leaf and proof in the anchor batch
+func SetRevocationsBatch(tx *gorm.DB, batchID uuid.UUID, revocations []CertificateRevocation) error {
+	if len(revocations) == 0 {
+		return nil
+	}
+
+	for i := range revocations {
+		revocations[i].BatchID = &batchID
+		revocations[i].Status = constant.SUBMITTED
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"batch_id": gorm.Expr("excluded.batch_id"),
+			"leaf":     gorm.Expr("excluded.leaf"),
+			"proof":    gorm.Expr("excluded.proof"),
+			"status":   gorm.Expr("excluded.status"),
+			"attempts": gorm.Expr("certificate_revocations.attempts + 1"),
+		}),
+	}).CreateInBatches(&revocations, 500).Error
+}
+
+// change the status of the events of the batch which still have the status from
+func UpdateRevocationStatusByBatch(tx *gorm.DB, batchID uuid.UUID, from constant.Status, to constant.Status) error {
+	return tx.Model(&CertificateRevocation{}).Where("batch_id = ? AND status = ?", batchID, from).Update("status", to).Error
+}
+
+// send the events of the failed batch back to OFFCHAIN, those part of maxAttempts batches or more are FAILED
+func FailRevocationsByBatch(tx *gorm.DB, batchID uuid.UUID, maxAttempts int) error {
+	return tx.Model(&CertificateRevocation{}).Where("batch_id = ? AND status = ?", batchID, constant.SUBMITTED).
+		Update("status", gorm.Expr("CASE WHEN attempts >= ? THEN ? ELSE ? END", maxAttempts, constant.FAILED, constant.OFFCHAIN)).Error
+}
+
+// get the certificates of the events of the batch
+func GetRevocationCertificateIDs(db *gorm.DB, batchID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := db.Model(&CertificateRevocation{}).Distinct().Where("batch_id = ?", batchID).Pluck("certificate_id", &ids).Error
+	return ids, err
+}
+
+// ----------------- Status List Functions -----------------
+
+func GetStatusListByID(db *gorm.DB, id uuid.UUID) (*StatusList, error) {
+	var l StatusList
+	if err := db.Where("id = ?", id).First(&l).Error; err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// assign the next free index of a status list of the company to the certificate,
+// a certificate keeps the index it already has
+func AssignStatusIndex(tx *gorm.DB, certificate *Certificate) error {
+	if certificate.StatusListID != nil {
+		return nil
+	}
+
+	var list StatusList
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("company_id = ? AND next_index < size", certificate.CompanyID).Order("created_at").First(&list).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		list = StatusList{CompanyID: certificate.CompanyID, Size: constant.STATUS_LIST_SIZE}
+		err = tx.Create(&list).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	index := list.NextIndex
+	result := tx.Model(&Certificate{}).Where("id = ? AND status_list_id IS NULL", certificate.ID).Updates(map[string]interface{}{
+		"status_list_id":    list.ID,
+		"status_list_index": index,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Assigned by a concurrent export
+		return tx.Select("status_list_id", "status_list_index").Where("id = ?", certificate.ID).First(certificate).Error
+	}
+
+	if err := tx.Model(&list).Update("next_index", index+1).Error; err != nil {
+		return err
+	}
+	certificate.StatusListID = &list.ID
+	certificate.StatusListIndex = &index
+	return nil
+}
+
+// get the indexes of the revoked or deleted certificates of the status list
+func GetRevokedStatusIndexes(db *gorm.DB, listID uuid.UUID) ([]int, error) {
+	var indexes []int
+	err := db.Model(&Certificate{}).
+		Where("status_list_id = ? AND status IN ?", listID, []constant.Status{constant.REVOKED, constant.DELETED}).
+		Pluck("status_list_index", &indexes).Error
+	return indexes, err
+}
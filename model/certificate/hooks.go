@@ -0,0 +1,29 @@
+package model_certificate
+
+import (
+	"certification/utils"
+
+	"gorm.io/gorm"
+)
+
+// Every certificate gets its public verification code and the salt of its recipient hash when it is created
+func (c *Certificate) BeforeCreate(tx *gorm.DB) error {
+	if c.RecipientSalt == "" {
+		salt, err := utils.GenerateToken()
+		if err != nil {
+			return err
+		}
+		c.RecipientSalt = salt
+	}
+
+	if c.Code != "" {
+		return nil
+	}
+
+	code, err := utils.GenerateVerificationCode()
+	if err != nil {
+		return err
+	}
+	c.Code = code
+	return nil
+}
@@ -0,0 +1,49 @@
+package model_certificate
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revocation or unrevocation of a certificate, the history of its revocation status. Events of
+// certificates anchored on-chain are anchored too, from OFFCHAIN through SUBMITTED to ONCHAIN or FAILED.
+type CertificateRevocation struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	CertificateID uuid.UUID       `json:"certificate_id" gorm:"type:uuid;index"`
+	Action        string          `json:"action"` // constant.REVOCATION_REVOKE or constant.REVOCATION_UNREVOKE
+	Reason        string          `json:"reason"`
+	ActorID       uuid.UUID       `json:"actor_id" gorm:"type:uuid"` // account which made the change
+	Status        constant.Status `json:"status" gorm:"index"`
+	BatchID       *uuid.UUID      `json:"batch_id" gorm:"type:uuid;index"` // last anchor batch the event was part of
+	Leaf          string          `json:"leaf"`
+	Proof         string          `json:"proof"`    // JSON array of the sibling hashes from the leaf to the root
+	Attempts      int             `json:"attempts"` // batches the event was part of
+
+	CertificateLeaf string `json:"-" gorm:"->;-:migration"` // loaded by GetAnchorableRevocations
+}
+
+// Fields of the event in the document returned by CertificateRevocation.CanonicalJSON,
+// the certificate is identified by the leaf it was anchored with
+type CanonicalRevocation struct {
+	ID          string `json:"id"`
+	Certificate string `json:"certificate"`
+	Action      string `json:"action"`
+	Reason      string `json:"reason"`
+	At          string `json:"at"`
+}
+
+// Revocation status list of the certificates of a company, a certificate gets the next
+// free index of the list the first time it is exported as a verifiable credential
+type StatusList struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	CompanyID uuid.UUID `json:"company_id" gorm:"type:uuid;index"`
+	Size      int       `json:"size"`
+	NextIndex int       `json:"next_index"`
+}
@@ -0,0 +1,163 @@
+package model_company
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// get company by id
+func GetCompanyByID(db *gorm.DB, id uuid.UUID) (*Company, error) {
+	var c Company
+	if err := db.Where("id = ?", id).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// get company owned by the account or which the account is an active member of
+func GetCompanyByAccountID(db *gorm.DB, accountID uuid.UUID) (*Company, error) {
+	var c Company
+	memberOf := db.Model(&CompanyMember{}).
+		Select("company_id").
+		Where("account_id = ? AND status = ?", accountID, constant.ACTIVE)
+
+	if err := db.Where("account_id = ?", accountID).Or("id IN (?)", memberOf).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// whether the account owns the company or is an active member of it
+func IsAccountInCompany(db *gorm.DB, companyID uuid.UUID, accountID uuid.UUID) bool {
+	memberOf := db.Model(&CompanyMember{}).
+		Select("company_id").
+		Where("account_id = ? AND status = ?", accountID, constant.ACTIVE)
+
+	var count int64
+	if err := db.Model(&Company{}).
+		Where("id = ?", companyID).
+		Where(db.Where("account_id = ?", accountID).Or("id IN (?)", memberOf)).
+		Count(&count).Error; err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// update whether members of the company must use two-factor authentication
+func UpdateRequireMFA(db *gorm.DB, id uuid.UUID, require bool) error {
+	return db.Model(&Company{}).Where("id = ?", id).Update("require_mfa", require).Error
+}
+
+// ----------------- Company Member Functions -----------------
+
+// get member by id within a company
+func GetMemberByID(db *gorm.DB, companyID uuid.UUID, id uuid.UUID) (*CompanyMember, error) {
+	var m CompanyMember
+	if err := db.Where("company_id = ? AND id = ? AND status <> ?", companyID, id, constant.DELETED).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// get the active membership of an account
+func GetActiveMemberByAccountID(db *gorm.DB, accountID uuid.UUID) (*CompanyMember, error) {
+	var m CompanyMember
+	if err := db.Where("account_id = ? AND status = ?", accountID, constant.ACTIVE).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// get a pending invitation by the hash of its token
+func GetMemberByInviteToken(db *gorm.DB, hash string) (*CompanyMember, error) {
+	var m CompanyMember
+	if err := db.Where("invite_token = ? AND status = ?", hash, constant.PENDING).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// get the pending or active membership of an email within a company
+func GetMemberByEmail(db *gorm.DB, companyID uuid.UUID, email string) (*CompanyMember, error) {
+	var m CompanyMember
+	err := db.Where("company_id = ? AND email = ? AND status IN ?", companyID, email, []constant.Status{constant.PENDING, constant.ACTIVE}).
+		First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// list members of a company which are not removed
+func GetMembersByCompanyID(db *gorm.DB, companyID uuid.UUID) ([]CompanyMember, error) {
+	var members []CompanyMember
+	err := db.Where("company_id = ? AND status <> ?", companyID, constant.DELETED).
+		Order("created_at asc").
+		Find(&members).Error
+	return members, err
+}
+
+// count active owners of a company
+func CountOwners(db *gorm.DB, companyID uuid.UUID) int64 {
+	var count int64
+	db.Model(&CompanyMember{}).
+		Where("company_id = ? AND role = ? AND status = ?", companyID, constant.PROJECT_OWNER, constant.ACTIVE).
+		Count(&count)
+	return count
+}
+
+// update the role of a member
+func UpdateMemberRole(tx *gorm.DB, id uuid.UUID, role string) error {
+	return tx.Model(&CompanyMember{ID: id}).Update("role", role).Error
+}
+
+// update the status of a member
+func UpdateMemberStatus(tx *gorm.DB, id uuid.UUID, status constant.Status) error {
+	return tx.Model(&CompanyMember{ID: id}).Update("status", status).Error
+}
+
+// ----------------- Company Key Functions -----------------
+
+// get the key the company signs its new credentials with
+func GetActiveKey(db *gorm.DB, companyID uuid.UUID) (*CompanyKey, error) {
+	var k CompanyKey
+	if err := db.Where("company_id = ? AND status = ?", companyID, constant.ACTIVE).Order("created_at DESC").First(&k).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// get the key of the company by its kid, retired keys included
+func GetKeyByKeyID(db *gorm.DB, companyID uuid.UUID, keyID string) (*CompanyKey, error) {
+	var k CompanyKey
+	if err := db.Where("company_id = ? AND key_id = ?", companyID, keyID).First(&k).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// list the keys of the company, newest first
+func GetKeysByCompanyID(db *gorm.DB, companyID uuid.UUID) ([]CompanyKey, error) {
+	var k []CompanyKey
+	if err := db.Where("company_id = ?", companyID).Order("created_at DESC").Find(&k).Error; err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// create the active key of the company and retire the previous one
+func CreateKey(tx *gorm.DB, key *CompanyKey) error {
+	err := tx.Model(&CompanyKey{}).Where("company_id = ? AND status = ?", key.CompanyID, constant.ACTIVE).Updates(map[string]interface{}{
+		"status":     constant.INACTIVE,
+		"retired_at": time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	key.Status = constant.ACTIVE
+	return tx.Create(key).Error
+}
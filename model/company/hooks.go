@@ -0,0 +1,40 @@
+package model_company
+
+import (
+	"certification/utils"
+
+	"gorm.io/gorm"
+)
+
+const invalidateSessionsKey = "company_member:invalidate_sessions"
+
+// A member whose role or status changes must log in again
+func (m *CompanyMember) BeforeUpdate(tx *gorm.DB) error {
+	if tx.Statement.Changed("Role", "Status") {
+		tx.InstanceSet(invalidateSessionsKey, true)
+	}
+	return nil
+}
+
+func (m *CompanyMember) AfterUpdate(tx *gorm.DB) error {
+	if _, ok := tx.InstanceGet(invalidateSessionsKey); !ok {
+		return nil
+	}
+
+	accountID := m.AccountID
+	if accountID == nil {
+		var member CompanyMember
+		if err := tx.Session(&gorm.Session{NewDB: true}).Where("id = ?", m.ID).First(&member).Error; err != nil {
+			return nil
+		}
+		accountID = member.AccountID
+	}
+
+	// A pending invitation has no account and no sessions yet
+	if accountID == nil {
+		return nil
+	}
+
+	utils.InvalidateSessionsAfterCommit(tx, accountID.String())
+	return nil
+}
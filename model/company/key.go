@@ -0,0 +1,24 @@
+package model_company
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Key the company signs its verifiable credentials with. Retired keys stay in the DID
+// document of the company so the credentials they signed can still be verified.
+type CompanyKey struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	CompanyID  uuid.UUID       `json:"company_id" gorm:"type:uuid;index"`
+	KeyID      string          `json:"kid" gorm:"uniqueIndex"` // fragment of the verification method in the DID document
+	Algorithm  string          `json:"algorithm"`              // constant.ISSUER_KEY_ED25519 or constant.ISSUER_KEY_SECP256K1
+	PublicKey  string          `json:"public_key"`             // hex
+	PrivateKey string          `json:"-"`                      // hex, encrypted with utils.EncryptString
+	Status     constant.Status `json:"status"`                 // ACTIVE or INACTIVE once retired
+	RetiredAt  *time.Time      `json:"retired_at"`
+}
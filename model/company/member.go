@@ -0,0 +1,24 @@
+package model_company
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CompanyMember struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	CompanyID uuid.UUID       `json:"company_id" gorm:"type:uuid;index"`
+	AccountID *uuid.UUID      `json:"account_id" gorm:"type:uuid;index"` // set once the invitation is accepted
+	Email     string          `json:"email"`
+	Role      string          `json:"role"` // constant.PROJECT_OWNER or constant.PROJECT_ADMIN
+	Status    constant.Status `json:"status"`
+	InvitedBy *uuid.UUID      `json:"invited_by" gorm:"type:uuid"`
+
+	InviteToken    string    `json:"-"` // SHA-256 hash of the invitation token
+	InviteExpireAt time.Time `json:"-"`
+}
@@ -0,0 +1,55 @@
+package model_identity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// get identity by provider and subject
+func GetIdentityBySubject(db *gorm.DB, provider string, subject string) (*LinkedIdentity, error) {
+	var i LinkedIdentity
+	if err := db.Where("provider = ? AND subject = ?", provider, subject).First(&i).Error; err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// get identity of the account by id
+func GetIdentityByID(db *gorm.DB, accountID uuid.UUID, id uuid.UUID) (*LinkedIdentity, error) {
+	var i LinkedIdentity
+	if err := db.Where("id = ? AND account_id = ?", id, accountID).First(&i).Error; err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// get all identities of the account
+func GetIdentitiesByAccountID(db *gorm.DB, accountID uuid.UUID) ([]LinkedIdentity, error) {
+	var i []LinkedIdentity
+	if err := db.Where("account_id = ?", accountID).Order("created_at").Find(&i).Error; err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// count identities of the account
+func CountIdentities(db *gorm.DB, accountID uuid.UUID) int64 {
+	var count int64
+	db.Model(&LinkedIdentity{}).Where("account_id = ?", accountID).Count(&count)
+	return count
+}
+
+// record a login with the identity
+func UpdateLastLogin(db *gorm.DB, id uuid.UUID, email string) error {
+	return db.Model(&LinkedIdentity{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":         email,
+		"last_login_at": time.Now(),
+	}).Error
+}
+
+// unlink the identity
+func DeleteIdentity(db *gorm.DB, id uuid.UUID) error {
+	return db.Where("id = ?", id).Delete(&LinkedIdentity{}).Error
+}
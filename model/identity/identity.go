@@ -0,0 +1,20 @@
+package model_identity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account of an OpenID Connect provider linked to a local account
+type LinkedIdentity struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	AccountID   uuid.UUID  `json:"account_id" gorm:"type:uuid;index"`
+	Provider    string     `json:"provider" gorm:"uniqueIndex:idx_provider_subject"`
+	Subject     string     `json:"-" gorm:"uniqueIndex:idx_provider_subject"` // sub claim of the ID token
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
@@ -0,0 +1,121 @@
+package model_issuance
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// get jobs of the company, newest first
+func GetJobsByCompanyID(db *gorm.DB, companyID uuid.UUID) ([]IssuanceJob, error) {
+	var j []IssuanceJob
+	if err := db.Where("company_id = ?", companyID).Order("created_at DESC").Find(&j).Error; err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// get job by id within a company
+func GetJobByID(db *gorm.DB, companyID uuid.UUID, id uuid.UUID) (*IssuanceJob, error) {
+	var j IssuanceJob
+	if err := db.Where("company_id = ? AND id = ?", companyID, id).First(&j).Error; err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// get job by id regardless of its company
+func GetJobByIDUnscoped(db *gorm.DB, id uuid.UUID) (*IssuanceJob, error) {
+	var j IssuanceJob
+	if err := db.Where("id = ?", id).First(&j).Error; err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// get the ids of the jobs which are not finished, to resume them after a restart
+func GetUnfinishedJobIDs(db *gorm.DB) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := db.Model(&IssuanceJob{}).Where("status IN ?", []constant.Status{constant.PENDING, constant.ACTIVE}).Order("created_at").Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Mark a pending job as active, returns false when another worker already picked it up.
+// Active jobs are taken over as well since they were interrupted by a restart.
+func StartJob(db *gorm.DB, id uuid.UUID) (bool, error) {
+	result := db.Model(&IssuanceJob{}).Where("id = ? AND status IN ?", id, []constant.Status{constant.PENDING, constant.ACTIVE}).Update("status", constant.ACTIVE)
+	return result.RowsAffected > 0, result.Error
+}
+
+// recount the rows of the job
+func UpdateJobProgress(db *gorm.DB, job *IssuanceJob) error {
+	var issued, failed int64
+	if err := db.Model(&IssuanceRow{}).Where("job_id = ? AND status = ?", job.ID, constant.COMPLETED).Count(&issued).Error; err != nil {
+		return err
+	}
+	if err := db.Model(&IssuanceRow{}).Where("job_id = ? AND status = ?", job.ID, constant.FAILED).Count(&failed).Error; err != nil {
+		return err
+	}
+
+	job.Issued, job.Failed = int(issued), int(failed)
+	return db.Model(&IssuanceJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"issued": job.Issued,
+		"failed": job.Failed,
+	}).Error
+}
+
+// mark the job as finished with the given status
+func FinishJob(db *gorm.DB, job *IssuanceJob, status constant.Status) error {
+	now := time.Now()
+	job.Status, job.CompletedAt = status, &now
+	return db.Model(&IssuanceJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":       status,
+		"completed_at": now,
+	}).Error
+}
+
+// get the next rows of the job which are still pending
+func GetPendingRows(db *gorm.DB, jobID uuid.UUID, limit int) ([]IssuanceRow, error) {
+	var r []IssuanceRow
+	if err := db.Where("job_id = ? AND status = ?", jobID, constant.PENDING).Order("row").Limit(limit).Find(&r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// get the rows of the job, optionally only those with the given status
+func GetRows(db *gorm.DB, jobID uuid.UUID, status constant.Status) ([]IssuanceRow, error) {
+	query := db.Where("job_id = ?", jobID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var r []IssuanceRow
+	if err := query.Order("row").Find(&r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Mark the pending row as completed, returns false when another worker already handled it.
+// Within a transaction the row stays locked until the certificate is recorded with SetRowCertificate.
+func ClaimRow(tx *gorm.DB, id uint) (bool, error) {
+	result := tx.Model(&IssuanceRow{}).Where("id = ? AND status = ?", id, constant.PENDING).Update("status", constant.COMPLETED)
+	return result.RowsAffected > 0, result.Error
+}
+
+// record the certificate issued for the row
+func SetRowCertificate(tx *gorm.DB, id uint, certificateID uuid.UUID) error {
+	return tx.Model(&IssuanceRow{}).Where("id = ?", id).Update("certificate_id", certificateID).Error
+}
+
+// record why the row could not be issued
+func FailRow(db *gorm.DB, id uint, reason string) error {
+	return db.Model(&IssuanceRow{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status": constant.FAILED,
+		"error":  reason,
+	}).Error
+}
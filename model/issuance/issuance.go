@@ -0,0 +1,40 @@
+package model_issuance
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bulk issuance of an uploaded file. Jobs are PENDING until a worker picks them up,
+// ACTIVE while issuing and COMPLETED or FAILED once every row was handled.
+type IssuanceJob struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	CompanyID   uuid.UUID       `json:"company_id" gorm:"type:uuid;index"`
+	CreatedBy   uuid.UUID       `json:"created_by" gorm:"type:uuid"`
+	TemplateID  uuid.UUID       `json:"template_id" gorm:"type:uuid"`
+	VersionID   uuid.UUID       `json:"version_id" gorm:"type:uuid"`
+	FileName    string          `json:"file_name"`
+	Status      constant.Status `json:"status" gorm:"index"`
+	Total       int             `json:"total"`
+	Issued      int             `json:"issued"`
+	Failed      int             `json:"failed"`
+	CompletedAt *time.Time      `json:"completed_at"`
+}
+
+// Row of the uploaded file, validated before the job is created
+type IssuanceRow struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	JobID          uuid.UUID       `json:"job_id" gorm:"type:uuid;index"`
+	Row            int             `json:"row"` // line in the file, the header is row 1
+	RecipientEmail string          `json:"recipient_email"`
+	Values         string          `json:"-"` // JSON of the normalized values
+	Status         constant.Status `json:"status"`
+	CertificateID  *uuid.UUID      `json:"certificate_id" gorm:"type:uuid"`
+	Error          string          `json:"error"`
+}
@@ -0,0 +1,81 @@
+package model_mfa
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// get mfa by account id
+func GetMFAByAccountID(db *gorm.DB, accountID uuid.UUID) (*MFA, error) {
+	var m MFA
+	if err := db.Where("account_id = ?", accountID).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// check whether the account has confirmed two-factor authentication
+func IsMFAEnabled(db *gorm.DB, accountID uuid.UUID) bool {
+	m, err := GetMFAByAccountID(db, accountID)
+	return err == nil && m.Enabled
+}
+
+// create or replace the pending secret of the account
+func SavePendingMFA(db *gorm.DB, accountID uuid.UUID, secret string) error {
+	m, err := GetMFAByAccountID(db, accountID)
+	if err != nil {
+		return db.Create(&MFA{AccountID: accountID, Secret: secret}).Error
+	}
+	return db.Model(m).Update("secret", secret).Error
+}
+
+// mark the mfa as enabled
+func EnableMFA(tx *gorm.DB, accountID uuid.UUID, step int64) error {
+	return tx.Model(&MFA{}).Where("account_id = ?", accountID).Updates(map[string]interface{}{
+		"enabled":        true,
+		"confirmed_at":   time.Now(),
+		"last_used_step": step,
+	}).Error
+}
+
+// record the last accepted time step, fails if the step was already used
+func UpdateLastUsedStep(db *gorm.DB, accountID uuid.UUID, step int64) bool {
+	result := db.Model(&MFA{}).
+		Where("account_id = ? AND last_used_step < ?", accountID, step).
+		Update("last_used_step", step)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+// remove mfa and recovery codes of the account
+func DeleteMFA(tx *gorm.DB, accountID uuid.UUID) error {
+	if err := tx.Where("account_id = ?", accountID).Delete(&RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("account_id = ?", accountID).Delete(&MFA{}).Error
+}
+
+// replace the recovery codes of the account with the given hashes
+func ReplaceRecoveryCodes(tx *gorm.DB, accountID uuid.UUID, hashes []string) error {
+	if err := tx.Where("account_id = ?", accountID).Delete(&RecoveryCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]RecoveryCode, len(hashes))
+	for i, hash := range hashes {
+		codes[i] = RecoveryCode{
+			AccountID: accountID,
+			Code:      hash,
+		}
+	}
+	return tx.Create(&codes).Error
+}
+
+// consume an unused recovery code, returns false if no code matches
+func UseRecoveryCode(db *gorm.DB, accountID uuid.UUID, hash string) bool {
+	result := db.Model(&RecoveryCode{}).
+		Where("account_id = ? AND code = ? AND used_at IS NULL", accountID, hash).
+		Update("used_at", time.Now())
+	return result.Error == nil && result.RowsAffected == 1
+}
@@ -0,0 +1,28 @@
+package model_mfa
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MFA struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	AccountID    uuid.UUID  `json:"account_id" gorm:"type:uuid;uniqueIndex"`
+	Secret       string     `json:"-"` // encrypted TOTP secret
+	Enabled      bool       `json:"enabled"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `json:"-"` // last accepted TOTP time step, a code cannot be replayed
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	AccountID uuid.UUID  `json:"account_id" gorm:"type:uuid;index"`
+	Code      string     `json:"-"` // SHA-256 hash of the code
+	UsedAt    *time.Time `json:"used_at"`
+}
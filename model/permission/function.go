@@ -0,0 +1,179 @@
+package model_permission
+
+import (
+	"certification/constant"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ----------------- Seed Functions -----------------
+
+// create the modules and the default roles if they don't exist yet
+func SeedPermissions(db *gorm.DB) error {
+	existing, err := GetModules(db)
+	if err != nil {
+		return err
+	}
+	isNew := func(moduleID uint) bool {
+		for _, m := range existing {
+			if m.ID == moduleID {
+				return false
+			}
+		}
+		return true
+	}
+
+	modules := []Module{
+		{ID: constant.ACCOUNT, Name: "account"},
+		{ID: constant.WALLET, Name: "wallet"},
+		{ID: constant.PROFILE, Name: "profile"},
+		{ID: constant.CERTIFICATE, Name: "certificate"},
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&modules).Error; err != nil {
+		return err
+	}
+
+	full := func(moduleID uint) Permission {
+		return Permission{ModuleID: moduleID, ModuleAccess: true, ReadAccess: true, WriteAccess: true, DeleteAccess: true}
+	}
+	readWrite := func(moduleID uint) Permission {
+		return Permission{ModuleID: moduleID, ModuleAccess: true, ReadAccess: true, WriteAccess: true}
+	}
+	read := func(moduleID uint) Permission {
+		return Permission{ModuleID: moduleID, ModuleAccess: true, ReadAccess: true}
+	}
+
+	// Default roles are named after the account role type, see GetRoleForAccount
+	roles := []Role{
+		{
+			Name:        constant.ROLE_NAME_ADMIN,
+			Description: "Full access to every module",
+			Permissions: []Permission{full(constant.ACCOUNT), full(constant.WALLET), full(constant.PROFILE), full(constant.CERTIFICATE)},
+		},
+		{
+			Name:        string(constant.ROLE_COMPANY),
+			Description: "Default role of company accounts",
+			Permissions: []Permission{readWrite(constant.WALLET), readWrite(constant.PROFILE), full(constant.CERTIFICATE)},
+		},
+		{
+			Name:        string(constant.ROLE_USER),
+			Description: "Default role of user accounts",
+			Permissions: []Permission{readWrite(constant.WALLET), readWrite(constant.PROFILE), read(constant.CERTIFICATE)},
+		},
+	}
+
+	for i := range roles {
+		role, err := GetRoleByName(db, roles[i].Name)
+		if err != nil {
+			if err := db.Create(&roles[i]).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Existing default roles only receive the grants of modules added since the last seed,
+		// grants an admin removed on purpose are not restored. Hooks are skipped so that
+		// seeding on start does not invalidate the sessions of the role.
+		for _, p := range roles[i].Permissions {
+			if !isNew(p.ModuleID) {
+				continue
+			}
+			p.RoleID = role.ID
+			if err := db.Session(&gorm.Session{SkipHooks: true}).Clauses(clause.OnConflict{DoNothing: true}).Create(&p).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ----------------- Role Functions -----------------
+
+// get all roles with their permissions
+func GetRoles(db *gorm.DB) ([]Role, error) {
+	var r []Role
+	if err := db.Preload("Permissions").Order("id").Find(&r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// get role by id with its permissions
+func GetRoleByID(db *gorm.DB, id uint) (*Role, error) {
+	var r Role
+	if err := db.Preload("Permissions").Where("id = ?", id).First(&r).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// get role by name
+func GetRoleByName(db *gorm.DB, name string) (*Role, error) {
+	var r Role
+	if err := db.Where("name = ?", name).First(&r).Error; err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// the assigned role of the account, or the default role named after its role type
+func GetRoleForAccount(db *gorm.DB, roleID *uint, roleType constant.AccountRoleType) (*Role, error) {
+	if roleID != nil {
+		return GetRoleByID(db, *roleID)
+	}
+	r, err := GetRoleByName(db, string(roleType))
+	if err != nil {
+		return nil, err
+	}
+	return GetRoleByID(db, r.ID)
+}
+
+// update name and description of the role
+func UpdateRole(db *gorm.DB, id uint, name string, description string) error {
+	return db.Model(&Role{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"name":        name,
+		"description": description,
+	}).Error
+}
+
+// delete the role and its permissions
+func DeleteRole(tx *gorm.DB, id uint) error {
+	if err := tx.Where("role_id = ?", id).Delete(&Permission{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id = ?", id).Delete(&Role{}).Error
+}
+
+// ----------------- Module Functions -----------------
+
+// get all modules
+func GetModules(db *gorm.DB) ([]Module, error) {
+	var m []Module
+	if err := db.Order("id").Find(&m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// check whether the module exists
+func IsModuleExist(db *gorm.DB, id uint) bool {
+	var count int64
+	db.Model(&Module{}).Where("id = ?", id).Count(&count)
+	return count > 0
+}
+
+// ----------------- Permission Functions -----------------
+
+// create or update the grant of the role on the module
+func UpsertPermission(tx *gorm.DB, p *Permission) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "role_id"}, {Name: "module_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"module_access", "read_access", "write_access", "delete_access", "updated_at"}),
+	}).Create(p).Error
+}
+
+// remove the grant of the role on the module
+func DeletePermission(db *gorm.DB, roleID uint, moduleID uint) error {
+	return db.Where("role_id = ? AND module_id = ?", roleID, moduleID).Delete(&Permission{RoleID: roleID}).Error
+}
@@ -0,0 +1,40 @@
+package model_permission
+
+import (
+	"certification/logger"
+	"certification/utils"
+
+	"gorm.io/gorm"
+)
+
+// Every account using the role gets the new grants on its next login
+func (p *Permission) AfterSave(tx *gorm.DB) error {
+	InvalidateRoleSessions(tx, p.RoleID)
+	return nil
+}
+
+func (p *Permission) AfterDelete(tx *gorm.DB) error {
+	InvalidateRoleSessions(tx, p.RoleID)
+	return nil
+}
+
+// Invalidate the sessions of the accounts assigned to the role, and of the accounts
+// without a role when it is the default role of their role type, once db is committed
+func InvalidateRoleSessions(db *gorm.DB, roleID uint) {
+	if roleID == 0 {
+		return
+	}
+
+	query := db.Session(&gorm.Session{NewDB: true})
+
+	var accountIDs []string
+	err := query.Table("account").
+		Where("role_id = ? OR (role_id IS NULL AND role = (?))", roleID, query.Model(&Role{}).Select("name").Where("id = ?", roleID)).
+		Pluck("id", &accountIDs).Error
+	if err != nil {
+		logger.Log.Error(err)
+		return
+	}
+
+	utils.InvalidateSessionsAfterCommit(db, accountIDs...)
+}
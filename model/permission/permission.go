@@ -0,0 +1,34 @@
+package model_permission
+
+import (
+	"time"
+)
+
+type Role struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Name        string `json:"name" gorm:"uniqueIndex"`
+	Description string `json:"description"`
+
+	Permissions []Permission `json:"permissions" gorm:"foreignKey:RoleID"`
+}
+
+type Module struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name" gorm:"uniqueIndex"`
+}
+
+type Permission struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	RoleID       uint `json:"role_id" gorm:"uniqueIndex:idx_role_module"`
+	ModuleID     uint `json:"module_id" gorm:"uniqueIndex:idx_role_module"`
+	ModuleAccess bool `json:"module_access"`
+	ReadAccess   bool `json:"read_access"`
+	WriteAccess  bool `json:"write_access"`
+	DeleteAccess bool `json:"delete_access"`
+}
@@ -0,0 +1,188 @@
+package model_template
+
+import (
+	"certification/constant"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ----------------- Template Functions -----------------
+
+// get templates of the company which are not deleted
+func GetTemplatesByCompanyID(db *gorm.DB, companyID uuid.UUID) ([]CertificateTemplate, error) {
+	var t []CertificateTemplate
+	if err := db.Where("company_id = ? AND status <> ?", companyID, constant.DELETED).Order("created_at DESC").Find(&t).Error; err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// get template by id within a company
+func GetTemplateByID(db *gorm.DB, companyID uuid.UUID, id uuid.UUID) (*CertificateTemplate, error) {
+	var t CertificateTemplate
+	if err := db.Where("company_id = ? AND id = ? AND status <> ?", companyID, id, constant.DELETED).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// get template by id regardless of its company and status
+func GetTemplateByIDUnscoped(db *gorm.DB, id uuid.UUID) (*CertificateTemplate, error) {
+	var t CertificateTemplate
+	if err := db.Where("id = ?", id).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// update name and description of the template, the fields are changed through a new version
+func UpdateTemplate(db *gorm.DB, id uuid.UUID, name string, description string) error {
+	return db.Model(&CertificateTemplate{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"name":        name,
+		"description": description,
+	}).Error
+}
+
+// mark the template as deleted, its versions are kept for the certificates issued with them
+func DeleteTemplate(db *gorm.DB, id uuid.UUID) error {
+	return db.Model(&CertificateTemplate{}).Where("id = ?", id).Update("status", constant.DELETED).Error
+}
+
+// ----------------- Version Functions -----------------
+
+// create the next version of the template with the given fields
+func CreateVersion(tx *gorm.DB, template *CertificateTemplate, createdBy uuid.UUID, fields []TemplateField) (*TemplateVersion, error) {
+	version := TemplateVersion{
+		TemplateID: template.ID,
+		Version:    template.LatestVersion + 1,
+		CreatedBy:  createdBy,
+		Fields:     fields,
+	}
+	if err := tx.Create(&version).Error; err != nil {
+		return nil, err
+	}
+
+	// The condition guards against two concurrent edits creating the same version
+	result := tx.Model(&CertificateTemplate{}).
+		Where("id = ? AND latest_version = ?", template.ID, template.LatestVersion).
+		Update("latest_version", version.Version)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	template.LatestVersion = version.Version
+	return &version, nil
+}
+
+// get a version of the template with its fields in order
+func GetVersion(db *gorm.DB, templateID uuid.UUID, version int) (*TemplateVersion, error) {
+	var v TemplateVersion
+	if err := db.Preload("Fields", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("template_id = ? AND version = ?", templateID, version).First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// get version by id with its fields in order
+func GetVersionByID(db *gorm.DB, id uuid.UUID) (*TemplateVersion, error) {
+	var v TemplateVersion
+	if err := db.Preload("Fields", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("id = ?", id).First(&v).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// get all versions of the template without their fields
+func GetVersions(db *gorm.DB, templateID uuid.UUID) ([]TemplateVersion, error) {
+	var v []TemplateVersion
+	if err := db.Where("template_id = ?", templateID).Order("version DESC").Find(&v).Error; err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ----------------- Layout Functions -----------------
+
+// get the layout of the template with its elements in order
+func GetLayout(db *gorm.DB, templateID uuid.UUID) (*TemplateLayout, error) {
+	var l TemplateLayout
+	if err := db.Preload("Elements", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Where("template_id = ?", templateID).First(&l).Error; err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// create or replace the layout of the template, the background is kept
+func SaveLayout(tx *gorm.DB, templateID uuid.UUID, width int, height int, backgroundColor string, elements []LayoutElement) (*TemplateLayout, error) {
+	layout, err := getOrCreateLayout(tx, templateID, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("layout_id = ?", layout.ID).Delete(&LayoutElement{}).Error; err != nil {
+		return nil, err
+	}
+	for i := range elements {
+		elements[i].LayoutID = layout.ID
+	}
+	if len(elements) > 0 {
+		if err := tx.Create(&elements).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	layout.Width = width
+	layout.Height = height
+	layout.BackgroundColor = backgroundColor
+	layout.Revision++
+	layout.Elements = elements
+	if err := tx.Model(&TemplateLayout{}).Where("id = ?", layout.ID).Updates(map[string]interface{}{
+		"width":            width,
+		"height":           height,
+		"background_color": backgroundColor,
+		"revision":         gorm.Expr("revision + 1"),
+	}).Error; err != nil {
+		return nil, err
+	}
+	return layout, nil
+}
+
+// set the background image of the template, a layout of the size of the image is created when there is none
+func SetBackground(tx *gorm.DB, templateID uuid.UUID, key string, width int, height int) (*TemplateLayout, error) {
+	layout, err := getOrCreateLayout(tx, templateID, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	layout.Background = key
+	layout.Revision++
+	if err := tx.Model(&TemplateLayout{}).Where("id = ?", layout.ID).Updates(map[string]interface{}{
+		"background": key,
+		"revision":   gorm.Expr("revision + 1"),
+	}).Error; err != nil {
+		return nil, err
+	}
+	return layout, nil
+}
+
+func getOrCreateLayout(tx *gorm.DB, templateID uuid.UUID, width int, height int) (*TemplateLayout, error) {
+	layout := TemplateLayout{
+		TemplateID: templateID,
+		Width:      width,
+		Height:     height,
+	}
+	if err := tx.Where("template_id = ?", templateID).FirstOrCreate(&layout).Error; err != nil {
+		return nil, err
+	}
+	return &layout, nil
+}
@@ -0,0 +1,88 @@
+package model_template
+
+import (
+	"certification/constant"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CertificateTemplate struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	CompanyID     uuid.UUID       `json:"company_id" gorm:"type:uuid;index"`
+	CreatedBy     uuid.UUID       `json:"created_by" gorm:"type:uuid"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	LatestVersion int             `json:"latest_version"`
+	Status        constant.Status `json:"status"`
+}
+
+// Snapshot of the fields of a template. Versions are never updated, changing the fields
+// creates a new version so that issued certificates keep the schema they were issued with.
+type TemplateVersion struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	TemplateID uuid.UUID `json:"template_id" gorm:"type:uuid;uniqueIndex:idx_template_version"`
+	Version    int       `json:"version" gorm:"uniqueIndex:idx_template_version"`
+	CreatedBy  uuid.UUID `json:"created_by" gorm:"type:uuid"`
+
+	Fields []TemplateField `json:"fields" gorm:"foreignKey:VersionID"`
+}
+
+type TemplateField struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	VersionID    uuid.UUID          `json:"version_id" gorm:"type:uuid;uniqueIndex:idx_version_key"`
+	Key          string             `json:"key" gorm:"uniqueIndex:idx_version_key"`
+	Position     int                `json:"position"`
+	Label        string             `json:"label"`
+	Type         constant.FieldType `json:"type"`
+	Required     bool               `json:"required"`
+	DefaultValue *string            `json:"default_value"` // normalized the same way as issued values
+
+	// Validation rules, MinLength, MaxLength and Pattern apply to text, Min and Max to number
+	MinLength *int     `json:"min_length"`
+	MaxLength *int     `json:"max_length"`
+	Min       *float64 `json:"min"`
+	Max       *float64 `json:"max"`
+	Pattern   string   `json:"pattern"`
+}
+
+// Layout used to render the certificates of a template. Unlike the fields it is not versioned,
+// every change bumps the revision so that the certificates are rendered again with it.
+type TemplateLayout struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	TemplateID      uuid.UUID `json:"template_id" gorm:"type:uuid;uniqueIndex"`
+	Width           int       `json:"width"`  // pixels
+	Height          int       `json:"height"` // pixels
+	BackgroundColor string    `json:"background_color"`
+	Background      string    `json:"-"` // storage key of the background image, empty without one
+	Revision        int       `json:"revision"`
+
+	Elements []LayoutElement `json:"elements" gorm:"foreignKey:LayoutID"`
+}
+
+// Text or QR code drawn on the certificate. Text may contain {{key}} placeholders
+// replaced by the field values or code, recipient_name, issuer_name, template_name and issued_at.
+type LayoutElement struct {
+	ID uint `json:"id" gorm:"primaryKey"`
+
+	LayoutID uuid.UUID            `json:"layout_id" gorm:"type:uuid;index"`
+	Position int                  `json:"position"`
+	Type     constant.ElementType `json:"type"`
+	Text     string               `json:"text"`
+	Font     string               `json:"font"`
+	FontSize float64              `json:"font_size"`
+	Color    string               `json:"color"`
+	Align    constant.Align       `json:"align"`
+	X        float64              `json:"x"`     // left of the element, or its center or right depending on the alignment
+	Y        float64              `json:"y"`     // top of the element
+	Width    float64              `json:"width"` // wraps the text when set, size of the QR code
+}
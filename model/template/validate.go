@@ -0,0 +1,174 @@
+package model_template
+
+import (
+	"certification/constant"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// check that the validation rules fit the type of the field and the default value passes them
+func (f *TemplateField) ValidateRules() error {
+	switch f.Type {
+	case constant.TEXT:
+		if f.Min != nil || f.Max != nil {
+			return fmt.Errorf("field %s: min and max only apply to number fields", f.Key)
+		}
+		if f.MinLength != nil && f.MaxLength != nil && *f.MinLength > *f.MaxLength {
+			return fmt.Errorf("field %s: min_length is greater than max_length", f.Key)
+		}
+		if f.Pattern != "" {
+			if _, err := regexp.Compile(f.Pattern); err != nil {
+				return fmt.Errorf("field %s: invalid pattern: %v", f.Key, err)
+			}
+		}
+	case constant.NUMBER:
+		if f.MinLength != nil || f.MaxLength != nil || f.Pattern != "" {
+			return fmt.Errorf("field %s: min_length, max_length and pattern only apply to text fields", f.Key)
+		}
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("field %s: min is greater than max", f.Key)
+		}
+	case constant.BOOLEAN, constant.DATE, constant.DATETIME:
+		if f.MinLength != nil || f.MaxLength != nil || f.Pattern != "" || f.Min != nil || f.Max != nil {
+			return fmt.Errorf("field %s: %s fields have no validation rules", f.Key, f.Type)
+		}
+	default:
+		return fmt.Errorf("field %s: unknown type %s", f.Key, f.Type)
+	}
+
+	if f.DefaultValue != nil {
+		value, err := f.Normalize(*f.DefaultValue)
+		if err != nil {
+			return fmt.Errorf("default value of %w", err)
+		}
+		f.DefaultValue = &value
+	}
+	return nil
+}
+
+// Validate the value against the type and rules of the field and return it in its stored form:
+// numbers without trailing zeros, booleans as true/false, dates as 2006-01-02 and datetimes as RFC 3339 in UTC.
+// Strings are accepted for every type so that values from CSV files can be validated the same way.
+func (f *TemplateField) Normalize(value interface{}) (string, error) {
+	switch f.Type {
+	case constant.TEXT:
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("field %s must be a text", f.Key)
+		}
+		length := utf8.RuneCountInString(s)
+		if f.MinLength != nil && length < *f.MinLength {
+			return "", fmt.Errorf("field %s must be at least %d characters", f.Key, *f.MinLength)
+		}
+		if f.MaxLength != nil && length > *f.MaxLength {
+			return "", fmt.Errorf("field %s must be at most %d characters", f.Key, *f.MaxLength)
+		}
+		if f.Pattern != "" {
+			re, err := regexp.Compile(f.Pattern)
+			if err != nil || !re.MatchString(s) {
+				return "", fmt.Errorf("field %s does not match the pattern %s", f.Key, f.Pattern)
+			}
+		}
+		return s, nil
+
+	case constant.NUMBER:
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case int:
+			n = float64(v)
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return "", fmt.Errorf("field %s must be a number", f.Key)
+			}
+			n = parsed
+		default:
+			return "", fmt.Errorf("field %s must be a number", f.Key)
+		}
+		if f.Min != nil && n < *f.Min {
+			return "", fmt.Errorf("field %s must be at least %v", f.Key, *f.Min)
+		}
+		if f.Max != nil && n > *f.Max {
+			return "", fmt.Errorf("field %s must be at most %v", f.Key, *f.Max)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+
+	case constant.BOOLEAN:
+		switch v := value.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return "", fmt.Errorf("field %s must be true or false", f.Key)
+			}
+			return strconv.FormatBool(b), nil
+		}
+		return "", fmt.Errorf("field %s must be true or false", f.Key)
+
+	case constant.DATE:
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("field %s must be a date formatted as %s", f.Key, constant.DATE_FORMAT)
+		}
+		d, err := time.Parse(constant.DATE_FORMAT, strings.TrimSpace(s))
+		if err != nil {
+			return "", fmt.Errorf("field %s must be a date formatted as %s", f.Key, constant.DATE_FORMAT)
+		}
+		return d.Format(constant.DATE_FORMAT), nil
+
+	case constant.DATETIME:
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("field %s must be a datetime formatted as RFC 3339", f.Key)
+		}
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(s))
+		if err != nil {
+			return "", fmt.Errorf("field %s must be a datetime formatted as RFC 3339", f.Key)
+		}
+		return t.UTC().Format(time.RFC3339), nil
+	}
+
+	return "", fmt.Errorf("field %s: unknown type %s", f.Key, f.Type)
+}
+
+// Validate the values against the fields of the version. Unknown keys are rejected,
+// missing values take the default of the field and a missing required value is an error.
+func (v *TemplateVersion) NormalizeValues(values map[string]interface{}) (map[string]string, error) {
+	known := make(map[string]bool, len(v.Fields))
+	normalized := make(map[string]string, len(v.Fields))
+
+	for i := range v.Fields {
+		field := &v.Fields[i]
+		known[field.Key] = true
+
+		value, ok := values[field.Key]
+		if !ok || value == nil || value == "" {
+			if field.DefaultValue != nil {
+				normalized[field.Key] = *field.DefaultValue
+			} else if field.Required {
+				return nil, fmt.Errorf("field %s is required", field.Key)
+			}
+			continue
+		}
+
+		s, err := field.Normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[field.Key] = s
+	}
+
+	for key := range values {
+		if !known[key] {
+			return nil, fmt.Errorf("field %s is not part of the template", key)
+		}
+	}
+	return normalized, nil
+}
@@ -3,15 +3,74 @@ package model_token
 import (
 	"certification/constant"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Get token by token string and token type
+func GetTokenByToken(db *gorm.DB, token string, tokenType string) (*Token, error) {
+	var t Token
+	if err := db.Where("token = ? AND type = ?", token, tokenType).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Get the newest token of a type and purpose for an account
+func GetLatestToken(db *gorm.DB, accountID uuid.UUID, tokenType string, purpose string) (*Token, error) {
+	var t Token
+	err := db.Where("account_id = ? AND type = ? AND purpose = ?", accountID, tokenType, purpose).
+		Order("created_at desc").
+		First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // Update the token status to used
 func UpdateTokenStatus(token string, tx *gorm.DB) error {
-	err := tx.Model(&Token{}).Where("token = ?", token).Update("status", constant.USED).Error
-	if err != nil {
-		return err
+	// Only a pending token can be used, so two concurrent requests cannot both use it
+	result := tx.Model(&Token{}).Where("token = ? AND status = ?", token, constant.PENDING).Update("status", constant.USED)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
 
 	return nil
 }
+
+// Deactivate all pending tokens of a type for an account, so only the newest one can be used
+func SupersedeTokens(accountID uuid.UUID, tokenType string, tx *gorm.DB) error {
+	return tx.Model(&Token{}).
+		Where("account_id = ? AND type = ? AND status = ?", accountID, tokenType, constant.PENDING).
+		Update("status", constant.INACTIVE).Error
+}
+
+// Deactivate pending tokens of a type and purpose for an account
+func SupersedeTokensByPurpose(accountID uuid.UUID, tokenType string, purpose string, tx *gorm.DB) error {
+	return tx.Model(&Token{}).
+		Where("account_id = ? AND type = ? AND purpose = ? AND status = ?", accountID, tokenType, purpose, constant.PENDING).
+		Update("status", constant.INACTIVE).Error
+}
+
+// Update the status of a single token
+func UpdateTokenStatusByID(id uuid.UUID, status constant.Status, tx *gorm.DB) error {
+	return tx.Model(&Token{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// Increase the failed attempts of a token and return the new count
+func IncrementTokenAttempts(id uuid.UUID, tx *gorm.DB) (int, error) {
+	var t Token
+	err := tx.Model(&t).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "attempts"}}}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+	if err != nil {
+		return 0, err
+	}
+	return t.Attempts, nil
+}
@@ -16,5 +16,7 @@ type Token struct {
 	Token     string          `json:"token"`
 	ExpireAt  time.Time       `json:"expire_at"`
 	Type      string          `json:"type"`
+	Purpose   string          `json:"purpose"`
+	Attempts  int             `json:"attempts"`
 	Status    constant.Status `json:"status"`
 }
@@ -0,0 +1,32 @@
+package model_user
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// get user by account id
+func GetUserByAccountID(db *gorm.DB, accountID uuid.UUID) (*User, error) {
+	var u User
+	if err := db.Where("account_id = ?", accountID).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// get user by id
+func GetUserByID(db *gorm.DB, id uuid.UUID) (*User, error) {
+	var u User
+	if err := db.Where("id = ?", id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// full name of the user
+func (u *User) FullName() string {
+	if u.LastName == "" {
+		return u.FirstName
+	}
+	return u.FirstName + " " + u.LastName
+}
@@ -0,0 +1,43 @@
+package model_wallet
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// get wallet by address
+func GetWalletByAddress(db *gorm.DB, address string) (*Wallet, error) {
+	var w Wallet
+	if err := db.Where("address = ?", address).First(&w).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// get wallet of the account by id
+func GetWalletByID(db *gorm.DB, accountID uuid.UUID, id uuid.UUID) (*Wallet, error) {
+	var w Wallet
+	if err := db.Where("id = ? AND account_id = ?", id, accountID).First(&w).Error; err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// get all wallets of the account
+func GetWalletsByAccountID(db *gorm.DB, accountID uuid.UUID) ([]Wallet, error) {
+	var w []Wallet
+	if err := db.Where("account_id = ?", accountID).Order("created_at").Find(&w).Error; err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// allow or forbid logging in with the wallet alone
+func UpdateAllowLogin(db *gorm.DB, id uuid.UUID, allowLogin bool) error {
+	return db.Model(&Wallet{}).Where("id = ?", id).Update("allow_login", allowLogin).Error
+}
+
+// unlink the wallet
+func DeleteWallet(db *gorm.DB, id uuid.UUID) error {
+	return db.Where("id = ?", id).Delete(&Wallet{}).Error
+}
@@ -0,0 +1,20 @@
+package model_wallet
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ethereum address whose ownership was proven with a signed message
+type Wallet struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;unique;default: gen_random_uuid();"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+
+	AccountID  uuid.UUID `json:"account_id" gorm:"type:uuid;index"`
+	Address    string    `json:"address" gorm:"uniqueIndex"` // EIP-55 checksummed
+	ChainID    int64     `json:"chain_id"`
+	AllowLogin bool      `json:"allow_login"` // the wallet alone can log in to the account
+	VerifiedAt time.Time `json:"verified_at"`
+}
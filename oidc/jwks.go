@@ -0,0 +1,49 @@
+package oidc
+
+import (
+	"certification/jwtkey"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Public keys of a provider by key ID, fetched again when an unknown key ID is seen
+type KeySet struct {
+	uri  string
+	keys map[string]interface{}
+	mu   sync.Mutex
+}
+
+func NewKeySet(uri string) *KeySet {
+	return &KeySet{uri: uri, keys: map[string]interface{}{}}
+}
+
+func (s *KeySet) Key(ctx context.Context, kid string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+
+	var jwks jwtkey.JWKS
+	if err := getJSON(ctx, s.uri, &jwks); err != nil {
+		return nil, fmt.Errorf("unable to fetch jwks: %v", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range jwks.Keys {
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	s.keys = keys
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %s", kid)
+	}
+	return key, nil
+}
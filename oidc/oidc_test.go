@@ -0,0 +1,369 @@
+package oidc
+
+import (
+	"certification/config"
+	"certification/jwtkey"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	testClientID = "client-id"
+	testCode     = "authorization-code"
+)
+
+// Identity provider serving discovery, JWKS and a token endpoint that checks the PKCE verifier
+type stubProvider struct {
+	t      *testing.T
+	name   string
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	mu           sync.Mutex
+	issuer       string // issuer published in the discovery document
+	challenge    string // code_challenge of the last authorization request
+	nonce        string // nonce of the last authorization request
+	jwksRequests int
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &stubProvider{t: t, name: strings.ToLower(t.Name()), key: key, kid: "key-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	p.issuer = p.server.URL
+	config.OIDC_REDIRECT_URL = "https://app.example.com/oidc/callback"
+	config.OIDC_PROVIDERS[p.name] = config.OIDCProvider{
+		Name:         p.name,
+		Issuer:       p.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: "client-secret",
+		Scopes:       []string{"openid", "email", "profile"},
+	}
+	t.Cleanup(func() {
+		delete(config.OIDC_PROVIDERS, p.name)
+		providersMu.Lock()
+		delete(providers, p.name)
+		providersMu.Unlock()
+	})
+
+	return p
+}
+
+func (p *stubProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	json.NewEncoder(w).Encode(Discovery{
+		Issuer:                p.issuer,
+		AuthorizationEndpoint: p.server.URL + "/authorize",
+		TokenEndpoint:         p.server.URL + "/token",
+		JWKSURI:               p.server.URL + "/jwks",
+	})
+}
+
+func (p *stubProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.jwksRequests++
+
+	jwk, err := jwtkey.NewJWK(p.kid, "RS256", &p.key.PublicKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(jwtkey.JWKS{Keys: []jwtkey.JWK{jwk}})
+}
+
+func (p *stubProvider) token(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("code") != testCode || base64.RawURLEncoding.EncodeToString(sum[:]) != p.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     p.sign(p.idTokenClaims(), p.kid),
+	})
+}
+
+// Claims of the ID token for the last authorization request
+func (p *stubProvider) idTokenClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            p.server.URL,
+		"aud":            testClientID,
+		"sub":            "subject-1",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"given_name":     "Jane",
+		"family_name":    "Doe",
+		"nonce":          p.nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Minute).Unix(),
+	}
+}
+
+func (p *stubProvider) sign(claims jwt.MapClaims, kid string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return signed
+}
+
+// Follow the authorization URL the way the browser would, the provider keeps the challenge and nonce
+func (p *stubProvider) authorize(authURL string) url.Values {
+	p.t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	query := u.Query()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.challenge = query.Get("code_challenge")
+	p.nonce = query.Get("nonce")
+	return query
+}
+
+func TestGetProviderDiscovery(t *testing.T) {
+	stub := newStubProvider(t)
+
+	provider, err := GetProvider(context.Background(), stub.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.Discovery.TokenEndpoint != stub.server.URL+"/token" || provider.Discovery.JWKSURI != stub.server.URL+"/jwks" {
+		t.Fatalf("unexpected discovery %+v", provider.Discovery)
+	}
+
+	cached, err := GetProvider(context.Background(), stub.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != provider {
+		t.Fatal("discovery document was not cached")
+	}
+
+	if _, err := GetProvider(context.Background(), "unknown"); err == nil {
+		t.Fatal("unknown provider was accepted")
+	}
+}
+
+func TestGetProviderIssuerMismatch(t *testing.T) {
+	stub := newStubProvider(t)
+	stub.issuer = "https://attacker.example.com"
+
+	if _, err := GetProvider(context.Background(), stub.name); err == nil {
+		t.Fatal("discovery document with another issuer was accepted")
+	}
+}
+
+func TestAuthCodeURLUsesPKCEAndNonce(t *testing.T) {
+	stub := newStubProvider(t)
+
+	provider, err := GetProvider(context.Background(), stub.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := stub.authorize(provider.AuthCodeURL("state", "nonce", "verifier"))
+
+	sum := sha256.Sum256([]byte("verifier"))
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected code challenge %v", query)
+	}
+	if query.Get("nonce") != "nonce" || query.Get("state") != "state" || query.Get("client_id") != testClientID {
+		t.Fatalf("unexpected authorization parameters %v", query)
+	}
+	if query.Get("code_verifier") != "" {
+		t.Fatal("code verifier was sent in the authorization URL")
+	}
+}
+
+func TestExchange(t *testing.T) {
+	stub := newStubProvider(t)
+
+	provider, err := GetProvider(context.Background(), stub.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.authorize(provider.AuthCodeURL("state", "nonce", "verifier"))
+
+	claims, err := provider.Exchange(context.Background(), testCode, "verifier", "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "subject-1" || claims.Email != "user@example.com" || !claims.EmailVerified ||
+		claims.GivenName != "Jane" || claims.FamilyName != "Doe" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	stub := newStubProvider(t)
+
+	provider, err := GetProvider(context.Background(), stub.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.authorize(provider.AuthCodeURL("state", "nonce", "verifier"))
+
+	if _, err := provider.Exchange(context.Background(), testCode, "another-verifier", "nonce"); err == nil {
+		t.Fatal("code was exchanged with another verifier")
+	}
+}
+
+func TestExchangeRejectsWrongNonce(t *testing.T) {
+	stub := newStubProvider(t)
+
+	provider, err := GetProvider(context.Background(), stub.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.authorize(provider.AuthCodeURL("state", "nonce", "verifier"))
+
+	if _, err := provider.Exchange(context.Background(), testCode, "verifier", "another-nonce"); err == nil {
+		t.Fatal("id token of another login was accepted")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	stub := newStubProvider(t)
+
+	provider, err := GetProvider(context.Background(), stub.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.nonce = "nonce"
+
+	valid := stub.idTokenClaims()
+	if _, err := provider.VerifyIDToken(context.Background(), stub.sign(valid, stub.kid), "nonce"); err != nil {
+		t.Fatal(err)
+	}
+
+	with := func(key string, value interface{}) jwt.MapClaims {
+		claims := stub.idTokenClaims()
+		claims[key] = value
+		return claims
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, valid)
+	forged.Header["kid"] = stub.kid
+	forgedToken, err := forged.SignedString(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, valid)
+	hmac.Header["kid"] = stub.kid
+	hmacToken, err := hmac.SignedString([]byte(testClientID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"issuer", stub.sign(with("iss", "https://attacker.example.com"), stub.kid)},
+		{"audience", stub.sign(with("aud", "another-client"), stub.kid)},
+		{"expired", stub.sign(with("exp", time.Now().Add(-time.Minute).Unix()), stub.kid)},
+		{"nonce", stub.sign(with("nonce", "another-nonce"), stub.kid)},
+		{"subject", stub.sign(with("sub", ""), stub.kid)},
+		{"unknown key", stub.sign(valid, "key-2")},
+		{"signature", forgedToken},
+		{"signing method", hmacToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := provider.VerifyIDToken(context.Background(), tt.token, "nonce"); err == nil {
+				t.Fatal("id token was accepted")
+			}
+		})
+	}
+}
+
+func TestKeySetRefreshesOnUnknownKey(t *testing.T) {
+	stub := newStubProvider(t)
+
+	provider, err := GetProvider(context.Background(), stub.name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.nonce = "nonce"
+
+	if _, err := provider.VerifyIDToken(context.Background(), stub.sign(stub.idTokenClaims(), stub.kid), "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.VerifyIDToken(context.Background(), stub.sign(stub.idTokenClaims(), stub.kid), "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	if stub.jwksRequests != 1 {
+		t.Fatalf("jwks fetched %d times for a known key", stub.jwksRequests)
+	}
+
+	// The provider rotates its key
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub.mu.Lock()
+	stub.key, stub.kid = newKey, "key-2"
+	stub.mu.Unlock()
+
+	if _, err := provider.VerifyIDToken(context.Background(), stub.sign(stub.idTokenClaims(), stub.kid), "nonce"); err != nil {
+		t.Fatal(err)
+	}
+	if stub.jwksRequests != 2 {
+		t.Fatalf("jwks fetched %d times after a key rotation", stub.jwksRequests)
+	}
+}
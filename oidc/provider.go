@@ -0,0 +1,125 @@
+package oidc
+
+import (
+	"certification/config"
+	"certification/constant"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Endpoints published by the provider at /.well-known/openid-configuration
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	Config    config.OIDCProvider
+	Discovery Discovery
+	keys      *KeySet
+	fetchedAt time.Time
+}
+
+var (
+	providers   = map[string]*Provider{}
+	providersMu sync.Mutex
+	httpClient  = &http.Client{Timeout: time.Second * 10}
+)
+
+// Get the configured provider by name, its discovery document is cached for OIDC_DISCOVERY_EXPIRY
+func GetProvider(ctx context.Context, name string) (*Provider, error) {
+	cfg, ok := config.OIDC_PROVIDERS[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %s", name)
+	}
+
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if p, ok := providers[name]; ok && time.Since(p.fetchedAt) < constant.OIDC_DISCOVERY_EXPIRY {
+		return p, nil
+	}
+
+	var discovery Discovery
+	if err := getJSON(ctx, cfg.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("unable to discover provider %s: %v", name, err)
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != cfg.Issuer {
+		return nil, fmt.Errorf("issuer mismatch for provider %s: %s", name, discovery.Issuer)
+	}
+
+	p := &Provider{
+		Config:    cfg,
+		Discovery: discovery,
+		keys:      NewKeySet(discovery.JWKSURI),
+		fetchedAt: time.Now(),
+	}
+	providers[name] = p
+	return p, nil
+}
+
+func (p *Provider) OAuth2Config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.Config.ClientID,
+		ClientSecret: p.Config.ClientSecret,
+		RedirectURL:  config.OIDC_REDIRECT_URL,
+		Scopes:       p.Config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.Discovery.AuthorizationEndpoint,
+			TokenURL: p.Discovery.TokenEndpoint,
+		},
+	}
+}
+
+// Authorization code URL using PKCE with the S256 challenge of verifier
+func (p *Provider) AuthCodeURL(state string, nonce string, verifier string) string {
+	return p.OAuth2Config().AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	)
+}
+
+// Exchange the authorization code and verify the returned ID token
+func (p *Provider) Exchange(ctx context.Context, code string, verifier string, nonce string) (*Claims, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+
+	token, err := p.OAuth2Config().Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("unable to exchange code: %v", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("no id_token in token response")
+	}
+
+	return p.VerifyIDToken(ctx, rawIDToken, nonce)
+}
+
+func getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, url)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
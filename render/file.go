@@ -0,0 +1,71 @@
+package render
+
+import (
+	"bytes"
+	"certification/constant"
+	"certification/storage"
+	"fmt"
+	"image"
+	"image/png"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Uploaded backgrounds and rendered certificates are kept in the storage by key
+
+func SaveFile(key string, data []byte) error {
+	return storage.Files.Put(key, data)
+}
+
+func LoadFile(key string) ([]byte, error) {
+	return storage.Files.Get(key)
+}
+
+func RemoveFile(key string) error {
+	return storage.Files.Delete(key)
+}
+
+// new key for a background image of a template, uploads never overwrite the background in use
+func BackgroundKey(templateID uuid.UUID) string {
+	return path.Join("backgrounds", templateID.String(), uuid.NewString())
+}
+
+// key of a rendered certificate, the fingerprint changes with everything drawn on it
+func RenderKey(certificateID uuid.UUID, fingerprint string, format string) string {
+	return path.Join("renders", certificateID.String(), fingerprint+"."+format)
+}
+
+// Remove the renders of the certificate with another fingerprint
+func RemoveStaleRenders(certificateID uuid.UUID, fingerprint string) {
+	prefix := path.Join("renders", certificateID.String()) + "/"
+	keys, err := storage.Files.List(prefix)
+	if err != nil {
+		return
+	}
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix+fingerprint+".") {
+			storage.Files.Delete(key)
+		}
+	}
+}
+
+// Encode the rendered certificate as PNG or PDF
+func Encode(img image.Image, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case constant.RENDER_FORMAT_PDF:
+		err = EncodePDF(&buf, img, constant.RENDER_DPI)
+	case constant.RENDER_FORMAT_PNG:
+		err = png.Encode(&buf, img)
+	default:
+		err = fmt.Errorf("unsupported format %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package render
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomedium"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+const DefaultFont = "regular"
+
+// The Go fonts are embedded so that rendering does not depend on the fonts of the host
+var fontFiles = map[string][]byte{
+	"regular":     goregular.TTF,
+	"bold":        gobold.TTF,
+	"italic":      goitalic.TTF,
+	"bold_italic": gobolditalic.TTF,
+	"medium":      gomedium.TTF,
+	"mono":        gomono.TTF,
+	"mono_bold":   gomonobold.TTF,
+}
+
+var (
+	fonts     = map[string]*truetype.Font{}
+	fontsOnce sync.Once
+	fontsErr  error
+)
+
+// names of the fonts a layout can use
+func Fonts() []string {
+	names := make([]string, 0, len(fontFiles))
+	for name := range fontFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func HasFont(name string) bool {
+	_, ok := fontFiles[name]
+	return ok
+}
+
+// face of the named font at the given size in pixels, the default font when the name is empty
+func fontFace(name string, size float64) (font.Face, error) {
+	fontsOnce.Do(func() {
+		for n, data := range fontFiles {
+			f, err := truetype.Parse(data)
+			if err != nil {
+				fontsErr = err
+				return
+			}
+			fonts[n] = f
+		}
+	})
+	if fontsErr != nil {
+		return nil, fontsErr
+	}
+
+	if name == "" {
+		name = DefaultFont
+	}
+	f, ok := fonts[name]
+	if !ok {
+		f = fonts[DefaultFont]
+	}
+	return truetype.NewFace(f, &truetype.Options{Size: size}), nil
+}
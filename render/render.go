@@ -0,0 +1,217 @@
+package render
+
+import (
+	"certification/constant"
+	model_template "certification/model/template"
+	"certification/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"math"
+	"regexp"
+	"sort"
+
+	"github.com/fogleman/gg"
+	"github.com/skip2/go-qrcode"
+)
+
+// Values drawn on a certificate
+type Data struct {
+	URL          string            // encoded in the QR codes
+	Revoked      bool              // a watermark is drawn over revoked certificates
+	Placeholders map[string]string // replace the {{key}} of the texts
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// Draw the layout over its background, the background image is stretched to the size of the layout
+func Render(layout *model_template.TemplateLayout, background image.Image, data Data) (image.Image, error) {
+	dc := gg.NewContext(layout.Width, layout.Height)
+
+	color := layout.BackgroundColor
+	if color == "" {
+		color = "#FFFFFF"
+	}
+	if err := setColor(dc, color); err != nil {
+		return nil, err
+	}
+	dc.Clear()
+
+	if background != nil {
+		bounds := background.Bounds()
+		dc.Push()
+		dc.Scale(float64(layout.Width)/float64(bounds.Dx()), float64(layout.Height)/float64(bounds.Dy()))
+		dc.DrawImage(background, -bounds.Min.X, -bounds.Min.Y)
+		dc.Pop()
+	}
+
+	for _, element := range layout.Elements {
+		var err error
+		switch element.Type {
+		case constant.ELEMENT_QR:
+			err = drawQR(dc, element, data.URL)
+		default:
+			err = drawText(dc, element, ReplacePlaceholders(element.Text, data.Placeholders))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", element.Position, err)
+		}
+	}
+
+	if data.Revoked {
+		if err := drawRevoked(dc); err != nil {
+			return nil, err
+		}
+	}
+	return dc.Image(), nil
+}
+
+// Identifies what is drawn with the layout revision and data, a certificate is rendered
+// again when its fingerprint changes, e.g. when the template layout changes or it is claimed
+func Fingerprint(revision int, data Data) string {
+	keys := make([]string, 0, len(data.Placeholders))
+	for key := range data.Placeholders {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%t\x00", data.URL, data.Revoked)
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", key, data.Placeholders[key])
+	}
+	return fmt.Sprintf("r%d-%s", revision, hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+// Replace the {{key}} of the text, unknown keys are replaced by an empty string
+func ReplacePlaceholders(text string, values map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return values[placeholderPattern.FindStringSubmatch(match)[1]]
+	})
+}
+
+func drawText(dc *gg.Context, element model_template.LayoutElement, text string) error {
+	if text == "" {
+		return nil
+	}
+
+	face, err := fontFace(element.Font, element.FontSize)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(face)
+
+	color := element.Color
+	if color == "" {
+		color = constant.RENDER_DEFAULT_FONT_COLOR
+	}
+	if err := setColor(dc, color); err != nil {
+		return err
+	}
+
+	anchor, align := 0.0, gg.AlignLeft
+	switch element.Align {
+	case constant.ALIGN_CENTER:
+		anchor, align = 0.5, gg.AlignCenter
+	case constant.ALIGN_RIGHT:
+		anchor, align = 1, gg.AlignRight
+	}
+
+	if element.Width > 0 {
+		dc.DrawStringWrapped(text, element.X, element.Y, anchor, 0, element.Width, 1.3, align)
+	} else {
+		dc.DrawStringAnchored(text, element.X, element.Y, anchor, 1)
+	}
+	return nil
+}
+
+func drawQR(dc *gg.Context, element model_template.LayoutElement, url string) error {
+	if url == "" {
+		return nil
+	}
+
+	size := int(math.Round(element.Width))
+	code, err := qrcode.New(url, qrcode.Medium)
+	if err != nil {
+		return err
+	}
+	code.DisableBorder = true
+
+	x := element.X
+	switch element.Align {
+	case constant.ALIGN_CENTER:
+		x -= float64(size) / 2
+	case constant.ALIGN_RIGHT:
+		x -= float64(size)
+	}
+	dc.DrawImage(code.Image(size), int(math.Round(x)), int(math.Round(element.Y)))
+	return nil
+}
+
+// Diagonal watermark across the certificate
+func drawRevoked(dc *gg.Context) error {
+	width, height := float64(dc.Width()), float64(dc.Height())
+
+	face, err := fontFace("bold", math.Min(width, height)/5)
+	if err != nil {
+		return err
+	}
+	dc.SetFontFace(face)
+	dc.SetRGBA255(200, 0, 0, 110)
+
+	dc.Push()
+	dc.RotateAbout(-math.Atan2(height, width), width/2, height/2)
+	dc.DrawStringAnchored("REVOKED", width/2, height/2, 0.5, 0.35)
+	dc.Pop()
+	return nil
+}
+
+func setColor(dc *gg.Context, hex string) error {
+	r, g, b, err := utils.HexToRGB(hex)
+	if err != nil {
+		return fmt.Errorf("invalid color %s: %w", hex, err)
+	}
+	dc.SetRGB255(int(r), int(g), int(b))
+	return nil
+}
+
+// Layout used for templates without one, lists the fields of the version under the recipient
+func DefaultLayout(version *model_template.TemplateVersion) *model_template.TemplateLayout {
+	width, height := float64(constant.RENDER_DEFAULT_WIDTH), float64(constant.RENDER_DEFAULT_HEIGHT)
+	center := width / 2
+	textWidth := width - 400
+
+	elements := []model_template.LayoutElement{
+		{Type: constant.ELEMENT_TEXT, Text: "{{template_name}}", Font: "bold", FontSize: 96, Align: constant.ALIGN_CENTER, X: center, Y: 200, Width: textWidth},
+		{Type: constant.ELEMENT_TEXT, Text: "Awarded to", Font: "italic", FontSize: 40, Color: "#555555", Align: constant.ALIGN_CENTER, X: center, Y: 430},
+		{Type: constant.ELEMENT_TEXT, Text: "{{recipient_name}}", Font: "bold", FontSize: 80, Align: constant.ALIGN_CENTER, X: center, Y: 500, Width: textWidth},
+	}
+
+	y := 700.0
+	for _, field := range version.Fields {
+		if y > height-450 {
+			break
+		}
+		elements = append(elements, model_template.LayoutElement{
+			Type: constant.ELEMENT_TEXT, Text: field.Label + ": {{" + field.Key + "}}", FontSize: 36, Align: constant.ALIGN_CENTER, X: center, Y: y,
+		})
+		y += 60
+	}
+
+	elements = append(elements,
+		model_template.LayoutElement{Type: constant.ELEMENT_TEXT, Text: "Issued by {{issuer_name}} on {{issued_at}}", FontSize: 36, X: 150, Y: height - 230},
+		model_template.LayoutElement{Type: constant.ELEMENT_QR, Align: constant.ALIGN_RIGHT, X: width - 150, Y: height - 400, Width: 220},
+		model_template.LayoutElement{Type: constant.ELEMENT_TEXT, Text: "{{code}}", Font: "mono", FontSize: 22, Color: "#555555", Align: constant.ALIGN_RIGHT, X: width - 150, Y: height - 160},
+	)
+	for i := range elements {
+		elements[i].Position = i
+	}
+
+	return &model_template.TemplateLayout{
+		Width:           constant.RENDER_DEFAULT_WIDTH,
+		Height:          constant.RENDER_DEFAULT_HEIGHT,
+		BackgroundColor: "#FFFFFF",
+		Elements:        elements,
+	}
+}
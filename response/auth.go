@@ -2,6 +2,7 @@ package response
 
 import (
 	"certification/constant"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,9 +19,35 @@ type ErrorResponse struct {
 }
 
 type LoginSuccessResponse struct {
-	ID    uuid.UUID `json:"id"`
-	Token string    `json:"token"`
-	Email string    `json:"email"`
+	ID           uuid.UUID `json:"id"`
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpireAt     time.Time `json:"expire_at"`
+	Email        string    `json:"email"`
+}
+
+type MFAChallengeResponse struct {
+	MFAPending         bool      `json:"mfa_pending"`
+	ChallengeToken     string    `json:"challenge_token"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+	ExpireAt           time.Time `json:"expire_at"`
+}
+
+func MFAChallengeResponseBody(
+	challengeToken string,
+	enrollmentRequired bool,
+	expireAt time.Time,
+) MessageDataResponse {
+	return MessageDataResponse{
+		Status:  constant.SUCCESS,
+		Message: constant.SuccessMFAPending,
+		Data: MFAChallengeResponse{
+			MFAPending:         true,
+			ChallengeToken:     challengeToken,
+			EnrollmentRequired: enrollmentRequired,
+			ExpireAt:           expireAt,
+		},
+	}
 }
 
 func LoginFailResponseBody() MessageResponse {
@@ -40,19 +67,35 @@ func InvalidPasswordResponseBody() MessageResponse {
 func LoginSuccessResponseBody(
 	id uuid.UUID,
 	token string,
+	refreshToken string,
+	expireAt time.Time,
 	email string,
 ) MessageDataResponse {
 	return MessageDataResponse{
 		Status:  constant.SUCCESS,
 		Message: constant.SuccessLogIn,
 		Data: LoginSuccessResponse{
-			ID:    id,
-			Token: token,
-			Email: email,
+			ID:           id,
+			Token:        token,
+			RefreshToken: refreshToken,
+			ExpireAt:     expireAt,
+			Email:        email,
 		},
 	}
 }
 
+func RefreshSuccessResponseBody(
+	id uuid.UUID,
+	token string,
+	refreshToken string,
+	expireAt time.Time,
+	email string,
+) MessageDataResponse {
+	body := LoginSuccessResponseBody(id, token, refreshToken, expireAt, email)
+	body.Message = constant.SuccessRefresh
+	return body
+}
+
 func LogoutFailResponseBody(message string) MessageResponse {
 	return MessageResponse{
 		Status:  constant.ErrorLogOut,
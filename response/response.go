@@ -50,6 +50,14 @@ func DataResponseBody(data interface{}, message string) DataResponse {
 	}
 }
 
+func ErrorDataResponseBody(data interface{}, message string) DataResponse {
+	return DataResponse{
+		Status:  constant.ERROR,
+		Message: message,
+		Data:    data,
+	}
+}
+
 func AccessDeniedResponseBody(id string) MessageResponse {
 	return MessageResponse{
 		Status:  constant.ACCESS_DENIED,
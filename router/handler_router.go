@@ -1,9 +1,17 @@
 package router
 
 import (
+	"certification/constant"
 	"certification/database"
 	handler_auth "certification/handler/auth"
+	handler_certificate "certification/handler/certificate"
+	handler_company "certification/handler/company"
+	handler_role "certification/handler/role"
+	handler_storage "certification/handler/storage"
+	handler_template "certification/handler/template"
 	"certification/middleware"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -11,30 +19,119 @@ import (
 func AuthenticationRoutes(app *fiber.App, initializer *database.Initializer) {
 	auth := app.Group("/auth")
 
-	auth.Post("/login/user", func(c *fiber.Ctx) error {
+	loginLimiter := middleware.RateLimit("login_ip", 20, time.Minute, middleware.KeyByIP)
+	loginEmailLimiter := middleware.RateLimit("login_email", 10, time.Minute, middleware.KeyByEmail)
+	signupLimiter := middleware.RateLimit("signup_ip", 5, time.Minute*10, middleware.KeyByIP)
+	emailLimiter := middleware.RateLimit("email_ip", 5, time.Minute*10, middleware.KeyByIP)
+	otpLimiter := middleware.RateLimit("otp_ip", 10, time.Minute, middleware.KeyByIP)
+	otpAccountLimiter := middleware.RateLimit("otp_account", 10, time.Minute*10, middleware.KeyByAccount)
+
+	walletModule := strconv.Itoa(constant.WALLET)
+	canReadWallet := middleware.ValidatePermission(walletModule, constant.READ)
+	canWriteWallet := middleware.ValidatePermission(walletModule, constant.WRITE)
+
+	// Sensitive actions need a step-up token from /auth/otp/verify
+	stepUp := middleware.RequireStepUp(constant.OTP_PURPOSE_STEP_UP)
+
+	auth.Post("/login/user", loginLimiter, loginEmailLimiter, func(c *fiber.Ctx) error {
 		return handler_auth.LoginUser(c, initializer, initializer.DB)
 	})
-	auth.Post("/login/company", func(c *fiber.Ctx) error {
+	auth.Post("/login/company", loginLimiter, loginEmailLimiter, func(c *fiber.Ctx) error {
 		return handler_auth.LoginCompany(c, initializer, initializer.DB)
 	})
+	auth.Post("/login/wallet", loginLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.LoginWallet(c, initializer)
+	})
+	auth.Post("/wallet/nonce", loginLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.RequestWalletNonce(c, initializer)
+	})
+	auth.Get("/wallets", middleware.ValidateToken(initializer), canReadWallet, func(c *fiber.Ctx) error {
+		return handler_auth.GetWallets(c, initializer)
+	})
+	auth.Post("/wallets", middleware.ValidateToken(initializer), canWriteWallet, func(c *fiber.Ctx) error {
+		return handler_auth.LinkWallet(c, initializer)
+	})
+	auth.Patch("/wallets/:id", middleware.ValidateToken(initializer), canWriteWallet, func(c *fiber.Ctx) error {
+		return handler_auth.UpdateWallet(c, initializer)
+	})
+	auth.Delete("/wallets/:id", middleware.ValidateToken(initializer), canWriteWallet, func(c *fiber.Ctx) error {
+		return handler_auth.DeleteWallet(c, initializer)
+	})
+	auth.Get("/oidc/:provider", loginLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.OIDCAuthorize(c, initializer)
+	})
+	auth.Post("/oidc/:provider/callback", loginLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.OIDCCallback(c, initializer)
+	})
+	auth.Get("/identities", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_auth.GetIdentities(c, initializer)
+	})
+	auth.Delete("/identities/:id", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_auth.DeleteIdentity(c, initializer)
+	})
+	auth.Post("/refresh", func(c *fiber.Ctx) error {
+		return handler_auth.RefreshToken(c, initializer)
+	})
 	auth.Post("/logout", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
 		return handler_auth.Logout(c, initializer)
 	})
-	auth.Post("/signup/user", func(c *fiber.Ctx) error {
+	auth.Get("/sessions", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_auth.GetSessions(c, initializer)
+	})
+	auth.Delete("/sessions", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_auth.DeleteOtherSessions(c, initializer)
+	})
+	auth.Delete("/sessions/:id", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_auth.DeleteSession(c, initializer)
+	})
+	auth.Post("/mfa/enroll", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_auth.EnrollMFA(c, initializer)
+	})
+	auth.Post("/mfa/confirm", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_auth.ConfirmMFA(c, initializer)
+	})
+	auth.Post("/mfa/disable", middleware.ValidateToken(initializer), stepUp, func(c *fiber.Ctx) error {
+		return handler_auth.DisableMFA(c, initializer)
+	})
+	auth.Post("/mfa/recovery-codes", middleware.ValidateToken(initializer), stepUp, func(c *fiber.Ctx) error {
+		return handler_auth.RegenerateRecoveryCodes(c, initializer)
+	})
+	auth.Post("/mfa/challenge/enroll", loginLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.EnrollMFAChallenge(c, initializer)
+	})
+	auth.Post("/mfa/verify", loginLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.VerifyMFA(c, initializer)
+	})
+	auth.Patch("/company/mfa", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_auth.UpdateCompanyMFA(c, initializer)
+	})
+	auth.Post("/otp/request", otpLimiter, middleware.ValidateToken(initializer), otpAccountLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.RequestOTP(c, initializer)
+	})
+	auth.Post("/otp/verify", otpLimiter, middleware.ValidateToken(initializer), otpAccountLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.VerifyOTPCode(c, initializer)
+	})
+	auth.Post("/signup/user", signupLimiter, func(c *fiber.Ctx) error {
 		return handler_auth.SignUpUser(c, initializer)
 	})
-	// auth.Post("/signup/company", func(c *fiber.Ctx) error {
-	// 	return handler_auth.SignUpCompany(c, initializer)
-	// })
-	// auth.Post("/activate", func(c *fiber.Ctx) error {
-	// 	return handler.ActivateAccount(c, initializer)
-	// })
-	// auth.Post("/forgot", func(c *fiber.Ctx) error {
-	// 	return handler.ForgotPassword(c, initializer)
-	// })
-	// auth.Post("/reset", func(c *fiber.Ctx) error {
-	// 	return handler.ResetPassword(c, initializer)
-	// })
+	auth.Post("/signup/company", signupLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.SignUpCompany(c, initializer)
+	})
+	auth.Get("/activate", func(c *fiber.Ctx) error {
+		return handler_auth.GetActivateAccount(c, initializer)
+	})
+	auth.Post("/activate", func(c *fiber.Ctx) error {
+		return handler_auth.ActivateAccount(c, initializer)
+	})
+	auth.Post("/activate/resend", emailLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.ResendActivation(c, initializer)
+	})
+	auth.Post("/forgot", emailLimiter, func(c *fiber.Ctx) error {
+		return handler_auth.ForgotPassword(c, initializer)
+	})
+	auth.Post("/reset", func(c *fiber.Ctx) error {
+		return handler_auth.ResetPassword(c, initializer)
+	})
 	// auth.Get("/profile", middleware.ValidateToken(initializer), middleware.GetCacheByIdForMe("profile", ""), func(c *fiber.Ctx) error {
 	// 	return handler.GetProfile(c, initializer)
 	// })
@@ -48,3 +145,251 @@ func AuthenticationRoutes(app *fiber.App, initializer *database.Initializer) {
 	// 	return handler.ChangePassword(c, initializer)
 	// })
 }
+
+func CompanyRoutes(app *fiber.App, initializer *database.Initializer) {
+	company := app.Group("/company")
+	stepUp := middleware.RequireStepUp(constant.OTP_PURPOSE_STEP_UP)
+
+	invitationLimiter := middleware.RateLimit("invitation_ip", 10, time.Minute*10, middleware.KeyByIP)
+
+	company.Get("/members/accept", invitationLimiter, func(c *fiber.Ctx) error {
+		return handler_company.GetAcceptInvitation(c, initializer)
+	})
+	company.Post("/members/accept", invitationLimiter, func(c *fiber.Ctx) error {
+		return handler_company.AcceptInvitation(c, initializer)
+	})
+	company.Get("/members", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_company.GetMembers(c, initializer)
+	})
+	company.Post("/members", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_company.InviteMember(c, initializer)
+	})
+	company.Patch("/members/:id", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_company.UpdateMemberRole(c, initializer)
+	})
+	company.Delete("/members/:id", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_company.DeleteMember(c, initializer)
+	})
+	company.Get("/api-keys", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_company.GetAPIKeys(c, initializer)
+	})
+	company.Post("/api-keys", middleware.ValidateToken(initializer), stepUp, func(c *fiber.Ctx) error {
+		return handler_company.CreateAPIKey(c, initializer)
+	})
+	company.Post("/api-keys/:id/rotate", middleware.ValidateToken(initializer), stepUp, func(c *fiber.Ctx) error {
+		return handler_company.RotateAPIKey(c, initializer)
+	})
+	company.Delete("/api-keys/:id", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_company.RevokeAPIKey(c, initializer)
+	})
+	company.Get("/signing-keys", middleware.ValidateToken(initializer), func(c *fiber.Ctx) error {
+		return handler_company.GetSigningKeys(c, initializer)
+	})
+	company.Post("/signing-keys", middleware.ValidateToken(initializer), stepUp, func(c *fiber.Ctx) error {
+		return handler_company.RotateSigningKey(c, initializer)
+	})
+}
+
+func RoleRoutes(app *fiber.App, initializer *database.Initializer) {
+	role := app.Group("/roles", middleware.ValidateToken(initializer))
+
+	accountModule := strconv.Itoa(constant.ACCOUNT)
+	canRead := middleware.ValidatePermission(accountModule, constant.READ)
+	canWrite := middleware.ValidatePermission(accountModule, constant.WRITE)
+	canDelete := middleware.ValidatePermission(accountModule, constant.DELETE)
+
+	role.Get("/", canRead, func(c *fiber.Ctx) error {
+		return handler_role.GetRoles(c, initializer)
+	})
+	role.Get("/modules", canRead, func(c *fiber.Ctx) error {
+		return handler_role.GetModules(c, initializer)
+	})
+	role.Get("/:id", canRead, func(c *fiber.Ctx) error {
+		return handler_role.GetRole(c, initializer)
+	})
+	role.Post("/", canWrite, func(c *fiber.Ctx) error {
+		return handler_role.CreateRole(c, initializer)
+	})
+	role.Patch("/accounts/:id", canWrite, func(c *fiber.Ctx) error {
+		return handler_role.UpdateAccountRole(c, initializer)
+	})
+	role.Patch("/:id", canWrite, func(c *fiber.Ctx) error {
+		return handler_role.UpdateRole(c, initializer)
+	})
+	role.Put("/:id/permissions", canWrite, func(c *fiber.Ctx) error {
+		return handler_role.UpdateRolePermissions(c, initializer)
+	})
+	role.Delete("/:id/permissions/:module_id", canDelete, func(c *fiber.Ctx) error {
+		return handler_role.DeleteRolePermission(c, initializer)
+	})
+	role.Delete("/:id", canDelete, func(c *fiber.Ctx) error {
+		return handler_role.DeleteRole(c, initializer)
+	})
+}
+
+func KeyRoutes(app *fiber.App, initializer *database.Initializer) {
+	app.Get("/.well-known/jwks.json", handler_auth.GetJWKS)
+
+	accountModule := strconv.Itoa(constant.ACCOUNT)
+	keys := app.Group("/auth/keys", middleware.ValidateToken(initializer))
+
+	keys.Get("/", middleware.ValidatePermission(accountModule, constant.READ), handler_auth.GetSigningKeys)
+	keys.Post("/rotate", middleware.ValidatePermission(accountModule, constant.DELETE), handler_auth.RotateSigningKey)
+}
+
+func TemplateRoutes(app *fiber.App, initializer *database.Initializer) {
+	template := app.Group("/templates", middleware.ValidateTokenOrAPIKey(initializer))
+
+	certificateModule := strconv.Itoa(constant.CERTIFICATE)
+	canRead := middleware.ValidatePermission(certificateModule, constant.READ)
+	canWrite := middleware.ValidatePermission(certificateModule, constant.WRITE)
+	canDelete := middleware.ValidatePermission(certificateModule, constant.DELETE)
+
+	template.Get("/", canRead, func(c *fiber.Ctx) error {
+		return handler_template.GetTemplates(c, initializer)
+	})
+	template.Get("/:id", canRead, func(c *fiber.Ctx) error {
+		return handler_template.GetTemplate(c, initializer)
+	})
+	template.Get("/:id/versions", canRead, func(c *fiber.Ctx) error {
+		return handler_template.GetTemplateVersions(c, initializer)
+	})
+	template.Get("/:id/versions/:version", canRead, func(c *fiber.Ctx) error {
+		return handler_template.GetTemplateVersion(c, initializer)
+	})
+	template.Post("/", canWrite, func(c *fiber.Ctx) error {
+		return handler_template.CreateTemplate(c, initializer)
+	})
+	template.Patch("/:id", canWrite, func(c *fiber.Ctx) error {
+		return handler_template.UpdateTemplate(c, initializer)
+	})
+	template.Put("/:id/fields", canWrite, func(c *fiber.Ctx) error {
+		return handler_template.UpdateTemplateFields(c, initializer)
+	})
+	template.Get("/:id/layout", canRead, func(c *fiber.Ctx) error {
+		return handler_template.GetTemplateLayout(c, initializer)
+	})
+	template.Put("/:id/layout", canWrite, func(c *fiber.Ctx) error {
+		return handler_template.UpdateTemplateLayout(c, initializer)
+	})
+	template.Put("/:id/background", canWrite, func(c *fiber.Ctx) error {
+		return handler_template.UpdateTemplateBackground(c, initializer)
+	})
+	template.Delete("/:id", canDelete, func(c *fiber.Ctx) error {
+		return handler_template.DeleteTemplate(c, initializer)
+	})
+}
+
+func CertificateRoutes(app *fiber.App, initializer *database.Initializer) {
+	certificate := app.Group("/certificates", middleware.ValidateTokenOrAPIKey(initializer))
+
+	certificateModule := strconv.Itoa(constant.CERTIFICATE)
+	canRead := middleware.ValidatePermission(certificateModule, constant.READ)
+	canWrite := middleware.ValidatePermission(certificateModule, constant.WRITE)
+	canDelete := middleware.ValidatePermission(certificateModule, constant.DELETE)
+
+	certificate.Get("/", canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetCertificates(c, initializer)
+	})
+	certificate.Get("/jobs", canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetIssuanceJobs(c, initializer)
+	})
+	certificate.Get("/jobs/:id", canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetIssuanceJob(c, initializer)
+	})
+	certificate.Get("/jobs/:id/rows", canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetIssuanceJobRows(c, initializer)
+	})
+	certificate.Get("/:id", canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetCertificate(c, initializer)
+	})
+	certificate.Get("/:id/render", canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetCertificateRender(c, initializer)
+	})
+	certificate.Get("/:id/credential", canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetCertificateCredential(c, initializer)
+	})
+	certificate.Post("/", canWrite, func(c *fiber.Ctx) error {
+		return handler_certificate.CreateCertificate(c, initializer)
+	})
+	certificate.Post("/bulk", canWrite, func(c *fiber.Ctx) error {
+		return handler_certificate.CreateBulkCertificates(c, initializer)
+	})
+	certificate.Post("/:id/reissue", canWrite, func(c *fiber.Ctx) error {
+		return handler_certificate.ReissueCertificate(c, initializer)
+	})
+	certificate.Get("/:id/revocations", canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetCertificateRevocations(c, initializer)
+	})
+	certificate.Post("/:id/revoke", canDelete, func(c *fiber.Ctx) error {
+		return handler_certificate.RevokeCertificate(c, initializer)
+	})
+	certificate.Post("/:id/unrevoke", canDelete, func(c *fiber.Ctx) error {
+		return handler_certificate.UnrevokeCertificate(c, initializer)
+	})
+}
+
+func ClaimRoutes(app *fiber.App, initializer *database.Initializer) {
+	claim := app.Group("/claims")
+
+	claimLimiter := middleware.RateLimit("claim_ip", 10, time.Minute, middleware.KeyByIP)
+	claimEmailLimiter := middleware.RateLimit("claim_email", 10, time.Minute*10, middleware.KeyByEmail)
+
+	claim.Post("/request", claimLimiter, claimEmailLimiter, func(c *fiber.Ctx) error {
+		return handler_certificate.RequestClaim(c, initializer)
+	})
+	claim.Post("/verify", claimLimiter, claimEmailLimiter, func(c *fiber.Ctx) error {
+		return handler_certificate.VerifyClaim(c, initializer)
+	})
+	// Recipients only have read access to certificates, they manage the visibility of their own
+	canRead := middleware.ValidatePermission(strconv.Itoa(constant.CERTIFICATE), constant.READ)
+
+	claim.Get("/certificates", middleware.ValidateToken(initializer), canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetClaimedCertificates(c, initializer)
+	})
+	claim.Patch("/certificates/:id", middleware.ValidateToken(initializer), canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.UpdateClaimedCertificate(c, initializer)
+	})
+	claim.Get("/certificates/:id/render", middleware.ValidateToken(initializer), canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetClaimedCertificateRender(c, initializer)
+	})
+	claim.Get("/certificates/:id/proof", middleware.ValidateToken(initializer), canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetClaimedCertificateProof(c, initializer)
+	})
+	claim.Get("/certificates/:id/credential", middleware.ValidateToken(initializer), canRead, func(c *fiber.Ctx) error {
+		return handler_certificate.GetClaimedCertificateCredential(c, initializer)
+	})
+}
+
+func VerificationRoutes(app *fiber.App, initializer *database.Initializer) {
+	verifyLimiter := middleware.RateLimit("verify_ip", 60, time.Minute, middleware.KeyByIP)
+
+	// Rendering is slower, renders are kept in the storage but the limit is lower
+	renderLimiter := middleware.RateLimit("verify_render_ip", 20, time.Minute, middleware.KeyByIP)
+
+	app.Get("/verify/:code", verifyLimiter, middleware.GetCacheByVerificationCode(constant.REDIS_VERIFICATION, "code"), func(c *fiber.Ctx) error {
+		return handler_certificate.VerifyCertificate(c, initializer)
+	})
+	app.Get("/verify/:code/render", renderLimiter, func(c *fiber.Ctx) error {
+		return handler_certificate.GetVerificationRender(c, initializer)
+	})
+	app.Get("/verify/:code/proof", verifyLimiter, func(c *fiber.Ctx) error {
+		return handler_certificate.GetVerificationProof(c, initializer)
+	})
+	app.Get("/issuers/:id/did.json", verifyLimiter, func(c *fiber.Ctx) error {
+		return handler_company.GetDIDDocument(c, initializer)
+	})
+	app.Get("/status-lists/:id", verifyLimiter, func(c *fiber.Ctx) error {
+		return handler_certificate.GetStatusList(c, initializer)
+	})
+	app.Post("/credentials/verify", verifyLimiter, func(c *fiber.Ctx) error {
+		return handler_certificate.VerifyCredential(c, initializer)
+	})
+}
+
+func StorageRoutes(app *fiber.App) {
+	fileLimiter := middleware.RateLimit("file_ip", 60, time.Minute, middleware.KeyByIP)
+
+	// Presigned URLs of the local storage, those of S3 point at the bucket
+	app.Get("/files/*", fileLimiter, handler_storage.GetFile)
+}
@@ -11,6 +11,14 @@ func SetupRoutes(app *fiber.App, initializer *database.Initializer) {
 	SetupSwagger(app)
 
 	AuthenticationRoutes(app, initializer)
+	CompanyRoutes(app, initializer)
+	RoleRoutes(app, initializer)
+	KeyRoutes(app, initializer)
+	TemplateRoutes(app, initializer)
+	CertificateRoutes(app, initializer)
+	ClaimRoutes(app, initializer)
+	VerificationRoutes(app, initializer)
+	StorageRoutes(app)
 }
 
 func SetupSwagger(app *fiber.App) {
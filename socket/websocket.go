@@ -1,11 +1,17 @@
 package socket
 
 import (
+	"certification/cache"
 	"certification/database"
 	"certification/logger"
+	"certification/middleware"
+	"certification/utils"
+	"context"
+	"strings"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type client struct {
@@ -19,8 +25,14 @@ type BroadcastMessage struct {
 
 var clients = make(map[*websocket.Conn]client) // Note: although large maps with pointer-like types (e.g. strings) as keys are slow, using pointers themselves as keys is acceptable and fast
 var broadcast = make(chan BroadcastMessage)
+var register = make(chan registration)
 var unregister = make(chan *websocket.Conn)
 
+type registration struct {
+	conn   *websocket.Conn
+	client client
+}
+
 func SendToBroadcast(message BroadcastMessage) {
 	broadcast <- message
 }
@@ -38,13 +50,18 @@ func runHub() {
 					logger.Log.Infof("client: %v, message: %v\n", cl, message)
 					if err := conn.WriteMessage(websocket.TextMessage, []byte(message.Content)); err != nil {
 						logger.Log.Error(err)
-						unregister <- conn
+						// Only the hub reads unregister, so the client is removed here instead of
+						// sending to it, the read loop of the connection then fails and returns
 						conn.WriteMessage(websocket.CloseMessage, []byte{})
 						conn.Close()
+						delete(clients, conn)
 					}
 				}
 			}
 
+		case r := <-register:
+			clients[r.conn] = r.client
+
 		case connection := <-unregister:
 			// Remove the client from the hub
 			delete(clients, connection)
@@ -62,10 +79,11 @@ func InitializeWebSocket(app *fiber.App, initializer *database.Initializer) {
 	})
 
 	go runHub()
+	go relayAccountEvents()
 
-	app.Get("/ws/:id", websocket.New(func(c *websocket.Conn) {
-		// Extract ID from the URL
-		id := c.Params("id")
+	app.Get("/ws", authenticateSocket(initializer), websocket.New(func(c *websocket.Conn) {
+		// The account of the access token, set by authenticateSocket
+		id := c.Locals("id").(uuid.UUID).String()
 
 		defer func() {
 			unregister <- c
@@ -73,7 +91,7 @@ func InitializeWebSocket(app *fiber.App, initializer *database.Initializer) {
 		}()
 
 		// Register the client with ID
-		clients[c] = client{ID: id}
+		register <- registration{conn: c, client: client{ID: id}}
 
 		// Existing read loop
 		for {
@@ -89,3 +107,31 @@ func InitializeWebSocket(app *fiber.App, initializer *database.Initializer) {
 		}
 	}))
 }
+
+// Validate the access token of the upgrade request. Browsers cannot set headers on a
+// websocket, so the token may also be sent in the token query parameter.
+func authenticateSocket(initializer *database.Initializer) fiber.Handler {
+	validateToken := middleware.ValidateToken(initializer)
+
+	return func(c *fiber.Ctx) error {
+		if !websocket.IsWebSocketUpgrade(c) {
+			return fiber.ErrUpgradeRequired
+		}
+		if c.Get(fiber.HeaderAuthorization) == "" && c.Query("token") != "" {
+			c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+c.Query("token"))
+		}
+		return validateToken(c)
+	}
+}
+
+// Forward the account events published by utils.PublishAccountEvent on any instance
+// to the clients of this instance authenticated as the account
+func relayAccountEvents() {
+	pubsub := cache.Redis.RDB.PSubscribe(context.Background(), utils.AccountEventsKey("*"))
+	defer pubsub.Close()
+
+	for message := range pubsub.Channel() {
+		accountID := strings.TrimPrefix(message.Channel, utils.AccountEventsKey(""))
+		SendToBroadcast(BroadcastMessage{ID: accountID, Content: message.Payload})
+	}
+}
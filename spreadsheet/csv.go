@@ -0,0 +1,35 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+)
+
+// Read the records of a CSV file, a leading UTF-8 byte order mark written by spreadsheet apps is dropped
+func ReadCSV(r io.Reader, maxRecords int) ([][]string, error) {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		buffered.Discard(3)
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records := [][]string{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == maxRecords {
+			return nil, ErrTooManyRecords
+		}
+		records = append(records, record)
+	}
+}
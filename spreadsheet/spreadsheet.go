@@ -0,0 +1,29 @@
+package spreadsheet
+
+import (
+	"errors"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+)
+
+// Returned as soon as a file has more records than the caller accepts
+var ErrTooManyRecords = errors.New("File has too many rows")
+
+// Read the records of an uploaded .csv or .xlsx file, the format is chosen by the file extension.
+// Reading stops with ErrTooManyRecords once the file has more than maxRecords records.
+func ReadFile(header *multipart.FileHeader, maxRecords int) ([][]string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".csv":
+		return ReadCSV(file, maxRecords)
+	case ".xlsx":
+		return ReadXLSX(file, header.Size, maxRecords)
+	}
+	return nil, errors.New("Unsupported file type, upload a .csv or .xlsx file")
+}
@@ -0,0 +1,308 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"certification/constant"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Only the parts needed to read the cell values of the first worksheet are decoded
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.Text)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxRow struct {
+	Cells []struct {
+		Ref    string       `xml:"r,attr"`
+		Type   string       `xml:"t,attr"`
+		Style  int          `xml:"s,attr"`
+		Value  string       `xml:"v"`
+		Inline xlsxRichText `xml:"is"`
+	} `xml:"c"`
+}
+
+// Read the cell values of the first worksheet of an XLSX file. Cells formatted as dates
+// are returned as 2006-01-02, or RFC 3339 when they have a time part. Rows are decoded one
+// at a time so reading stops with ErrTooManyRecords once there are more than maxRecords.
+func ReadXLSX(r io.ReaderAt, size int64, maxRecords int) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.New("Invalid XLSX file")
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if err := decodeFile(files, "xl/sharedStrings.xml", &shared, true); err != nil {
+		return nil, err
+	}
+	var styles xlsxStyles
+	if err := decodeFile(files, "xl/styles.xml", &styles, true); err != nil {
+		return nil, err
+	}
+
+	dateStyles := make(map[int]bool, len(styles.CellXfs))
+	for i, xf := range styles.CellXfs {
+		dateStyles[i] = isDateFormat(xf.NumFmtID, styles)
+	}
+
+	records := [][]string{}
+	cells := 0
+	err = decodeRows(files, sheetPath, func(row xlsxRow) error {
+		if len(records) == maxRecords {
+			return ErrTooManyRecords
+		}
+
+		var record []string
+		for i, cell := range row.Cells {
+			column := columnIndex(cell.Ref)
+			if column < 0 {
+				column = i
+			}
+			if column >= constant.SPREADSHEET_MAX_COLUMNS {
+				return errors.New("Invalid cell reference in XLSX file")
+			}
+			if column >= len(record) {
+				cells += column + 1 - len(record)
+				if cells > constant.SPREADSHEET_MAX_CELLS {
+					return errors.New("XLSX file has too many cells")
+				}
+				record = append(record, make([]string, column+1-len(record))...)
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return errors.New("Invalid shared string in XLSX file")
+				}
+				record[column] = shared.Items[index].String()
+			case "inlineStr":
+				record[column] = cell.Inline.String()
+			case "b":
+				record[column] = strconv.FormatBool(cell.Value == "1")
+			case "", "n":
+				if dateStyles[cell.Style] {
+					record[column] = excelDate(cell.Value)
+				} else {
+					record[column] = cell.Value
+				}
+			default: // str (formula result) and e (error)
+				record[column] = cell.Value
+			}
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	if err := decodeFile(files, "xl/workbook.xml", &workbook, false); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("XLSX file has no worksheet")
+	}
+
+	var rels xlsxRelationships
+	if err := decodeFile(files, "xl/_rels/workbook.xml.rels", &rels, false); err != nil {
+		return "", err
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "", errors.New("XLSX file has no worksheet")
+}
+
+// Open a part of the archive, at most SPREADSHEET_MAX_PART_SIZE bytes of it are read
+func openFile(files map[string]*zip.File, name string) (io.ReadCloser, error) {
+	f, ok := files[name]
+	if !ok {
+		return nil, errors.New("Invalid XLSX file, missing " + name)
+	}
+	if f.UncompressedSize64 > constant.SPREADSHEET_MAX_PART_SIZE {
+		return nil, errors.New("XLSX file is too large, cannot read " + name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(rc, constant.SPREADSHEET_MAX_PART_SIZE), rc}, nil
+}
+
+func decodeFile(files map[string]*zip.File, name string, v interface{}, optional bool) error {
+	if _, ok := files[name]; !ok && optional {
+		return nil
+	}
+
+	rc, err := openFile(files, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return errors.New("Invalid XLSX file, cannot read " + name)
+	}
+	return nil
+}
+
+// Call fn with every row of the worksheet in order, without decoding the whole sheet first
+func decodeRows(files map[string]*zip.File, name string, fn func(row xlsxRow) error) error {
+	rc, err := openFile(files, name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.New("Invalid XLSX file, cannot read " + name)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+
+		var row xlsxRow
+		if err := decoder.DecodeElement(&row, &start); err != nil {
+			return errors.New("Invalid XLSX file, cannot read " + name)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
+
+// zero based column of a cell reference such as AB12, -1 when it does not start with a column
+// and SPREADSHEET_MAX_COLUMNS when it is past the last column
+func columnIndex(ref string) int {
+	column := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		column = column*26 + int(c-'A'+1)
+		if column > constant.SPREADSHEET_MAX_COLUMNS {
+			return constant.SPREADSHEET_MAX_COLUMNS
+		}
+	}
+	return column - 1
+}
+
+// Built-in formats 14 to 22 and 45 to 47 are dates and times, custom formats are
+// treated as dates when they contain a day, month or year outside of quoted text
+func isDateFormat(numFmtID int, styles xlsxStyles) bool {
+	if (numFmtID >= 14 && numFmtID <= 22) || (numFmtID >= 45 && numFmtID <= 47) {
+		return true
+	}
+	for _, f := range styles.NumFmts {
+		if f.ID != numFmtID {
+			continue
+		}
+		quoted := false
+		for _, c := range strings.ToLower(f.Code) {
+			switch {
+			case c == '"':
+				quoted = !quoted
+			case !quoted && (c == 'd' || c == 'm' || c == 'y'):
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// convert an Excel serial date, days since 1899-12-30, to a date or datetime string
+func excelDate(value string) string {
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	days, fraction := math.Modf(serial)
+	t := epoch.AddDate(0, 0, int(days)).Add(time.Duration(math.Round(fraction*86400)) * time.Second)
+
+	if fraction == 0 {
+		return t.Format(constant.DATE_FORMAT)
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"bytes"
+	"certification/constant"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3config "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Objects of an S3-compatible bucket. An endpoint overrides the AWS endpoint, e.g. for MinIO,
+// its objects are then addressed by path instead of by virtual host.
+type S3 struct {
+	Client  *s3.Client
+	Presign *s3.PresignClient
+	Bucket  string
+}
+
+func NewS3(endpoint, region, bucket, accessKeyID, secretAccessKey string) (*S3, error) {
+	var options []func(*s3config.LoadOptions) error
+	options = append(options, s3config.WithRegion(region))
+	if accessKeyID != "" {
+		options = append(options, s3config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")))
+	}
+
+	cfg, err := s3config.LoadDefaultConfig(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+	return &S3{
+		Client:  client,
+		Presign: s3.NewPresignClient(client),
+		Bucket:  bucket,
+	}, nil
+}
+
+func (s *S3) Put(key string, data []byte) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constant.STORAGE_TIMEOUT)
+	defer cancel()
+	_, err = s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(ContentType(data)),
+	})
+	return err
+}
+
+func (s *S3) Get(key string) ([]byte, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constant.STORAGE_TIMEOUT)
+	defer cancel()
+	output, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
+
+func (s *S3) Delete(key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), constant.STORAGE_TIMEOUT)
+	defer cancel()
+	_, err = s.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func (s *S3) PresignedURL(key string, expiry time.Duration) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	request, err := s.Presign.PresignGetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expiry))
+	if err != nil {
+		return "", err
+	}
+	return request.URL, nil
+}
+
+func (s *S3) List(prefix string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), constant.STORAGE_TIMEOUT)
+	defer cancel()
+
+	keys := []string{}
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,82 @@
+// Package storage keeps the uploaded and generated files by key, on the local disk under
+// STORAGE_PATH or in an S3-compatible bucket when STORAGE_DRIVER is s3. Keys are slash
+// separated paths such as renders/<certificate>/<fingerprint>.png.
+package storage
+
+import (
+	"certification/config"
+	"certification/constant"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrInvalidKey = errors.New("invalid file key")
+)
+
+type Storage interface {
+	// Store the data under the key, its content type is sniffed from the data
+	Put(key string, data []byte) error
+	// Data stored under the key, ErrNotFound when there is none
+	Get(key string) ([]byte, error)
+	// Remove the key, removing a missing key is not an error
+	Delete(key string) error
+	// URL which gives access to the key without authentication until the expiry
+	PresignedURL(key string, expiry time.Duration) (string, error)
+	// Keys starting with the prefix
+	List(prefix string) ([]string, error)
+}
+
+// Storage of the server, set by Connect
+var Files Storage
+
+// Connect the storage of STORAGE_DRIVER, the local disk by default
+func Connect() error {
+	switch config.STORAGE_DRIVER {
+	case "", constant.STORAGE_LOCAL:
+		Files = NewLocal(config.STORAGE_PATH)
+	case constant.STORAGE_S3:
+		s3, err := NewS3(config.AWS_ENDPOINT, config.AWS_REGION, config.AWS_BUCKET, config.AWS_ACCESS_KEY_ID, config.AWS_SECRET_ACCESS_KEY)
+		if err != nil {
+			return err
+		}
+		Files = s3
+	default:
+		return fmt.Errorf("unsupported storage driver %s", config.STORAGE_DRIVER)
+	}
+	return nil
+}
+
+// Content type of the data, e.g. image/png
+func ContentType(data []byte) string {
+	return mimetype.Detect(data).String()
+}
+
+// Remove every key starting with the prefix
+func DeletePrefix(prefix string) error {
+	keys, err := Files.List(prefix)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := Files.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clean the key, keys are relative and may not leave the storage
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
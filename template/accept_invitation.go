@@ -4,11 +4,22 @@ import (
 	"certification/config"
 	"certification/database"
 	"fmt"
+	"html"
 )
 
 func TemplateEmailInvitation(initializer *database.Initializer, username string, token string) string {
 	acceptInvitationLink := fmt.Sprintf("%s/auth/activate?token=%s", config.API_URL, token)
 
+	return templateInvitation(username, acceptInvitationLink)
+}
+
+func TemplateCompanyInvitation(initializer *database.Initializer, username string, token string) string {
+	acceptInvitationLink := fmt.Sprintf("%s/company/members/accept?token=%s", config.API_URL, token)
+
+	return templateInvitation(username, acceptInvitationLink)
+}
+
+func templateInvitation(username string, acceptInvitationLink string) string {
 	return fmt.Sprintf(
 		`<mjml>
 		<mj-body background-color="#f0f0f0">
@@ -46,3 +57,34 @@ func TemplateEmailInvitation(initializer *database.Initializer, username string,
 	  `, config.EMAIL_LOGO_URL, username, acceptInvitationLink,
 	)
 }
+
+// Page of the company invitation link, the account is only created when the form with the
+// password is submitted so that link scanners and prefetchers opening the link do not use the token
+func TemplateAcceptInvitationPage(token string, valid bool) string {
+	body := `<p>This invitation link is invalid or has expired.</p>`
+	if valid {
+		body = fmt.Sprintf(
+			`<p>Choose a password for your CertFirst company account.</p>
+		<form method="post" action="/company/members/accept">
+			<input type="hidden" name="token" value="%s">
+			<input type="password" name="password" minlength="8" autocomplete="new-password" required>
+			<button type="submit">Accept Invitation</button>
+		</form>`, html.EscapeString(token),
+		)
+	}
+
+	return fmt.Sprintf(
+		`<!DOCTYPE html>
+<html>
+	<head>
+		<meta charset="utf-8">
+		<meta name="robots" content="noindex">
+		<title>Accept your invitation</title>
+	</head>
+	<body>
+		%s
+	</body>
+</html>
+`, body,
+	)
+}
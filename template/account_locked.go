@@ -0,0 +1,42 @@
+package template
+
+import (
+	"certification/config"
+	"certification/database"
+	"fmt"
+)
+
+func TemplateAccountLocked(initializer *database.Initializer, username string, ipAddress string, minutes int) string {
+	forgotPasswordLink := config.API_URL + "/forgot-password"
+
+	return fmt.Sprintf(
+		`<mjml>
+		<mj-body background-color="#f0f0f0">
+		  <mj-section background-color="#ffffff" padding="20px">
+			<mj-column>
+			  <mj-image src="%[1]s" alt="Logo" width="200px"></mj-image>
+			</mj-column>
+		  </mj-section>
+		  <mj-section background-color="#ffffff" padding="20px">
+			<mj-column>
+			  <mj-text color="#F45E43" font-size="24px" font-weight="bold">Hi, %[2]s</mj-text>
+			  <mj-text color="#000000">We noticed several failed attempts to log in to your account, the last one from IP address %[3]s.</mj-text>
+			  <mj-text color="#000000">To protect your account, logging in has been paused for %[4]d minutes.</mj-text>
+			</mj-column>
+		  </mj-section>
+		  <mj-section background-color="#ffffff" padding="10px">
+			<mj-column>
+			  <mj-button background-color="#22BC66" color="#ffffff" font-size="20px" href="%[5]s">Reset My Password</mj-button>
+			</mj-column>
+		  </mj-section>
+		  <mj-section background-color="#ffffff" padding="20px">
+			<mj-column>
+			  <mj-divider border-color="#F45E43"></mj-divider>
+			  <mj-text color="#626262">If this was you, you can try again later. If not, we recommend resetting your password.</mj-text>
+			  <mj-text color="#626262" font-size="12px">Learn more at <a href="https://tokenfirst.com">https://tokenfirst.com</a></mj-text>
+			</mj-column>
+		  </mj-section>
+		</mj-body>
+	  </mjml>`, config.EMAIL_LOGO_URL, username, ipAddress, minutes, forgotPasswordLink,
+	)
+}
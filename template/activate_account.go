@@ -0,0 +1,37 @@
+package template
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+)
+
+// Confirmation page of the activation link, the account is only activated when the form is
+// submitted so that link scanners and prefetchers opening the link do not use the token
+func TemplateActivatePage(token string, valid bool) string {
+	body := `<p>This activation link is invalid or has expired.</p>`
+	if valid {
+		action := "/auth/activate?token=" + url.QueryEscape(token)
+		body = fmt.Sprintf(
+			`<p>Confirm the activation of your CertFirst account.</p>
+		<form method="post" action="%s">
+			<button type="submit">Activate My Account</button>
+		</form>`, html.EscapeString(action),
+		)
+	}
+
+	return fmt.Sprintf(
+		`<!DOCTYPE html>
+<html>
+	<head>
+		<meta charset="utf-8">
+		<meta name="robots" content="noindex">
+		<title>Activate your account</title>
+	</head>
+	<body>
+		%s
+	</body>
+</html>
+`, body,
+	)
+}
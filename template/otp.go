@@ -6,7 +6,7 @@ import (
 	"fmt"
 )
 
-func TemplateOTP(initializer *database.Initializer, username string, otp string) string {
+func TemplateOTP(initializer *database.Initializer, username string, email string, otp string) string {
 
 	return fmt.Sprintf(
 		`
@@ -31,7 +31,7 @@ func TemplateOTP(initializer *database.Initializer, username string, otp string)
           </mj-text>
           <mj-text>Hello %[2]s,</mj-text>
           <mj-text>
-            We received your request for claim under email: <strong>theodore@gmail.com</strong> 
+            We received your request for claim under email: <strong>%[4]s</strong> 
             Just a reminder, we'll create your account using this email. 
             Once you log in, this email will serve as your credential. 
             Please make sure to check this email for further instructions.
@@ -63,5 +63,5 @@ func TemplateOTP(initializer *database.Initializer, username string, otp string)
 			</mj-section>
 		</mj-body>
 	</mjml>
-	`, config.EMAIL_LOGO_URL, username, otp)
+	`, config.EMAIL_LOGO_URL, username, otp, email)
 }
\ No newline at end of file
@@ -0,0 +1,47 @@
+package template
+
+import (
+	"certification/config"
+	"certification/database"
+	"fmt"
+)
+
+func TemplateVerifyOTP(initializer *database.Initializer, username string, otp string) string {
+
+	return fmt.Sprintf(
+		`<mjml>
+		<mj-head>
+			<mj-attributes>
+				<mj-text line-height="20px" align="center"/>
+			</mj-attributes>
+		</mj-head>
+		<mj-body background-color="#1454E2">
+		  <mj-section>
+			<mj-column>
+			  <mj-image width="200px" src="%[1]s" />
+			</mj-column>
+		  </mj-section>
+		  <mj-section background-color="#FFFFFF" padding="24px" border-radius="10px">
+			<mj-column>
+			  <mj-text font-weight="bold" font-size="24px" padding="30px">Verify it's you</mj-text>
+			  <mj-text>Hello %[2]s,</mj-text>
+			  <mj-text>We received a request that needs to confirm your identity on <strong>FirstCert</strong>.</mj-text>
+			  <mj-text>Please enter this number when requested: <strong>%[3]s</strong></mj-text>
+			  <mj-text>The code expires in 5 minutes.</mj-text>
+			  <mj-divider border-width="1px" padding-top="24px" />
+			  <mj-text line-height="24px" padding="24px 10%%">
+				If you did not make this request, please change your password and notify
+				<a href="mailto:support@first-cert.com">support@first-cert.com</a>
+			  </mj-text>
+			</mj-column>
+		  </mj-section>
+		  <mj-section>
+			<mj-column>
+			  <mj-text color="#FFFFFF">First Cert Copyrights © 2024. All right reserved</mj-text>
+			</mj-column>
+		  </mj-section>
+		</mj-body>
+	  </mjml>
+	  `, config.EMAIL_LOGO_URL, username, otp,
+	)
+}
@@ -3,37 +3,45 @@ package utils
 import (
 	"archive/tar"
 	"bytes"
+	"certification/cache"
 	"certification/config"
 	"certification/constant"
+	"certification/jwtkey"
 	"certification/logger"
 	model_token "certification/model/token"
+	"certification/storage"
 	"context"
 	"reflect"
 	"strconv"
 
 	"compress/gzip"
-	"crypto/ecdsa"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
-	"os"
-	"path/filepath"
+	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gabriel-vasile/mimetype"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt"
 	"github.com/google/uuid"
 	jsoniter "github.com/json-iterator/go"
-	"github.com/otiai10/copy"
 	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
 	age "github.com/theTardigrade/golang-age"
@@ -44,9 +52,15 @@ import (
 )
 
 type RedisValue struct {
-	Token  string
-	Module []map[string]interface{}
-	Status string
+	Token      string
+	SessionID  string
+	Module     []map[string]interface{}
+	Status     string
+	Device     string
+	IPAddress  string
+	UserAgent  string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
 }
 
 type Initializer struct {
@@ -70,13 +84,13 @@ type Month struct {
 	Days  int
 }
 
-var fileTypes = map[string]string{
-	"data:image/png;base64,":     "png",
-	"data:image/jpg;base64,":     "jpg",
-	"data:image/jpeg;base64,":    "jpeg",
-	"data:image/bmp;base64,":     "bmp",
-	"data:image/svg+xml;base64,": "svg",
-	"data:image/x-icon;base64,":  "ico",
+// Content types of the images accepted by UploadImage
+var imageTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/bmp",
+	"image/svg+xml",
+	"image/x-icon",
 }
 
 func IsValidUUID(u string, id *uuid.UUID) bool {
@@ -100,19 +114,137 @@ func GenerateToken() (string, error) {
 	return encodedToken, nil
 }
 
-func GenerateJWT(id *uuid.UUID, profile_id *uuid.UUID, email *string, expiry *time.Time, role *constant.AccountRoleType) (string, error) {
-	tokenJWT := jwt.New(jwt.SigningMethodHS256)
-	claims := tokenJWT.Claims.(jwt.MapClaims)
+func GenerateJWT(id *uuid.UUID, profile_id *uuid.UUID, email *string, expiry *time.Time, role *constant.AccountRoleType, sessionID *string) (string, error) {
+	claims := jwt.MapClaims{}
 	claims["id"] = *id
 	claims["profile_id"] = *profile_id
 	claims["email"] = *email
 	claims["exp"] = expiry.Unix()
+	claims["iat"] = time.Now().Unix()
 	claims["role"] = *role
+	claims["jti"] = *sessionID
 
-	// Generate tokenJWT (JWT) with a secret key
-	token, err := tokenJWT.SignedString([]byte(config.SECRET))
+	// Sign with the active key of the keyset, or HS256 and the secret without one
+	return jwtkey.Sign(claims)
+}
+
+// Generate a 32-byte random token and its SHA-256 hash for storage
+func GenerateHashedToken() (string, string, error) {
+	token := make([]byte, 32)
+
+	_, err := rand.Read(token)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	encodedToken := base64.RawURLEncoding.EncodeToString(token)
+
+	return encodedToken, HashToken(encodedToken), nil
+}
+
+// Generate an API key of DEFAULT_SIZE_OF_API_GROUP groups of DEFAULT_SIZE_PER_API_GROUP characters
+// joined by dashes, together with its first group used for lookup and its SHA-256 hash
+func GenerateAPIKey() (string, string, string, error) {
+	raw := make([]byte, constant.DEFAULT_SIZE_PER_API_GROUP*constant.DEFAULT_SIZE_OF_API_GROUP)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api key: %v", err)
+	}
+
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw)
+
+	groups := make([]string, constant.DEFAULT_SIZE_OF_API_GROUP)
+	for i := range groups {
+		groups[i] = encoded[i*constant.DEFAULT_SIZE_PER_API_GROUP : (i+1)*constant.DEFAULT_SIZE_PER_API_GROUP]
+	}
+
+	key := strings.Join(groups, "-")
+	return key, groups[0], HashToken(key), nil
+}
+
+// Generate the unguessable code a certificate is publicly verified with
+func GenerateVerificationCode() (string, error) {
+	raw := make([]byte, constant.VERIFICATION_CODE_SIZE*5/8)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %v", err)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw), nil
+}
+
+// Verification codes may be typed with spaces, dashes or in lower case
+func NormalizeVerificationCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// Hash a token with SHA-256 so the raw value is never stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+var encryptionKey []byte
+
+// Read ENCRYPTION_KEY, the AES-256 key of EncryptString given as 32 random bytes in hex
+func LoadEncryptionKey() error {
+	key, err := hex.DecodeString(strings.TrimPrefix(config.ENCRYPTION_KEY, "0x"))
+	if err != nil || len(key) != 32 {
+		return errors.New("ENCRYPTION_KEY must be 32 random bytes in hex, e.g. from openssl rand -hex 32")
+	}
+	encryptionKey = key
+	return nil
+}
+
+// Encrypt a string with AES-GCM using ENCRYPTION_KEY
+func EncryptString(plainText string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	cipherText := gcm.Seal(nonce, nonce, []byte(plainText), nil)
+	return base64.StdEncoding.EncodeToString(cipherText), nil
+}
+
+// Decrypt a string encrypted by EncryptString
+func DecryptString(encrypted string) (string, error) {
+	cipherText, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	if len(cipherText) < gcm.NonceSize() {
+		return "", errors.New("invalid cipher text")
+	}
+
+	nonce, cipherText := cipherText[:gcm.NonceSize()], cipherText[gcm.NonceSize():]
+	plainText, err := gcm.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return "", err
+	}
 
-	return token, err
+	return string(plainText), nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	if len(encryptionKey) == 0 {
+		return nil, errors.New("ENCRYPTION_KEY is not loaded")
+	}
+
+	block, err := aes.NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
 }
 
 // Validate token from token table whether it's exist and return account id
@@ -126,8 +258,8 @@ func ValidateToken(token string, db *gorm.DB) (uuid.UUID, error) {
 	return tokenData.AccountID, nil
 }
 
-// Generate random 6-digit OTP
-func GenerateOTPToken(accountID uuid.UUID, tx *gorm.DB) (string, error) {
+// Generate random 6-digit OTP for a purpose, previous pending OTPs of the purpose stop working
+func GenerateOTPToken(accountID uuid.UUID, purpose string, tx *gorm.DB) (string, error) {
 	otp := make([]byte, 6)
 	_, err := rand.Read(otp)
 
@@ -139,7 +271,12 @@ func GenerateOTPToken(accountID uuid.UUID, tx *gorm.DB) (string, error) {
 		otp[i] = uint8(48 + (otp[i] % 10))
 	}
 
-	logger.Log.Info(time.Now(), " : ", time.Now().Add(time.Minute*5))
+	err = model_token.SupersedeTokensByPurpose(accountID, constant.OTP_TOKEN, purpose, tx)
+	if err != nil {
+		tx.Rollback()
+		logger.Log.Error(err)
+		return "", fmt.Errorf("failed to supersede OTP token: %v", err)
+	}
 
 	//store otp in token
 	token := model_token.Token{
@@ -147,6 +284,7 @@ func GenerateOTPToken(accountID uuid.UUID, tx *gorm.DB) (string, error) {
 		Token:     string(otp),
 		ExpireAt:  time.Now().Add(time.Minute * 5), // 5 minutes expiry
 		Type:      constant.OTP_TOKEN,
+		Purpose:   purpose,
 		Status:    constant.PENDING,
 	}
 
@@ -180,28 +318,154 @@ func IsValueExisting(db *gorm.DB, table string, key string, value interface{}) b
 
 // --------------- Redis ---------------
 
-func (initializer *Initializer) UpdateStatusInRedis(results string, id string) error {
+// Redis key of a single login session of an account
+func SessionKey(accountID string, sessionID string) string {
+	return fmt.Sprintf("%s:%s:%s", constant.REDIS_SESSION, accountID, sessionID)
+}
+
+// Redis key of the set holding every session ID of an account
+func AccountSessionsKey(accountID string) string {
+	return fmt.Sprintf("%s:%s", constant.REDIS_ACCOUNT_SESSIONS, accountID)
+}
+
+// Redis channel of the events of an account, see PublishAccountEvent
+func AccountEventsKey(accountID string) string {
+	return fmt.Sprintf("%s:%s", constant.REDIS_ACCOUNT_EVENTS, accountID)
+}
+
+// Mark the session stored at key as UPDATED, ValidateToken then asks the client to log in again
+func UpdateStatusInRedis(key string) error {
+	ctx := context.Background()
+
+	results, err := cache.Redis.RDB.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	var data RedisValue
-	err := json.Unmarshal([]byte(results), &data)
+	err = json.Unmarshal([]byte(results), &data)
 	if err != nil {
-		return fmt.Errorf("error in decoding string to json for ID %s. %s", id, results)
+		return fmt.Errorf("error in decoding string to json for key %s. %s", key, results)
 	}
 
 	data.Status = constant.UPDATED
 	jsonData, err := jsoniter.Marshal(data)
 	if err != nil {
-		return fmt.Errorf("error in encoding JSON %s for ID %s", err, id)
+		return fmt.Errorf("error in encoding JSON %s for key %s", err, key)
 	}
 
-	// Store in Redis
-	err = initializer.RDB.Set(context.Background(), id, jsonData, 0).Err()
+	// Store in Redis, the session keeps its expiry
+	err = cache.Redis.RDB.Set(ctx, key, jsonData, redis.KeepTTL).Err()
 	if err != nil {
-		return fmt.Errorf("unable to set EmpID %s and JSON %v to Redis", id, data)
+		return fmt.Errorf("unable to set key %s and JSON %v to Redis", key, data)
 	}
 
 	return nil
 }
 
+// Mark every session of the account as UPDATED and notify its connected clients
+func InvalidateSessions(accountID string) error {
+	sessionIDs, err := cache.Redis.RDB.SMembers(context.Background(), AccountSessionsKey(accountID)).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, sessionID := range sessionIDs {
+		if err := UpdateStatusInRedis(SessionKey(accountID, sessionID)); err != nil {
+			return err
+		}
+	}
+
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	return PublishAccountEvent(accountID, constant.EVENT_REAUTHENTICATE, nil)
+}
+
+const pendingSessionsKey = "sessions:invalidate_after_commit"
+
+// Accounts to invalidate once the transaction begun by the caller is committed, by the connection of the transaction
+var pendingSessions sync.Map
+
+// Invalidate the sessions of the accounts once the changes made by tx are committed, so that a rolled back
+// change logs nobody out and a session refreshed before the commit does not keep the old permissions.
+// Called from the hooks of the models, the transaction is either the one gorm begins for the statement,
+// which is handled by InvalidateSessionsCallback, or one begun by the caller, which must be ended with
+// CommitTransaction.
+func InvalidateSessionsAfterCommit(tx *gorm.DB, accountIDs ...string) {
+	if len(accountIDs) == 0 {
+		return
+	}
+
+	if _, ok := tx.InstanceGet("gorm:started_transaction"); ok {
+		pending, _ := tx.InstanceGet(pendingSessionsKey)
+		ids, _ := pending.([]string)
+		tx.InstanceSet(pendingSessionsKey, append(ids, accountIDs...))
+		return
+	}
+
+	if _, ok := tx.Statement.ConnPool.(gorm.TxCommitter); ok {
+		pending, _ := pendingSessions.LoadOrStore(tx.Statement.ConnPool, &[]string{})
+		ids := pending.(*[]string)
+		*ids = append(*ids, accountIDs...)
+		return
+	}
+
+	invalidateSessions(accountIDs)
+}
+
+// Registered after gorm:commit_or_rollback_transaction, invalidates what the hooks of the statement queued once it is committed
+func InvalidateSessionsCallback(db *gorm.DB) {
+	pending, ok := db.InstanceGet(pendingSessionsKey)
+	if !ok || db.Error != nil {
+		return
+	}
+	invalidateSessions(pending.([]string))
+}
+
+// Commit a transaction begun with Begin and invalidate the sessions its changes queued
+func CommitTransaction(tx *gorm.DB) error {
+	connPool := tx.Statement.ConnPool
+	err := tx.Commit().Error
+
+	pending, ok := pendingSessions.LoadAndDelete(connPool)
+	if ok && err == nil {
+		invalidateSessions(*pending.(*[]string))
+	}
+	return err
+}
+
+// Forget the sessions queued by a transaction which was rolled back, deferred right after Begin
+func DiscardPendingSessions(tx *gorm.DB) {
+	pendingSessions.Delete(tx.Statement.ConnPool)
+}
+
+func invalidateSessions(accountIDs []string) {
+	for _, accountID := range accountIDs {
+		if err := InvalidateSessions(accountID); err != nil {
+			logger.Log.Errorf("unable to invalidate sessions for %s in Redis", accountID)
+		}
+	}
+}
+
+// Sent to the websocket connections of the account, e.g. {"type":"reauthenticate"} once its sessions are invalidated
+type AccountEvent struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+}
+
+// Publish an event to every websocket connection of the account
+func PublishAccountEvent(accountID string, event string, data interface{}) error {
+	payload, err := json.Marshal(AccountEvent{Type: event, Data: data})
+	if err != nil {
+		return err
+	}
+	return cache.Redis.RDB.Publish(context.Background(), AccountEventsKey(accountID), payload).Err()
+}
+
 func (initializer *Initializer) UpdateObjectHSetInRedis(key string, value interface{}) error {
 	return initializer.RDB.HSet(context.Background(), key, "data", value).Err()
 }
@@ -211,31 +475,26 @@ func (initializer *Initializer) GetObjectHGetAllByKeyInRedis(key string) string
 }
 
 // --------------- Web 3 ---------------
-func VerifyWallet() error {
-	pvKeyHex := ""
 
-	pvKeyBytes, err := hex.DecodeString(pvKeyHex)
-	if err != nil {
-		return err
+// Recover the address that signed message with EIP-191 personal_sign
+func RecoverPersonalSignAddress(message string, signatureHex string) (common.Address, error) {
+	signature, err := hexutil.Decode(signatureHex)
+	if err != nil || len(signature) != crypto.SignatureLength {
+		return common.Address{}, errors.New("Invalid signature")
 	}
 
-	pvKey, err := crypto.ToECDSA(pvKeyBytes)
-	if err != nil {
-		return err
+	// Wallets return the recovery ID as 27 or 28, go-ethereum expects 0 or 1
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
 	}
 
-	message := []byte("hello world")
-	r, s, err := ecdsa.Sign(rand.Reader, pvKey, message)
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	publicKey, err := crypto.SigToPub(hash, signature)
 	if err != nil {
-		return err
-	}
-
-	isVerified := ecdsa.Verify(&pvKey.PublicKey, message, r, s)
-	if !isVerified {
-		return errors.New("Invalid wallet info")
+		return common.Address{}, errors.Wrap(err, "Invalid signature")
 	}
 
-	return nil
+	return crypto.PubkeyToAddress(*publicKey), nil
 }
 
 // --------------- Date Time ---------------
@@ -287,124 +546,86 @@ func CalculateYearsToNow(date time.Time) float32 {
 
 // --------------- File ---------------
 
-func GetImageFileType(base64String string) (string, string, error) {
-	for key, value := range fileTypes {
-		if strings.HasPrefix(base64String, key) {
-			format := value
-			if value == "svg" {
-				format += "+xml"
-			} else if value == "ico" {
-				format = "x-icon"
-			}
-			return value, format, nil
+// Image type of the data sniffed from its content, returns its extension and content type
+func GetImageFileType(data []byte) (string, string, error) {
+	mime := mimetype.Detect(data)
+	for _, contentType := range imageTypes {
+		if mime.Is(contentType) {
+			return strings.TrimPrefix(mime.Extension(), "."), mime.String(), nil
 		}
 	}
 
 	return "", "", errors.New("unsupported image format")
 }
 
-func LocateFile(path string) error {
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return err
-	}
-
-	return nil
-}
-
+// Store a base64 image, with or without its data URI prefix, under image/<childPath>/<imageName>
+// and remove the previous image. Returns the storage key of the image.
 func UploadImage(prevImagePath string, base64String string, imageName string, childPath string) (string, error) {
-	if prevImagePath != "" {
-		if err := LocateFile(prevImagePath); err != nil {
-			return "", err
-		}
+	if _, raw, ok := strings.Cut(base64String, ";base64,"); ok && strings.HasPrefix(base64String, "data:") {
+		base64String = raw
 	}
 
-	ext, format, err := GetImageFileType(base64String)
+	decodedImage, err := base64.StdEncoding.DecodeString(base64String)
 	if err != nil {
 		return "", err
 	}
 
-	rawImageString := strings.TrimPrefix(base64String, "data:image/"+format+";base64,")
-
-	decodedImage, err := base64.StdEncoding.DecodeString(rawImageString)
+	ext, _, err := GetImageFileType(decodedImage)
 	if err != nil {
-		return ext, err
-	}
-
-	// create folder if not exist
-	if err := LocateFile("image"); err != nil {
-		if err := os.Mkdir("image", os.ModePerm); err != nil {
-			return "", err
-		}
-	}
-
-	if err := LocateFile("image/" + childPath); err != nil {
-		if err := os.Mkdir("image/"+childPath, os.ModePerm); err != nil {
-			return "", err
-		}
+		return "", err
 	}
 
-	imagePath := "image/" + childPath + "/" + imageName + "." + ext
-	file, err := os.Create(imagePath)
-	if err != nil {
+	imagePath := path.Join("image", childPath, imageName+"."+ext)
+	if err := storage.Files.Put(imagePath, decodedImage); err != nil {
 		return "", err
 	}
-	defer file.Close()
 
-	_, err = file.Write(decodedImage)
-	if err != nil {
-		return "", err
+	if prevImagePath != "" && prevImagePath != imagePath {
+		if err := storage.Files.Delete(prevImagePath); err != nil {
+			logger.Log.Error(err)
+		}
 	}
 
 	return imagePath, nil
 }
 
+// Image stored by UploadImage as a data URI, empty when it cannot be loaded
 func DownloadImage(imagePath string) string {
-	if err := LocateFile(imagePath); err != nil {
+	imageByte, err := storage.Files.Get(imagePath)
+	if err != nil {
 		logger.Log.Info(err)
 		return ""
 	}
 
-	var headerBase64 string
-	for key, value := range fileTypes {
-		if filepath.Ext(imagePath)[1:] == value {
-			headerBase64 = key
-			break
-		}
-	}
-
-	imageByte, err := os.ReadFile(imagePath)
+	_, contentType, err := GetImageFileType(imageByte)
 	if err != nil {
 		logger.Log.Info(err)
 		return ""
 	}
-	return headerBase64 + base64.StdEncoding.EncodeToString(imageByte)
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(imageByte)
 }
 
-func DeleteFile(path string) error {
-	if err := os.RemoveAll(path); err != nil {
-		return err
-	}
-
-	return nil
+// Remove the files of the directory from the storage
+func DeleteFile(dir string) error {
+	return storage.DeletePrefix(strings.TrimSuffix(dir, "/") + "/")
 }
 
+// Names of the files of the directory in the storage
 func GetAllFilePaths(dir string) ([]string, error) {
-	var filePaths []string
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if !info.IsDir() {
-			filePaths = append(filePaths, filepath.Base(path))
-		}
-
-		return nil
-	})
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	keys, err := storage.Files.List(prefix)
+	if err != nil {
+		return nil, err
+	}
 
-	return filePaths, err
+	var filePaths []string
+	for _, key := range keys {
+		filePaths = append(filePaths, strings.TrimPrefix(key, prefix))
+	}
+	return filePaths, nil
 }
 
+// Archive the files of the directory in the storage as a base64 tar.gz
 func CompressAndEncodeFiles(dir string, filePaths []string) (string, int, error) {
 	// Compress the files into a gzip-compressed archive
 	var compressedData bytes.Buffer
@@ -413,21 +634,17 @@ func CompressAndEncodeFiles(dir string, filePaths []string) (string, int, error)
 	tarWritter := tar.NewWriter(gzipWritter)
 
 	for _, filePath := range filePaths {
-		finalFilePath := filepath.Join(dir, filePath)
-
-		file, err := os.Open(finalFilePath)
+		data, err := storage.Files.Get(path.Join(dir, filePath))
 		if err != nil {
 			return base64String, fiber.StatusBadRequest, fmt.Errorf("error opening file: %s", err)
 		}
-		defer file.Close()
 
 		// Prepare the file info for the archive
-		fileInfo, _ := file.Stat()
 		header := &tar.Header{
 			Name:    filePath,
-			Size:    fileInfo.Size(),
-			Mode:    int64(fileInfo.Mode()),
-			ModTime: fileInfo.ModTime(),
+			Size:    int64(len(data)),
+			Mode:    0o644,
+			ModTime: time.Now(),
 		}
 
 		// Write the file info and content to the archive
@@ -436,7 +653,7 @@ func CompressAndEncodeFiles(dir string, filePaths []string) (string, int, error)
 				fiber.StatusInternalServerError,
 				fmt.Errorf("error writing tar header: %s", err)
 		}
-		if _, err := io.Copy(tarWritter, file); err != nil {
+		if _, err := tarWritter.Write(data); err != nil {
 			return base64String,
 				fiber.StatusInternalServerError,
 				fmt.Errorf("error writing file to archive: %s", err)
@@ -462,6 +679,8 @@ func CompressAndEncodeFiles(dir string, filePaths []string) (string, int, error)
 	return base64String, fiber.StatusOK, nil
 }
 
+// Extract a base64 tar.gz into the directory of the storage. Every file is read and checked before
+// anything is stored, the update state replaces the files of the directory and restores them on error.
 func DecodeAndSaveCompressedFiles(
 	base64String string,
 	saveDir string,
@@ -488,29 +707,8 @@ func DecodeAndSaveCompressedFiles(
 	// Create tar reader to read individual files
 	tarReader := tar.NewReader(gzipReader)
 
-	// Directory to save the decompressed files
-	err = LocateFile(saveDir)
-	isNewDir := err != nil
-	backupDir := saveDir + "_backup"
-
-	if isUpdateState && !isNewDir {
-		err := copy.Copy(saveDir, backupDir)
-		if err != nil {
-			errMsg := "error create backup files"
-			logger.Log.Error(errMsg, err)
-			return fiber.StatusInternalServerError, fmt.Errorf(errMsg)
-		}
-
-		DeleteFile(saveDir)
-	}
-
-	if err = os.MkdirAll(saveDir, os.ModePerm); err != nil {
-		logger.Log.Error(err, saveDir)
-		return fiber.StatusInternalServerError, fmt.Errorf("error creating save directory")
-	}
-
-	errMsg := ""
-
+	files := map[string][]byte{}
+	var totalSize int64
 	for {
 		tarHeader, err := tarReader.Next()
 		if err == io.EOF {
@@ -518,60 +716,93 @@ func DecodeAndSaveCompressedFiles(
 		}
 
 		if err != nil {
-			errMsg = "error reading tar header"
 			logger.Log.Error(err)
-			break
+			return fiber.StatusBadRequest, fmt.Errorf("error reading tar header")
 		}
 
-		// Create a new file to save the decompressed content
-		savePath := filepath.Join(saveDir, tarHeader.Name)
+		if tarHeader.Typeflag != tar.TypeReg {
+			continue
+		}
 
-		saveExt := filepath.Ext(savePath)[1:]
+		savePath := path.Join(saveDir, tarHeader.Name)
+		if !strings.HasPrefix(savePath, path.Clean(saveDir)+"/") {
+			errMsg := fmt.Sprintf("invalid file name: %s", tarHeader.Name)
+			logger.Log.Error(errMsg)
+			return fiber.StatusBadRequest, fmt.Errorf(errMsg)
+		}
 
-		_, exist := (*supportFileExt)[saveExt]
-		if !exist {
-			errMsg = fmt.Sprintf("unsupported file format: %s", saveExt)
+		saveExt := strings.TrimPrefix(path.Ext(savePath), ".")
+		if _, exist := (*supportFileExt)[saveExt]; !exist {
+			errMsg := fmt.Sprintf("unsupported file format: %s", saveExt)
 			logger.Log.Error(errMsg, savePath)
-			break
+			return fiber.StatusBadRequest, fmt.Errorf(errMsg)
 		}
 
-		saveFile, err := os.Create(savePath)
+		// The header size is not trusted, at most one byte past the limits is read
+		limit := int64(constant.ARCHIVE_MAX_FILE_SIZE)
+		if remaining := constant.ARCHIVE_MAX_SIZE - totalSize; remaining < limit {
+			limit = remaining
+		}
+		data, err := io.ReadAll(io.LimitReader(tarReader, limit+1))
 		if err != nil {
-			errMsg = "error creating save file"
 			logger.Log.Error(err, savePath)
-			saveFile.Close()
-			break
+			return fiber.StatusBadRequest, fmt.Errorf("error reading file")
 		}
-
-		// Write the decompressed content to the save file
-		if _, err := io.Copy(saveFile, tarReader); err != nil {
-			errMsg = "error writing save file"
-			logger.Log.Error(err, savePath)
-			saveFile.Close()
-			break
+		if int64(len(data)) > limit {
+			errMsg := fmt.Sprintf("archive is too large, files are limited to %d MB and the archive to %d MB",
+				constant.ARCHIVE_MAX_FILE_SIZE>>20, constant.ARCHIVE_MAX_SIZE>>20)
+			logger.Log.Error(errMsg, savePath)
+			return fiber.StatusBadRequest, fmt.Errorf(errMsg)
 		}
-
-		saveFile.Close()
-
-		logger.Log.Infof("Uploaded file is saved:", savePath)
+		totalSize += int64(len(data))
+		files[savePath] = data
 	}
 
-	if errMsg != "" {
-		DeleteFile(saveDir)
+	// Files of the directory before the update, restored when a file cannot be stored
+	backup := map[string][]byte{}
+	if isUpdateState {
+		keys, err := storage.Files.List(path.Clean(saveDir) + "/")
+		if err != nil {
+			logger.Log.Error(err, saveDir)
+			return fiber.StatusInternalServerError, fmt.Errorf("error create backup files")
+		}
+		for _, key := range keys {
+			data, err := storage.Files.Get(key)
+			if err != nil {
+				logger.Log.Error(err, key)
+				return fiber.StatusInternalServerError, fmt.Errorf("error create backup files")
+			}
+			backup[key] = data
+		}
+	}
 
-		if isUpdateState && !isNewDir {
-			err := os.Rename(backupDir, saveDir)
+	var saved []string
+	for savePath, data := range files {
+		if err := storage.Files.Put(savePath, data); err != nil {
+			logger.Log.Error(err, savePath)
 
-			if err != nil {
-				logger.Log.Error(err, backupDir)
+			for _, key := range saved {
+				storage.Files.Delete(key)
 			}
+			for key, data := range backup {
+				if err := storage.Files.Put(key, data); err != nil {
+					logger.Log.Error(err, key)
+				}
+			}
+			return fiber.StatusInternalServerError, fmt.Errorf("error writing save file")
 		}
+		saved = append(saved, savePath)
 
-		return fiber.StatusInternalServerError, fmt.Errorf(errMsg)
+		logger.Log.Info("Uploaded file is saved: ", savePath)
 	}
 
-	if isUpdateState && !isNewDir {
-		DeleteFile(backupDir)
+	// Files of the previous version which are not part of the update
+	for key := range backup {
+		if _, exist := files[key]; !exist {
+			if err := storage.Files.Delete(key); err != nil {
+				logger.Log.Error(err, key)
+			}
+		}
 	}
 
 	return fiber.StatusOK, nil
@@ -715,7 +946,7 @@ func UpdateBSONParser(val *reflect.Value, bodyMap *map[string]interface{}, valid
 // hexToRGB takes a hex color code as a string and returns the RGB representation.
 func HexToRGB(hexColor string) (uint8, uint8, uint8, error) {
 	// Remove the hash (#) character if it's present
-	if hexColor[0] == '#' {
+	if strings.HasPrefix(hexColor, "#") {
 		hexColor = hexColor[1:]
 	}
 
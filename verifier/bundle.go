@@ -0,0 +1,223 @@
+// Package verifier checks the proof bundles of anchored certificates without trusting the API,
+// only the chain the registry is deployed on.
+//
+// A bundle is a JSON document with the members
+//
+//	version         1
+//	document        canonical JSON of the certificate, as a string
+//	leaf            keccak256(keccak256(document))
+//	proof           sibling hashes from the leaf up to the root
+//	root            Merkle root anchored in the registry
+//	chain_id        decimal ID of the chain of the registry
+//	registry        address of the registry contract
+//	tx_hash         transaction which anchored the root
+//	block_number    block of the transaction
+//	signer          address of the owner of the registry
+//	signature       65 bytes secp256k1 signature of the bundle digest
+//	recipient_salt  salt of the recipient hash in the document, only in the bundle of the holder
+//
+// The document does not contain the email of the recipient, only its recipient member
+// sha256(recipient_salt ‖ email) in hex without 0x. The holder proves the certificate was issued to them by
+// handing out the salt, CheckRecipient checks an email against it.
+//
+// Every hash is 0x prefixed hex. A node of the tree is the keccak256 of its two children in ascending
+// order, so the root is found by hashing the leaf with each hash of the proof in turn. The digest is
+//
+//	keccak256(leaf ‖ root ‖ uint256 chain_id ‖ registry ‖ tx_hash ‖ uint256 block_number)
+//
+// and is signed as an EIP-191 personal message, the signature can be checked with ecrecover.
+// A bundle is valid when the signer is the owner of the registry and the registry has
+// anchored the root in the transaction and block of the bundle.
+package verifier
+
+import (
+	"certification/anchor"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const Version = 1
+
+var (
+	ErrVersion     = errors.New("unsupported bundle version")
+	ErrLeaf        = errors.New("leaf is not the hash of the document")
+	ErrProof       = errors.New("proof does not lead from the leaf to the root")
+	ErrSignature   = errors.New("bundle is not signed by its signer")
+	ErrChain       = errors.New("bundle is for another chain")
+	ErrOwner       = errors.New("signer is not the owner of the registry")
+	ErrNotAnchored = errors.New("root is not anchored in the registry")
+	ErrTransaction = errors.New("root was anchored by another transaction")
+	ErrRecipient   = errors.New("certificate was not issued to the email")
+)
+
+type Bundle struct {
+	Version     int            `json:"version"`
+	Document    string         `json:"document"`
+	Leaf        common.Hash    `json:"leaf"`
+	Proof       []common.Hash  `json:"proof"`
+	Root        common.Hash    `json:"root"`
+	ChainID     string         `json:"chain_id"`
+	Registry    common.Address `json:"registry"`
+	TxHash      common.Hash    `json:"tx_hash"`
+	BlockNumber uint64         `json:"block_number"`
+	Signer      common.Address `json:"signer"`
+	Signature   hexutil.Bytes  `json:"signature"`
+
+	RecipientSalt string `json:"recipient_salt,omitempty"` // not signed, checked against the document
+}
+
+// Hash of the recipient email in the document of a certificate
+func HashRecipient(salt string, email string) string {
+	sum := sha256.Sum256([]byte(salt + email))
+	return hex.EncodeToString(sum[:])
+}
+
+// Check that the certificate of the bundle was issued to the email, needs the salt of the holder
+func (b *Bundle) CheckRecipient(email string) error {
+	var document struct {
+		Recipient string `json:"recipient"`
+	}
+	if err := json.Unmarshal([]byte(b.Document), &document); err != nil {
+		return err
+	}
+	if b.RecipientSalt == "" || document.Recipient != HashRecipient(b.RecipientSalt, email) {
+		return ErrRecipient
+	}
+	return nil
+}
+
+// What the verification needs from a node, ethclient.Client and the simulated backend satisfy it
+type Backend interface {
+	bind.ContractBackend
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Anchoring of a valid bundle as found on the chain
+type Result struct {
+	Signer      common.Address
+	BlockNumber uint64
+	AnchoredAt  time.Time // time of the block
+}
+
+// Hash signed by the owner of the registry
+func (b *Bundle) Digest() (common.Hash, error) {
+	chainID, ok := new(big.Int).SetString(b.ChainID, 10)
+	if !ok || chainID.Sign() < 0 {
+		return common.Hash{}, fmt.Errorf("invalid chain id %q", b.ChainID)
+	}
+
+	return crypto.Keccak256Hash(
+		b.Leaf[:],
+		b.Root[:],
+		common.LeftPadBytes(chainID.Bytes(), 32),
+		b.Registry[:],
+		b.TxHash[:],
+		common.LeftPadBytes(new(big.Int).SetUint64(b.BlockNumber).Bytes(), 32),
+	), nil
+}
+
+// Sign the bundle with the key of the owner of the registry
+func (b *Bundle) Sign(key *ecdsa.PrivateKey) error {
+	digest, err := b.Digest()
+	if err != nil {
+		return err
+	}
+
+	signature, err := crypto.Sign(accounts.TextHash(digest[:]), key)
+	if err != nil {
+		return err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	b.Signer = crypto.PubkeyToAddress(key.PublicKey)
+	b.Signature = signature
+	return nil
+}
+
+// Check the hashes, proof and signature of the bundle, everything but the chain
+func (b *Bundle) Check() error {
+	if b.Version != Version {
+		return ErrVersion
+	}
+	if anchor.HashLeaf([]byte(b.Document)) != b.Leaf {
+		return ErrLeaf
+	}
+	if !anchor.VerifyProof(b.Leaf, b.Proof, b.Root) {
+		return ErrProof
+	}
+
+	digest, err := b.Digest()
+	if err != nil {
+		return err
+	}
+	if len(b.Signature) != crypto.SignatureLength {
+		return ErrSignature
+	}
+	signature := append([]byte(nil), b.Signature...)
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	publicKey, err := crypto.SigToPub(accounts.TextHash(digest[:]), signature)
+	if err != nil || crypto.PubkeyToAddress(*publicKey) != b.Signer {
+		return ErrSignature
+	}
+	return nil
+}
+
+// Check the bundle and that its root was anchored on the chain of the backend as it claims
+func (b *Bundle) Verify(ctx context.Context, backend Backend) (*Result, error) {
+	if err := b.Check(); err != nil {
+		return nil, err
+	}
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if chainID.String() != b.ChainID {
+		return nil, ErrChain
+	}
+
+	registry := anchor.NewRegistry(b.Registry, backend)
+	owner, err := registry.Owner(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no registry at %s: %w", b.Registry.Hex(), err)
+	}
+	if owner != b.Signer {
+		return nil, ErrOwner
+	}
+
+	log, err := registry.FindAnchor(ctx, b.Root)
+	if err != nil {
+		return nil, err
+	}
+	if log == nil {
+		return nil, ErrNotAnchored
+	}
+	if log.TxHash != b.TxHash || log.BlockNumber != b.BlockNumber {
+		return nil, ErrTransaction
+	}
+
+	header, err := backend.HeaderByNumber(ctx, new(big.Int).SetUint64(log.BlockNumber))
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Signer:      owner,
+		BlockNumber: log.BlockNumber,
+		AnchoredAt:  time.Unix(int64(header.Time), 0).UTC(),
+	}, nil
+}
@@ -0,0 +1,202 @@
+//go:build simulated
+
+// Runs against an in-process simulated chain, with: go test -tags simulated ./...
+
+package verifier
+
+import (
+	"certification/anchor"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// Simulated chain with a registry owned by the returned key
+func newTestChain(t *testing.T) (*simulated.Backend, *ecdsa.PrivateKey, *anchor.Anchorer) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := simulated.NewBackend(types.GenesisAlloc{
+		crypto.PubkeyToAddress(key.PublicKey): {Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))},
+	}, simulated.WithBlockGasLimit(30_000_000))
+	t.Cleanup(func() { backend.Close() })
+
+	chainID, err := backend.Client().ChainID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry, _, err := anchor.DeployRegistry(opts, backend.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+
+	anchorer, err := anchor.New(context.Background(), backend.Client(), registry.Address, key, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return backend, key, anchorer
+}
+
+// Anchor a tree of documents and return the signed bundle of the first one
+func anchorTestBundle(t *testing.T, backend *simulated.Backend, key *ecdsa.PrivateKey, anchorer *anchor.Anchorer, documents ...string) *Bundle {
+	t.Helper()
+	ctx := context.Background()
+
+	leaves := make([]common.Hash, len(documents))
+	for i, document := range documents {
+		leaves[i] = anchor.HashLeaf([]byte(document))
+	}
+	tree := anchor.NewTree(leaves)
+
+	txHash, err := anchorer.Submit(ctx, tree.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+
+	receipt, err := anchorer.Confirm(ctx, txHash)
+	if err != nil || receipt == nil || !receipt.Success {
+		t.Fatalf("root not anchored: %v", err)
+	}
+
+	bundle := &Bundle{
+		Version:     Version,
+		Document:    documents[0],
+		Leaf:        leaves[0],
+		Proof:       tree.Proof(0),
+		Root:        tree.Root(),
+		ChainID:     anchorer.ChainID().String(),
+		Registry:    anchorer.Registry.Address,
+		TxHash:      receipt.TxHash,
+		BlockNumber: receipt.BlockNumber,
+	}
+	if err := bundle.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	return bundle
+}
+
+func TestVerify(t *testing.T) {
+	backend, key, anchorer := newTestChain(t)
+	bundle := anchorTestBundle(t, backend, key, anchorer, `{"code":"a"}`, `{"code":"b"}`, `{"code":"c"}`)
+
+	result, err := bundle.Verify(context.Background(), backend.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Signer != crypto.PubkeyToAddress(key.PublicKey) || result.BlockNumber != bundle.BlockNumber || result.AnchoredAt.IsZero() {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestCheckRecipient(t *testing.T) {
+	bundle := &Bundle{
+		Document:      `{"code":"a","recipient":"` + HashRecipient("salt", "user@example.com") + `"}`,
+		RecipientSalt: "salt",
+	}
+	if err := bundle.CheckRecipient("user@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bundle.CheckRecipient("other@example.com"); !errors.Is(err, ErrRecipient) {
+		t.Fatalf("expected %v, got %v", ErrRecipient, err)
+	}
+
+	// The public bundle has no salt
+	bundle.RecipientSalt = ""
+	if err := bundle.CheckRecipient("user@example.com"); !errors.Is(err, ErrRecipient) {
+		t.Fatalf("expected %v, got %v", ErrRecipient, err)
+	}
+}
+
+func TestVerifyRejectsTamperedBundle(t *testing.T) {
+	backend, key, anchorer := newTestChain(t)
+	bundle := anchorTestBundle(t, backend, key, anchorer, `{"code":"a"}`, `{"code":"b"}`, `{"code":"c"}`)
+
+	// A second root anchored by the same registry, used to point the bundle at another transaction
+	other := anchorTestBundle(t, backend, key, anchorer, `{"code":"d"}`, `{"code":"e"}`)
+
+	otherKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(b *Bundle)
+		err    error
+	}{
+		{"version", func(b *Bundle) { b.Version = 2 }, ErrVersion},
+		{"document", func(b *Bundle) { b.Document = `{"code":"x"}` }, ErrLeaf},
+		{"leaf", func(b *Bundle) {
+			b.Document = `{"code":"b"}`
+			b.Leaf = anchor.HashLeaf([]byte(b.Document))
+		}, ErrProof},
+		{"proof", func(b *Bundle) { b.Proof = b.Proof[1:] }, ErrProof},
+		{"root", func(b *Bundle) { b.Root = other.Root }, ErrProof},
+		{"signature", func(b *Bundle) { b.Signature[0] ^= 0xff }, ErrSignature},
+		{"signed field", func(b *Bundle) { b.BlockNumber++ }, ErrSignature},
+		{"signer", func(b *Bundle) { b.Signer = crypto.PubkeyToAddress(otherKey.PublicKey) }, ErrSignature},
+		{"owner", func(b *Bundle) { b.Sign(otherKey) }, ErrOwner},
+		{"chain", func(b *Bundle) {
+			b.ChainID = "1"
+			b.Sign(key)
+		}, ErrChain},
+		{"transaction", func(b *Bundle) {
+			b.TxHash = other.TxHash
+			b.Sign(key)
+		}, ErrTransaction},
+		{"block", func(b *Bundle) {
+			b.BlockNumber = other.BlockNumber
+			b.Sign(key)
+		}, ErrTransaction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := *bundle
+			tampered.Proof = append([]common.Hash(nil), bundle.Proof...)
+			tampered.Signature = append([]byte(nil), bundle.Signature...)
+			tt.tamper(&tampered)
+
+			if _, err := tampered.Verify(context.Background(), backend.Client()); !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsUnanchoredRoot(t *testing.T) {
+	backend, key, anchorer := newTestChain(t)
+	bundle := anchorTestBundle(t, backend, key, anchorer, `{"code":"a"}`, `{"code":"b"}`)
+
+	// A valid tree signed by the owner but never sent to the registry
+	leaves := []common.Hash{anchor.HashLeaf([]byte(`{"code":"x"}`)), anchor.HashLeaf([]byte(`{"code":"y"}`))}
+	tree := anchor.NewTree(leaves)
+	bundle.Document = `{"code":"x"}`
+	bundle.Leaf = leaves[0]
+	bundle.Proof = tree.Proof(0)
+	bundle.Root = tree.Root()
+	if err := bundle.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := bundle.Verify(context.Background(), backend.Client()); !errors.Is(err, ErrNotAnchored) {
+		t.Fatalf("expected %v, got %v", ErrNotAnchored, err)
+	}
+}